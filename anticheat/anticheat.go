@@ -0,0 +1,232 @@
+package anticheat
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// Verdicts on location updates
+const (
+	// Accepted updates are plausible
+	Accepted = "ACCEPTED"
+	// Flagged updates are suspicious but still accepted
+	Flagged = "FLAGGED"
+	// Rejected updates are impossible, so the trainer keeps the last accepted location
+	Rejected = "REJECTED"
+	// Banned trainers have all their updates rejected until the ban ends
+	Banned = "BANNED"
+)
+
+// scores below this fraction of the ban score are dropped when trainers are forgotten
+const negligibleScoreRatio = .01
+
+type (
+	// Config holds the detection thresholds. Speeds are in meters per second, distances in meters
+	// and times in seconds. Each flagged or rejected update adds to the trainer's suspicion
+	// score, which halves every ScoreHalfLife, and trainers reaching BanScore are banned for
+	// BanDuration. Jumps shorter than MinDistance are GPS noise and never suspicious.
+	Config struct {
+		FlagSpeed     float64 `json:"flag_speed"`
+		RejectSpeed   float64 `json:"reject_speed"`
+		MinDistance   float64 `json:"min_distance"`
+		FlagScore     float64 `json:"flag_score"`
+		RejectScore   float64 `json:"reject_score"`
+		BanScore      float64 `json:"ban_score"`
+		BanDuration   int     `json:"ban_duration"`
+		ScoreHalfLife int     `json:"score_half_life"`
+	}
+
+	// Result of checking a location update. Speed is the one implied by the update, 0 for the
+	// first one.
+	Result struct {
+		Verdict     string
+		Speed       float64
+		Score       float64
+		BannedUntil time.Time
+	}
+
+	// Detector keeps the last accepted location of each trainer and checks their updates
+	// against it. It is safe for concurrent use.
+	Detector struct {
+		config Config
+
+		lock     sync.Mutex
+		trainers map[string]*trainerState
+	}
+
+	trainerState struct {
+		location    s2.LatLng
+		timestamp   time.Time
+		score       float64
+		scoredAt    time.Time
+		bannedUntil time.Time
+	}
+)
+
+// DefaultConfig flags trainers faster than a car on a highway and rejects those faster than a
+// plane
+var DefaultConfig = Config{
+	FlagSpeed:     50,
+	RejectSpeed:   300,
+	MinDistance:   100,
+	FlagScore:     1,
+	RejectScore:   5,
+	BanScore:      10,
+	BanDuration:   600,
+	ScoreHalfLife: 3600,
+}
+
+// LoadConfig reads the detection thresholds from a JSON file
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, wrapLoadConfigError(err, filename)
+	}
+
+	var config Config
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, wrapLoadConfigError(err, filename)
+	}
+
+	return &config, nil
+}
+
+func NewDetector(config Config) (*Detector, error) {
+	if config.FlagSpeed <= 0 || config.RejectSpeed < config.FlagSpeed || config.MinDistance < 0 ||
+		config.FlagScore < 0 || config.RejectScore < 0 || config.BanScore <= 0 || config.BanDuration < 0 ||
+		config.ScoreHalfLife <= 0 {
+		return nil, ErrorInvalidConfig
+	}
+
+	return &Detector{
+		config:   config,
+		trainers: map[string]*trainerState{},
+	}, nil
+}
+
+// Check judges the trainer's update to the location at the given time, returning
+// ErrorLocationRejected or ErrorTrainerBanned when it should be ignored
+func (d *Detector) Check(username string, location s2.LatLng, timestamp time.Time) (Result, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	state, ok := d.trainers[username]
+	if !ok {
+		d.trainers[username] = &trainerState{location: location, timestamp: timestamp, scoredAt: timestamp}
+		observe(Accepted, 0)
+		return Result{Verdict: Accepted}, nil
+	}
+
+	state.decay(timestamp, d.config.ScoreHalfLife)
+
+	if timestamp.Before(state.bannedUntil) {
+		observe(Banned, state.score)
+		return state.result(Banned, 0), wrapCheckError(ErrorTrainerBanned, username)
+	} else if !state.bannedUntil.IsZero() || state.timestamp.IsZero() {
+		// start over once the ban ends or the trainer reconnects, as the last location is stale
+		state.bannedUntil = time.Time{}
+		state.location, state.timestamp = location, timestamp
+		observe(Accepted, state.score)
+		return state.result(Accepted, 0), nil
+	}
+
+	distance := gps.Distance(state.location, location)
+	speed := math.Inf(1)
+	if elapsed := timestamp.Sub(state.timestamp).Seconds(); elapsed > 0 {
+		speed = distance / elapsed
+	}
+
+	verdict := Accepted
+	switch {
+	case distance <= d.config.MinDistance || speed <= d.config.FlagSpeed:
+	case speed <= d.config.RejectSpeed:
+		verdict = Flagged
+		state.score += d.config.FlagScore
+	default:
+		verdict = Rejected
+		state.score += d.config.RejectScore
+	}
+
+	if verdict != Rejected {
+		state.location, state.timestamp = location, timestamp
+	}
+
+	var err error
+	if state.score >= d.config.BanScore {
+		verdict = Banned
+		state.bannedUntil = timestamp.Add(time.Duration(d.config.BanDuration) * time.Second)
+		err = wrapCheckError(ErrorTrainerBanned, username)
+	} else if verdict == Rejected {
+		err = wrapCheckError(ErrorLocationRejected, username)
+	}
+
+	observe(verdict, state.score)
+	return state.result(verdict, speed), err
+}
+
+// Score is the trainer's suspicion score at the given time
+func (d *Detector) Score(username string, timestamp time.Time) float64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	state, ok := d.trainers[username]
+	if !ok {
+		return 0
+	}
+
+	state.decay(timestamp, d.config.ScoreHalfLife)
+	return state.score
+}
+
+// Forget drops the last location of the trainer at the given time, e.g. once it disconnects, so
+// its next update is accepted wherever it is. The suspicion score and bans are kept until they
+// become negligible, so reconnecting doesn't clear them.
+func (d *Detector) Forget(username string, timestamp time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	state, ok := d.trainers[username]
+	if !ok {
+		return
+	}
+
+	state.decay(timestamp, d.config.ScoreHalfLife)
+	if !timestamp.Before(state.bannedUntil) && state.score < negligibleScoreRatio*d.config.BanScore {
+		delete(d.trainers, username)
+		return
+	}
+
+	state.location, state.timestamp = s2.LatLng{}, time.Time{}
+}
+
+func (state *trainerState) decay(timestamp time.Time, halfLife int) {
+	elapsed := timestamp.Sub(state.scoredAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	state.score *= math.Pow(.5, elapsed/float64(halfLife))
+	state.scoredAt = timestamp
+}
+
+func (state *trainerState) result(verdict string, speed float64) Result {
+	return Result{
+		Verdict:     verdict,
+		Speed:       speed,
+		Score:       state.score,
+		BannedUntil: state.bannedUntil,
+	}
+}
+
+// IsRejection tells if the error means the update should be ignored
+func IsRejection(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrorLocationRejected || cause == ErrorTrainerBanned
+}
@@ -0,0 +1,150 @@
+package anticheat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	lisbon = s2.LatLngFromDegrees(38.7223, -9.1393)
+	tokyo  = s2.LatLngFromDegrees(35.6762, 139.6503)
+	start  = time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+)
+
+func north(meters float64) s2.LatLng {
+	return gps.Destination(lisbon, 0*s1.Degree, meters)
+}
+
+func TestWalkingIsAccepted(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	for i := 0; i < 10; i++ {
+		result, err := detector.Check("walker", north(float64(i)*15), start.Add(time.Duration(i)*10*time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, Accepted, result.Verdict)
+	}
+
+	assert.Equal(t, 0., detector.Score("walker", start.Add(time.Hour)))
+}
+
+func TestNoiseIsAccepted(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("noisy", lisbon, start)
+	result, err := detector.Check("noisy", north(DefaultConfig.MinDistance/2), start)
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, result.Verdict)
+}
+
+func TestFastUpdatesAreFlagged(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	flagged := testutil.ToFloat64(locationUpdates.WithLabelValues(Flagged))
+
+	_, _ = detector.Check("driver", lisbon, start)
+	result, err := detector.Check("driver", north(1000), start.Add(10*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, Flagged, result.Verdict)
+	assert.InDelta(t, 100, result.Speed, 1e-6)
+	assert.Equal(t, DefaultConfig.FlagScore, result.Score)
+	assert.Equal(t, flagged+1, testutil.ToFloat64(locationUpdates.WithLabelValues(Flagged)))
+}
+
+func TestTeleportIsRejected(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("teleporter", lisbon, start)
+	result, err := detector.Check("teleporter", tokyo, start.Add(time.Minute))
+	assert.Equal(t, ErrorLocationRejected, errors.Cause(err))
+	assert.True(t, IsRejection(err))
+	assert.Equal(t, Rejected, result.Verdict)
+
+	// the next update is checked against the last accepted location
+	result, err = detector.Check("teleporter", north(10), start.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, result.Verdict)
+}
+
+func TestRepeatedTeleportsBan(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("cheater", lisbon, start)
+	_, _ = detector.Check("cheater", tokyo, start.Add(time.Second))
+	result, err := detector.Check("cheater", tokyo, start.Add(time.Second))
+	assert.Equal(t, ErrorTrainerBanned, errors.Cause(err))
+	assert.Equal(t, Banned, result.Verdict)
+	assert.Equal(t, start.Add(time.Second+time.Duration(DefaultConfig.BanDuration)*time.Second), result.BannedUntil)
+
+	// even plausible updates are ignored while banned
+	_, err = detector.Check("cheater", lisbon, start.Add(time.Minute))
+	assert.Equal(t, ErrorTrainerBanned, errors.Cause(err))
+
+	// and the trainer starts over once the ban ends
+	result, err = detector.Check("cheater", tokyo, result.BannedUntil)
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, result.Verdict)
+}
+
+func TestScoreDecays(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("decaying", lisbon, start)
+	_, _ = detector.Check("decaying", north(1000), start.Add(10*time.Second))
+
+	halfLife := time.Duration(DefaultConfig.ScoreHalfLife) * time.Second
+	assert.InDelta(t, DefaultConfig.FlagScore/2, detector.Score("decaying", start.Add(10*time.Second+halfLife)),
+		1e-9)
+}
+
+func TestForget(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("forgotten", lisbon, start)
+	_, _ = detector.Check("forgotten", tokyo, start.Add(time.Second))
+	detector.Forget("forgotten", start.Add(time.Second))
+
+	// the teleport is no longer compared with the old location, but it is still suspicious
+	result, err := detector.Check("forgotten", tokyo, start.Add(2*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, result.Verdict)
+	assert.InDelta(t, DefaultConfig.RejectScore, detector.Score("forgotten", start.Add(2*time.Second)), .01)
+
+	// trainers without a noticeable score are dropped
+	detector.Forget("forgotten", start.Add(100*time.Hour))
+	assert.Len(t, detector.trainers, 0)
+}
+
+func TestForgetKeepsBans(t *testing.T) {
+	detector, _ := NewDetector(DefaultConfig)
+
+	_, _ = detector.Check("cheater", lisbon, start)
+	_, _ = detector.Check("cheater", tokyo, start.Add(time.Second))
+	result, _ := detector.Check("cheater", tokyo, start.Add(time.Second))
+	assert.Equal(t, Banned, result.Verdict)
+
+	detector.Forget("cheater", start.Add(time.Minute))
+	_, err := detector.Check("cheater", lisbon, start.Add(2*time.Minute))
+	assert.Equal(t, ErrorTrainerBanned, errors.Cause(err))
+
+	result, err = detector.Check("cheater", tokyo, result.BannedUntil)
+	assert.NoError(t, err)
+	assert.Equal(t, Accepted, result.Verdict)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := NewDetector(DefaultConfig)
+	assert.NoError(t, err)
+
+	config := DefaultConfig
+	config.RejectSpeed = config.FlagSpeed / 2
+
+	_, err = NewDetector(config)
+	assert.Equal(t, ErrorInvalidConfig, err)
+}
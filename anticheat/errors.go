@@ -0,0 +1,26 @@
+package anticheat
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorLoadConfigFormat = "error loading anti-cheat config %s"
+	errorCheckFormat      = "error checking location of trainer %s"
+)
+
+var (
+	ErrorInvalidConfig    = errors.New("invalid anti-cheat config")
+	ErrorLocationRejected = errors.New("location rejected")
+	ErrorTrainerBanned    = errors.New("trainer banned")
+)
+
+func wrapLoadConfigError(err error, filename string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorLoadConfigFormat, filename))
+}
+
+func wrapCheckError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorCheckFormat, username))
+}
@@ -0,0 +1,28 @@
+package anticheat
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const verdictLabel = "verdict"
+
+var (
+	// suspicionScore is a histogram rather than a gauge per trainer, which would add a series for
+	// every username ever seen. The score of a given trainer is available through Detector.Score.
+	suspicionScore = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "anticheat_suspicion_score",
+		Help:    "Suspicion score of trainers after each location update",
+		Buckets: []float64{0, .5, 1, 2, 5, 10, 20},
+	})
+
+	locationUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "anticheat_location_updates_total",
+		Help: "Location updates checked, by verdict",
+	}, []string{verdictLabel})
+)
+
+func observe(verdict string, score float64) {
+	suspicionScore.Observe(score)
+	locationUpdates.WithLabelValues(verdict).Inc()
+}
@@ -17,6 +17,9 @@ const (
 
 	// RejectChallengePath path to reject battle challenge
 	RejectChallengePath = "/battles/reject/%s"
+
+	// GetBattleLogPath path to get the log and summary of a finished battle
+	GetBattleLogPath = "/battles/log/%s"
 )
 
 const (
@@ -31,4 +34,5 @@ var (
 	ChallengeToBattleRoute = fmt.Sprintf(ChallengeToBattlePath, fmt.Sprintf("{%s}", TargetPlayerIdPathvar))
 	AcceptChallengeRoute   = fmt.Sprintf(AcceptChallengePath, fmt.Sprintf("{%s}", BattleIdPathVar))
 	RejectChallengeRoute   = fmt.Sprintf(RejectChallengePath, fmt.Sprintf("{%s}", BattleIdPathVar))
+	GetBattleLogRoute      = fmt.Sprintf(GetBattleLogPath, fmt.Sprintf("{%s}", BattleIdPathVar))
 )
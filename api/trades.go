@@ -8,6 +8,7 @@ const GetTradesPath = "/trades"
 const StartTradePath = "/trades/join"
 const JoinTradePath = "/trades/join/%s"
 const RejectTradePath = "/trades/reject/%s"
+const GetTradeHistoryPath = "/trades/history"
 
 var (
 	JoinTradeRoute   = fmt.Sprintf(JoinTradePath, fmt.Sprintf("{%s}", TradeIdVar))
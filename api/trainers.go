@@ -15,6 +15,7 @@ const UpdateTrainerStatsPath = "/trainers/%s"
 const AddPokemonPath = "/trainers/%s/pokemons/"
 const RemovePokemonPath = "/trainers/%s/pokemons/%s"
 const UpdatePokemonPath = "/trainers/%s/pokemons/%s"
+const AddPokemonExperiencePath = "/trainers/%s/pokemons/%s/xp"
 
 // trainer bag
 const AddItemToBagPath = "/trainers/%s/bag/"
@@ -46,6 +47,7 @@ var UpdateTrainerStatsRoute = fmt.Sprintf(UpdateTrainerStatsPath, UsernameRouteV
 var AddPokemonRoute = fmt.Sprintf(AddPokemonPath, UsernameRouteVar)
 var UpdatePokemonRoute = fmt.Sprintf(UpdatePokemonPath, UsernameRouteVar, PokemonIdRouteVar)
 var RemovePokemonRoute = fmt.Sprintf(RemovePokemonPath, UsernameRouteVar, PokemonIdRouteVar)
+var AddPokemonExperienceRoute = fmt.Sprintf(AddPokemonExperiencePath, UsernameRouteVar, PokemonIdRouteVar)
 
 // trainer bag
 var AddItemToBagRoute = fmt.Sprintf(AddItemToBagPath, UsernameRouteVar)
@@ -0,0 +1,48 @@
+package catalogues
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Load reads a catalogue of the given kind from a JSON or YAML file, chosen by the file extension.
+// The file is decoded into the given value and then build is called to create the catalogue from it.
+func Load(kind, filename string, file interface{}, build func() error) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return wrapLoadError(err, kind, filename)
+	}
+
+	if err = Parse(kind, data, filepath.Ext(filename), file, build); err != nil {
+		return wrapLoadError(err, kind, filename)
+	}
+
+	return nil
+}
+
+// Parse is like Load for a catalogue in the given format, either ".json" or ".yaml"
+func Parse(kind string, data []byte, format string, file interface{}, build func() error) error {
+	var err error
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		err = json.Unmarshal(data, file)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, file)
+	default:
+		err = ErrorUnknownType
+	}
+
+	if err != nil {
+		return wrapParseError(err, kind)
+	}
+
+	if err = build(); err != nil {
+		return wrapParseError(err, kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package catalogues
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFile struct {
+	Names []string `json:"names" yaml:"names"`
+}
+
+func TestParseFormats(t *testing.T) {
+	var fromJSON, fromYAML testFile
+	noop := func() error { return nil }
+
+	assert.NoError(t, Parse("test", []byte(`{"names":["a","b"]}`), ".json", &fromJSON, noop))
+	assert.NoError(t, Parse("test", []byte("names:\n  - a\n  - b\n"), "YML", &fromYAML, noop))
+	assert.Equal(t, testFile{Names: []string{"a", "b"}}, fromJSON)
+	assert.Equal(t, fromJSON, fromYAML)
+
+	err := Parse("test", nil, ".toml", &fromJSON, noop)
+	assert.Equal(t, ErrorUnknownType, errors.Cause(err))
+	assert.EqualError(t, err, "error parsing test catalogue: unknown catalogue file type")
+}
+
+func TestLoadBuildsFromTheFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalogues")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.yaml")
+	assert.NoError(t, ioutil.WriteFile(filename, []byte("names: [a]\n"), 0644))
+
+	var file testFile
+	errorEmpty := errors.New("empty")
+	build := func() error {
+		if len(file.Names) == 0 {
+			return errorEmpty
+		}
+		return nil
+	}
+
+	assert.NoError(t, Load("test", filename, &file, build))
+	assert.Equal(t, []string{"a"}, file.Names)
+
+	file = testFile{}
+	assert.NoError(t, ioutil.WriteFile(filename, []byte("names: []\n"), 0644))
+	assert.Equal(t, errorEmpty, errors.Cause(Load("test", filename, &file, build)))
+
+	assert.True(t, os.IsNotExist(errors.Cause(Load("test", filepath.Join(dir, "missing.json"), &file, build))))
+}
@@ -0,0 +1,24 @@
+package catalogues
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorLoadFormat  = "error loading %s catalogue %s"
+	errorParseFormat = "error parsing %s catalogue"
+)
+
+var (
+	ErrorUnknownType = errors.New("unknown catalogue file type")
+)
+
+func wrapLoadError(err error, kind, filename string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorLoadFormat, kind, filename))
+}
+
+func wrapParseError(err error, kind string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorParseFormat, kind))
+}
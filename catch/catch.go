@@ -0,0 +1,171 @@
+package catch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/NOVAPokemon/utils/species"
+)
+
+// Outcomes
+const (
+	Caught  = "CAUGHT"
+	Escaped = "ESCAPED"
+	Fled    = "FLED"
+)
+
+const (
+	// Shakes is how many times the ball shakes before the pokemon is caught
+	Shakes = 3
+
+	// LevelGapScale is the level difference between pokemon and trainer that halves the catch
+	// probability
+	LevelGapScale  = 50
+	minLevelFactor = .25
+	maxLevelFactor = 1.25
+
+	// pokemons at full HP are as hard to catch as the other factors say, and up to hpFactorAtZero
+	// times easier as their HP goes down
+	hpFactorAtZero = 3
+
+	// difficulty of species missing from the catalogue
+	defaultCatchDifficulty = .5
+)
+
+var fleeChances = map[string]float64{
+	species.Common:    .05,
+	species.Uncommon:  .1,
+	species.Rare:      .2,
+	species.Legendary: .3,
+}
+
+type (
+	Attempt struct {
+		Pokeball     items.Item
+		Pokemon      pokemons.Pokemon
+		TrainerLevel int
+	}
+
+	// Result of an attempt. Shakes counts the shakes before the pokemon broke free, or Shakes when
+	// it was caught, and Reason describes the outcome for clients to display.
+	Result struct {
+		Outcome     string
+		Shakes      int
+		Probability float64
+		Reason      string
+	}
+)
+
+// Probability of the attempt succeeding, which is the ball's catch rate scaled by the species
+// difficulty, the level gap between the pokemon and the trainer and the pokemon's remaining HP.
+// Balls with the master ball value always catch.
+func Probability(attempt Attempt) (float64, error) {
+	ballRate, err := ballRate(attempt.Pokeball)
+	if err != nil {
+		return 0, err
+	}
+
+	if ballRate >= float64(items.MasterBallValue)/100 {
+		return 1, nil
+	}
+
+	difficulty := defaultCatchDifficulty
+	if s, ok := species.DefaultCatalogue.Get(attempt.Pokemon.Species); ok {
+		difficulty = s.CatchDifficulty
+	}
+
+	probability := ballRate * (1 - difficulty) * levelFactor(attempt.Pokemon.Level, attempt.TrainerLevel) *
+		hpFactor(attempt.Pokemon)
+
+	return math.Min(1, math.Max(0, probability)), nil
+}
+
+// Try makes the attempt. Each shake succeeds with the probability that makes all of them and
+// the final check succeed with the catch probability. Pokemons that break free may flee, more
+// often the rarer they are.
+func Try(attempt Attempt, rng random.Source) (Result, error) {
+	rng = random.OrGlobal(rng)
+
+	probability, err := Probability(attempt)
+	if err != nil {
+		return Result{}, err
+	}
+
+	checkProbability := math.Pow(probability, 1./(Shakes+1))
+	for shakes := 0; shakes <= Shakes; shakes++ {
+		if rng.Float64() < checkProbability {
+			continue
+		}
+
+		if rng.Float64() < fleeChance(attempt.Pokemon.Species) {
+			return Result{
+				Outcome:     Fled,
+				Shakes:      shakes,
+				Probability: probability,
+				Reason:      fmt.Sprintf("%s broke free after %d shakes and fled", attempt.Pokemon.Species, shakes),
+			}, nil
+		}
+
+		return Result{
+			Outcome:     Escaped,
+			Shakes:      shakes,
+			Probability: probability,
+			Reason:      fmt.Sprintf("%s broke free after %d shakes", attempt.Pokemon.Species, shakes),
+		}, nil
+	}
+
+	return Result{
+		Outcome:     Caught,
+		Shakes:      Shakes,
+		Probability: probability,
+		Reason:      fmt.Sprintf("%s was caught", attempt.Pokemon.Species),
+	}, nil
+}
+
+// ballRate multiplies the catch rates of the ball's effects, given in percentages
+func ballRate(pokeball items.Item) (float64, error) {
+	if !pokeball.IsPokeBall() {
+		return 0, ErrorNotAPokeball
+	}
+
+	rate := 1.
+	found := false
+	for _, component := range pokeball.Effect.GetComponents() {
+		if component.Type == items.CatchRateEffectType {
+			rate *= float64(component.Value) / 100
+			found = true
+		}
+	}
+
+	if !found {
+		return 0, ErrorNotAPokeball
+	}
+
+	return rate, nil
+}
+
+func levelFactor(pokemonLevel, trainerLevel int) float64 {
+	factor := 1 - float64(pokemonLevel-trainerLevel)/(2*LevelGapScale)
+	return math.Min(maxLevelFactor, math.Max(minLevelFactor, factor))
+}
+
+func hpFactor(pokemon pokemons.Pokemon) float64 {
+	if pokemon.MaxHP <= 0 || pokemon.HP >= pokemon.MaxHP {
+		return 1
+	}
+
+	hp := math.Max(0, float64(pokemon.HP))
+	return hpFactorAtZero - (hpFactorAtZero-1)*hp/float64(pokemon.MaxHP)
+}
+
+func fleeChance(speciesName string) float64 {
+	s, ok := species.DefaultCatalogue.Get(speciesName)
+	if !ok {
+		return fleeChances[species.Common]
+	}
+
+	return fleeChances[s.Rarity]
+}
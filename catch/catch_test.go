@@ -0,0 +1,57 @@
+package catch
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbabilityFactors(t *testing.T) {
+	pidgey := pokemons.Pokemon{Species: "pidgey", Level: 10, HP: 20, MaxHP: 20}
+	attempt := Attempt{Pokeball: items.PokeBallItem, Pokemon: pidgey, TrainerLevel: 10}
+
+	probability, err := Probability(attempt)
+	assert.NoError(t, err)
+	assert.InDelta(t, .75*.9, probability, 1e-9)
+
+	attempt.Pokemon.HP = 0
+	weakened, _ := Probability(attempt)
+	assert.InDelta(t, 1, weakened, 1e-9)
+
+	attempt.Pokemon.HP = pidgey.HP
+	attempt.Pokemon.Level = 60
+	stronger, _ := Probability(attempt)
+	assert.InDelta(t, .75*.9*.5, stronger, 1e-9)
+
+	attempt.Pokeball = items.MasterBallItem
+	attempt.Pokemon.Species = "mewtwo"
+	master, _ := Probability(attempt)
+	assert.Equal(t, 1., master)
+}
+
+func TestTryOutcomes(t *testing.T) {
+	attempt := Attempt{Pokeball: items.MasterBallItem, Pokemon: pokemons.Pokemon{Species: "mewtwo", Level: 70}}
+	result, err := Try(attempt, random.New(1))
+	assert.NoError(t, err)
+	assert.Equal(t, Caught, result.Outcome)
+	assert.Equal(t, Shakes, result.Shakes)
+
+	attempt.Pokeball = items.PokeBallItem
+	outcomes := map[string]int{}
+	rng := random.New(1)
+	for i := 0; i < 200; i++ {
+		result, _ = Try(attempt, rng)
+		outcomes[result.Outcome]++
+		assert.NotEmpty(t, result.Reason)
+	}
+	assert.Greater(t, outcomes[Escaped], outcomes[Caught])
+	assert.Greater(t, outcomes[Fled], 0)
+}
+
+func TestTryRejectsOtherItems(t *testing.T) {
+	_, err := Try(Attempt{Pokeball: items.HealItem}, nil)
+	assert.Equal(t, ErrorNotAPokeball, err)
+}
@@ -0,0 +1,9 @@
+package catch
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNotAPokeball = errors.New("item is not a pokeball")
+)
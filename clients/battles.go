@@ -219,3 +219,18 @@ func (client *BattleLobbyClient) RejectChallenge(authToken, battleId, serverHost
 
 	return nil
 }
+
+func (client *BattleLobbyClient) GetBattleLog(battleId string) (*utils.Battle, error) {
+	req, err := client.BuildRequest(http.MethodGet, client.BattlesAddr, fmt.Sprintf(api.GetBattleLogPath, battleId), nil)
+	if err != nil {
+		return nil, errors.WrapGetBattleLogError(err)
+	}
+
+	var battle utils.Battle
+	_, err = DoRequest(client.httpClient, req, &battle, client.commsManager)
+	if err != nil {
+		return nil, errors.WrapGetBattleLogError(err)
+	}
+
+	return &battle, nil
+}
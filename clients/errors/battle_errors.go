@@ -8,6 +8,7 @@ const (
 	errorChallengeForBattle    = "error challenging for battle"
 	errorAcceptBattleChallenge = "error accepting battle challenge"
 	errorRejectBattleChallenge = "error rejecting battle challenge"
+	errorGetBattleLog          = "error getting battle log"
 )
 
 func WrapGetBattleLobbiesError(err error) error {
@@ -29,3 +30,7 @@ func WrapAcceptBattleChallengeError(err error) error {
 func WrapRejectBattleChallengeError(err error) error {
 	return errors.Wrap(err, errorRejectBattleChallenge)
 }
+
+func WrapGetBattleLogError(err error) error {
+	return errors.Wrap(err, errorGetBattleLog)
+}
@@ -10,6 +10,7 @@ const (
 	errorGetServerForLocation = "error fetching server for current location"
 	errorUpdateConnections    = "error updating connections"
 	errorHandleLocationMsg    = "error handling location message"
+	errorPredictHandover      = "error predicting handover"
 )
 
 var (
@@ -44,3 +45,7 @@ func WrapUpdateConnectionsError(err error) error {
 func WrapHandleLocationMsgError(err error) error {
 	return errors.Wrap(err, errorHandleLocationMsg)
 }
+
+func WrapPredictHandoverError(err error) error {
+	return errors.Wrap(err, errorPredictHandover)
+}
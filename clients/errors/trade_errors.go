@@ -8,6 +8,7 @@ const (
 	errorJoinTradeLobby           = "error joining trade lobby"
 	errorRejectTradeLobby         = "error rejecting trade lobby"
 	errorHandleMessagesTradeLobby = "error handling received messages"
+	errorGetTradeHistory          = "error getting trade history"
 )
 
 func WrapGetTradeLobbiesError(err error) error {
@@ -29,3 +30,7 @@ func WrapRejectTradeLobbyError(err error) error {
 func WrapHandleMessagesTradeError(err error) error {
 	return errors.Wrap(err, errorHandleMessagesTradeLobby)
 }
+
+func WrapGetTradeHistoryError(err error) error {
+	return errors.Wrap(err, errorGetTradeHistory)
+}
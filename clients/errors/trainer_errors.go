@@ -11,6 +11,7 @@ const (
 	errorRemoveItem           = "error removing item from trainer"
 	errorAddPokemon           = "error adding pokemon to trainer"
 	errorUpdatePokemon        = "error updating pokemon"
+	errorAddPokemonExperience = "error adding experience to pokemon"
 	errorRemovePokemon        = "error removing pokemon from trainer"
 	errorGetAllTokens         = "error getting all tokens"
 	errorGetStatsToken        = "error getting stats token"
@@ -57,6 +58,10 @@ func WrapUpdatePokemonError(err error) error {
 	return errors.Wrap(err, errorUpdatePokemon)
 }
 
+func WrapAddPokemonExperienceError(err error) error {
+	return errors.Wrap(err, errorAddPokemonExperience)
+}
+
 func WrapRemovePokemonError(err error) error {
 	return errors.Wrap(err, errorRemovePokemon)
 }
@@ -77,6 +77,8 @@ func (g *GymClient) CreateRaid(serverHostname, gymName string) error {
 	return errors.WrapCreateRaidError(err)
 }
 
+// EnterRaid connects to the raid of the gym, which the gym server plays with a battles.RaidSession.
+// The raid's phase is received as a battles.RaidPhaseMessage when joining and every time it changes.
 func (g *GymClient) EnterRaid(authToken string, pokemonsTokens []string, statsToken, itemsToken string,
 	gymId, serverHostname string) (*websocket.Conn, *battles.BattleChannels, error) {
 	u := url.URL{
@@ -0,0 +1,240 @@
+package clients
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/s2"
+)
+
+const (
+	defaultHandoverIntervals = 2
+	// servers found through the location service are cached for cells of this level, for a while
+	// since cells may move to other servers
+	predictionCellLevel = 15
+	cachedServerTTL     = 5 * time.Minute
+	maxCachedServers    = 1000
+)
+
+// handover tracks which location servers the client needs: those the location service asked
+// for, those it predicts it will need soon and those it stopped needing less than a grace period
+// ago. It also measures how long the client waits for a server after being told to use it.
+type handover struct {
+	lock sync.Mutex
+
+	required  map[string]struct{}
+	keepUntil map[string]time.Time
+	predicted map[string]struct{}
+
+	established  map[string]struct{}
+	pendingSince map[string]time.Time
+
+	cellsPerServer map[string]s2.CellUnion
+	serverForCell  map[s2.CellID]cachedServer
+}
+
+type cachedServer struct {
+	server string
+	until  time.Time
+}
+
+func newHandover() *handover {
+	return &handover{
+		required:       map[string]struct{}{},
+		keepUntil:      map[string]time.Time{},
+		predicted:      map[string]struct{}{},
+		established:    map[string]struct{}{},
+		pendingSince:   map[string]time.Time{},
+		cellsPerServer: map[string]s2.CellUnion{},
+		serverForCell:  map[s2.CellID]cachedServer{},
+	}
+}
+
+// require replaces the servers the location service asked for. Servers it no longer asks for
+// are kept until the grace period ends.
+func (h *handover) require(servers []string, now time.Time, grace time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	firstServers := len(h.required) == 0
+	required := make(map[string]struct{}, len(servers))
+
+	for _, server := range servers {
+		required[server] = struct{}{}
+		if _, ok := h.required[server]; ok || firstServers {
+			continue
+		}
+
+		_, predicted := h.predicted[server]
+		delete(h.predicted, server)
+		handoversCounter.WithLabelValues(strconv.FormatBool(predicted)).Inc()
+
+		if _, ok := h.established[server]; ok {
+			handoverLatency.Observe(0)
+		} else {
+			h.pendingSince[server] = now
+		}
+	}
+
+	for server := range h.required {
+		if _, ok := required[server]; !ok {
+			h.keepLocked(server, now.Add(grace))
+		}
+	}
+
+	h.required = required
+}
+
+// predict keeps the server until the given time, marking it as predicted when the client was not
+// going to connect to it otherwise
+func (h *handover) predict(server string, until time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	_, required := h.required[server]
+	_, kept := h.keepUntil[server]
+	if !required && !kept {
+		h.predicted[server] = struct{}{}
+	}
+
+	h.keepLocked(server, until)
+}
+
+func (h *handover) keepLocked(server string, until time.Time) {
+	if until.After(h.keepUntil[server]) {
+		h.keepUntil[server] = until
+	}
+}
+
+// wanted returns the servers the client should be connected to, forgetting those whose grace
+// period has ended
+func (h *handover) wanted(now time.Time) map[string]struct{} {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	wanted := make(map[string]struct{}, len(h.required)+len(h.keepUntil))
+	for server := range h.required {
+		wanted[server] = struct{}{}
+	}
+
+	for server, until := range h.keepUntil {
+		if now.After(until) {
+			delete(h.keepUntil, server)
+			continue
+		}
+
+		wanted[server] = struct{}{}
+	}
+
+	return wanted
+}
+
+func (h *handover) isRequired(server string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	_, ok := h.required[server]
+	return ok
+}
+
+// connected records that the connection to the server is up, ending the wait for it if the
+// client was told to use it
+func (h *handover) connected(server string, now time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.established[server] = struct{}{}
+
+	if since, ok := h.pendingSince[server]; ok {
+		handoverLatency.Observe(now.Sub(since).Seconds())
+		delete(h.pendingSince, server)
+	}
+}
+
+// disconnected records that the client dropped the connection to the server
+func (h *handover) disconnected(server string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.predicted[server]; ok {
+		wastedPreconnectionsCounter.Inc()
+		delete(h.predicted, server)
+	}
+
+	delete(h.established, server)
+	delete(h.pendingSince, server)
+}
+
+// dropped records that the connection to the server went down. The client will connect to it
+// again, so it stays predicted.
+func (h *handover) dropped(server string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.established, server)
+}
+
+// setCells records the cells servers told the client about, which replace the servers cached for
+// those cells
+func (h *handover) setCells(cellsPerServer map[string]s2.CellUnion) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for server, cells := range cellsPerServer {
+		normalized := append(s2.CellUnion(nil), cells...)
+		normalized.Normalize()
+		h.cellsPerServer[server] = normalized
+
+		for cellId := range h.serverForCell {
+			if normalized.IntersectsCellID(cellId) {
+				delete(h.serverForCell, cellId)
+			}
+		}
+	}
+}
+
+// serverFor looks for the server of the location among the cells servers told the client about
+// and the ones it recently asked the location service for
+func (h *handover) serverFor(loc s2.LatLng, now time.Time) (string, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	cellId := s2.CellIDFromLatLng(loc)
+	for server, cells := range h.cellsPerServer {
+		if cells.ContainsCellID(cellId) {
+			return server, true
+		}
+	}
+
+	cached, ok := h.serverForCell[cellId.Parent(predictionCellLevel)]
+	if !ok || now.After(cached.until) {
+		return "", false
+	}
+
+	return cached.server, true
+}
+
+// cacheServer remembers the server of the location for cachedServerTTL. When the cache is full
+// the expired entries are dropped, or every entry if that frees no room.
+func (h *handover) cacheServer(loc s2.LatLng, server string, now time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.serverForCell) >= maxCachedServers {
+		for cellId, cached := range h.serverForCell {
+			if now.After(cached.until) {
+				delete(h.serverForCell, cellId)
+			}
+		}
+
+		if len(h.serverForCell) >= maxCachedServers {
+			h.serverForCell = map[s2.CellID]cachedServer{}
+		}
+	}
+
+	h.serverForCell[s2.CellIDFromLatLng(loc).Parent(predictionCellLevel)] = cachedServer{
+		server: server,
+		until:  now.Add(cachedServerTTL),
+	}
+}
@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGrace = 10 * time.Second
+
+var testNow = time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func TestHandoverGracePeriod(t *testing.T) {
+	h := newHandover()
+
+	h.require([]string{"a", "b"}, testNow, testGrace)
+	h.require([]string{"b"}, testNow, testGrace)
+
+	assert.Contains(t, h.wanted(testNow.Add(testGrace)), "a")
+	assert.NotContains(t, h.wanted(testNow.Add(testGrace+time.Second)), "a")
+	assert.Contains(t, h.wanted(testNow.Add(time.Hour)), "b")
+	assert.False(t, h.isRequired("a"))
+}
+
+func TestPredictedHandover(t *testing.T) {
+	h := newHandover()
+	predictedBefore := testutil.ToFloat64(handoversCounter.WithLabelValues("true"))
+
+	h.require([]string{"a"}, testNow, testGrace)
+	h.predict("b", testNow.Add(testGrace))
+	assert.Contains(t, h.wanted(testNow), "b")
+	assert.False(t, h.isRequired("b"))
+
+	h.connected("b", testNow)
+	h.require([]string{"a", "b"}, testNow.Add(time.Second), testGrace)
+
+	assert.True(t, h.isRequired("b"))
+	assert.Empty(t, h.pendingSince)
+	assert.Equal(t, predictedBefore+1, testutil.ToFloat64(handoversCounter.WithLabelValues("true")))
+}
+
+func TestUnpredictedHandoverWaits(t *testing.T) {
+	h := newHandover()
+
+	h.require([]string{"a"}, testNow, testGrace)
+	h.require([]string{"a", "b"}, testNow, testGrace)
+	assert.Equal(t, testNow, h.pendingSince["b"])
+
+	h.connected("b", testNow.Add(time.Second))
+	assert.Empty(t, h.pendingSince)
+}
+
+func TestWastedPreconnection(t *testing.T) {
+	h := newHandover()
+	wastedBefore := testutil.ToFloat64(wastedPreconnectionsCounter)
+
+	h.predict("b", testNow.Add(testGrace))
+	h.connected("b", testNow)
+	h.disconnected("b")
+
+	assert.Equal(t, wastedBefore+1, testutil.ToFloat64(wastedPreconnectionsCounter))
+}
+
+func TestDroppedConnectionWaitsForTheNextOne(t *testing.T) {
+	h := newHandover()
+
+	h.require([]string{"a"}, testNow, testGrace)
+	h.predict("b", testNow.Add(testGrace))
+	h.connected("b", testNow)
+	h.dropped("b")
+	assert.Contains(t, h.predicted, "b")
+
+	h.require([]string{"a", "b"}, testNow.Add(time.Second), testGrace)
+	assert.Equal(t, testNow.Add(time.Second), h.pendingSince["b"])
+
+	h.connected("b", testNow.Add(2*time.Second))
+	assert.Empty(t, h.pendingSince)
+}
+
+func TestHandoverServerFor(t *testing.T) {
+	h := newHandover()
+	loc := s2.LatLngFromDegrees(38.7, -9.1)
+
+	_, ok := h.serverFor(loc, testNow)
+	assert.False(t, ok)
+
+	h.setCells(map[string]s2.CellUnion{"a": {s2.CellIDFromLatLng(loc).Parent(10)}})
+	server, ok := h.serverFor(loc, testNow)
+	assert.True(t, ok)
+	assert.Equal(t, "a", server)
+
+	other := s2.LatLngFromDegrees(40, -8)
+	h.cacheServer(other, "b", testNow)
+	server, ok = h.serverFor(other, testNow)
+	assert.True(t, ok)
+	assert.Equal(t, "b", server)
+}
+
+func TestHandoverCacheExpires(t *testing.T) {
+	h := newHandover()
+	loc := s2.LatLngFromDegrees(40, -8)
+
+	h.cacheServer(loc, "b", testNow)
+	_, ok := h.serverFor(loc, testNow.Add(cachedServerTTL+time.Second))
+	assert.False(t, ok)
+
+	// servers announcing their cells replace what was cached for them
+	h.cacheServer(loc, "b", testNow)
+	h.setCells(map[string]s2.CellUnion{"c": {s2.CellIDFromLatLng(loc).Parent(10)}})
+	server, _ := h.serverFor(loc, testNow)
+	assert.Equal(t, "c", server)
+	assert.Empty(t, h.serverForCell)
+
+	for i := 0; i < 2*maxCachedServers; i++ {
+		h.cacheServer(s2.LatLngFromDegrees(float64(i)/100, 0), "b", testNow)
+	}
+	assert.LessOrEqual(t, len(h.serverForCell), maxCachedServers)
+}
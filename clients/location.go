@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -21,6 +20,8 @@ import (
 	errors2 "github.com/NOVAPokemon/utils/clients/errors"
 	"github.com/NOVAPokemon/utils/gps"
 	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/movement"
+	"github.com/NOVAPokemon/utils/random"
 	"github.com/NOVAPokemon/utils/tokens"
 	ws "github.com/NOVAPokemon/utils/websockets"
 	"github.com/NOVAPokemon/utils/websockets/comms_manager"
@@ -36,11 +37,28 @@ type (
 	}
 
 	toConnChansValueType     = chan *ws.WebsocketMsg
-	finishConnChansValueType = chan struct{}
+	finishConnChansValueType = *connectionFinish
 	connectionsValueType     = *websocket.Conn
 	gymsValueType            = []utils.GymWithServer
 )
 
+// connectionFinish is closed when the client no longer wants a server, stopping the routine
+// connecting to it. Closing it more than once is fine.
+type connectionFinish struct {
+	finish    chan struct{}
+	closeOnce sync.Once
+}
+
+func newConnectionFinish() *connectionFinish {
+	return &connectionFinish{finish: make(chan struct{})}
+}
+
+func (f *connectionFinish) close() {
+	f.closeOnce.Do(func() {
+		close(f.finish)
+	})
+}
+
 type LocationClient struct {
 	LocationAddr string
 	config       utils.LocationClientConfig
@@ -51,10 +69,9 @@ type LocationClient struct {
 	pokemonsLock sync.Mutex
 	pokemons     []utils.WildPokemonWithServer
 
-	CurrentLocation     s2.LatLng
-	LocationParameters  utils.LocationParameters
-	DistanceToStartLat  float64
-	DistanceToStartLong float64
+	CurrentLocation    s2.LatLng
+	LocationParameters utils.LocationParameters
+	Movement           movement.MovementModel
 
 	serversConnected []string
 	fromConnChan     chan *ws.WebsocketMsg
@@ -65,6 +82,8 @@ type LocationClient struct {
 	commsManager ws.CommunicationManager
 
 	updateConnectionsLock sync.Mutex
+	handover              *handover
+	authToken             string
 
 	*BasicClient
 }
@@ -98,35 +117,46 @@ func NewLocationClient(config utils.LocationClientConfig, startLocation s2.CellI
 		startingLocation = startLocation.LatLng()
 	}
 
+	movementModel, err := movement.New(config.Parameters, startingLocation, client.Rand)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if playback, ok := movementModel.(*movement.Playback); ok {
+		log.Info("playing back trajectory ", config.Parameters.Movement.Trajectory)
+		startingLocation = playback.Start()
+	}
+
 	return &LocationClient{
-		LocationAddr:        locationURL,
-		config:              config,
-		pokemonsLock:        sync.Mutex{},
-		gyms:                sync.Map{},
-		HttpClient:          httpClient,
-		CurrentLocation:     startingLocation,
-		LocationParameters:  config.Parameters,
-		DistanceToStartLat:  0.0,
-		DistanceToStartLong: 0.0,
-		serversConnected:    []string{},
-		fromConnChan:        make(chan *ws.WebsocketMsg, bufferSize),
-		finishConnChans:     sync.Map{},
-		connections:         sync.Map{},
-		toConnsChans:        sync.Map{},
-		commsManager:        manager,
-		BasicClient:         client,
+		LocationAddr:       locationURL,
+		config:             config,
+		pokemonsLock:       sync.Mutex{},
+		gyms:               sync.Map{},
+		HttpClient:         httpClient,
+		CurrentLocation:    startingLocation,
+		LocationParameters: config.Parameters,
+		Movement:           movementModel,
+		serversConnected:   []string{},
+		fromConnChan:       make(chan *ws.WebsocketMsg, bufferSize),
+		finishConnChans:    sync.Map{},
+		connections:        sync.Map{},
+		toConnsChans:       sync.Map{},
+		commsManager:       manager,
+		handover:           newHandover(),
+		BasicClient:        client,
 	}
 }
 
 func (c *LocationClient) StartLocationUpdates(authToken string) error {
 	catchPokemonResponses = make(chan *location.CatchWildPokemonMessageResponse)
+	c.authToken = authToken
 
 	serverURL, err := c.GetServerForLocation(c.CurrentLocation)
 	if err != nil {
 		return errors2.WrapStartLocationUpdatesError(err)
 	}
 
-	go c.restartConnectionIfFails(serverURL, authToken)
+	c.updateConnections([]string{serverURL}, authToken)
 
 	go c.updateLocationLoop()
 
@@ -144,32 +174,42 @@ func (c *LocationClient) StartLocationUpdates(authToken string) error {
 	return errors.New("stopped updating location")
 }
 
-func (c *LocationClient) restartConnectionIfFails(serverUrl, authToken string) {
+// restartConnectionIfFails keeps a connection to the server until finished, reconnecting when
+// connecting fails or the connection drops
+func (c *LocationClient) restartConnectionIfFails(serverUrl, authToken string, finish *connectionFinish) {
 	defer func() {
 		log.Infof("stopping conection routine to %s", serverUrl)
 	}()
 
 	for {
-		err, finish, failed := c.handleLocationConnection(serverUrl, authToken)
+		err, done, failed := c.handleLocationConnection(serverUrl, authToken)
 		if err != nil {
 			log.Error(err)
+		} else {
+			select {
+			case <-finish.finish:
+			case <-failed:
+				c.toConnsChans.Delete(serverUrl)
+				c.connections.Delete(serverUrl)
+				c.handover.dropped(serverUrl)
+			}
+			close(done)
 		}
 
 		select {
-		case <-finish:
+		case <-finish.finish:
 			return
-		case <-failed:
-			close(finish)
-			break
+		case <-time.After(ws.Timeout):
 		}
 
-		time.Sleep(ws.Timeout)
 		log.Infof("restarting connection to %s", serverUrl)
 	}
 }
 
+// handleLocationConnection connects to the server and starts reading and writing messages until
+// done is closed. Failed is closed if the connection drops.
 func (c *LocationClient) handleLocationConnection(serverUrl, authToken string) (err error,
-	finish, failed chan struct{}) {
+	done, failed chan struct{}) {
 	outChan := make(toConnChansValueType, bufferSize)
 
 	conn, err := c.connect(serverUrl, outChan, authToken)
@@ -177,31 +217,30 @@ func (c *LocationClient) handleLocationConnection(serverUrl, authToken string) (
 		return errors2.WrapStartLocationUpdatesError(err), nil, nil
 	}
 
-	finish = make(chan struct{})
+	done = make(chan struct{})
 	failed = make(chan struct{})
 
 	c.toConnsChans.Store(serverUrl, outChan)
-	c.finishConnChans.Store(serverUrl, finish)
 	c.connections.Store(serverUrl, conn)
-	c.serversConnected = append(c.serversConnected, serverUrl)
+	c.handover.connected(serverUrl, time.Now())
 
 	SetDefaultPingHandler(conn, outChan)
 
 	closeWithFailure := func() {
 		close(failed)
 	}
-	closeFinishOnce := sync.Once{}
+	closeFailedOnce := sync.Once{}
 
 	go func() {
-		err := ReadMessagesFromConnToChanWithoutClosing(conn, c.fromConnChan, finish, c.commsManager)
+		err := ReadMessagesFromConnToChanWithoutClosing(conn, c.fromConnChan, done, c.commsManager)
 		if err != nil {
-			closeFinishOnce.Do(closeWithFailure)
+			closeFailedOnce.Do(closeWithFailure)
 		}
 	}()
 	go func() {
-		err := WriteTextMessagesFromChanToConn(conn, c.commsManager, outChan, finish)
+		err := WriteTextMessagesFromChanToConn(conn, c.commsManager, outChan, done)
 		if err != nil {
-			closeFinishOnce.Do(closeWithFailure)
+			closeFailedOnce.Do(closeWithFailure)
 		}
 	}()
 
@@ -247,10 +286,7 @@ func (c *LocationClient) handleLocationMsg(wsMsg *ws.WebsocketMsg, authToken str
 		}
 
 		log.Info("received servers ", serversMsg.Servers)
-		err := c.updateConnections(serversMsg.Servers, authToken)
-		if err != nil {
-			return errors2.WrapHandleLocationMsgError(err)
-		}
+		c.updateConnections(serversMsg.Servers, authToken)
 	case location.CellsResponse:
 		cellsMsg := &location.CellsPerServerMessage{}
 		if err := mapstructure.Decode(msgData, cellsMsg); err != nil {
@@ -259,6 +295,8 @@ func (c *LocationClient) handleLocationMsg(wsMsg *ws.WebsocketMsg, authToken str
 
 		log.Infof("received tiles from %s", cellsMsg.OriginServer)
 
+		c.handover.setCells(cellsMsg.CellsPerServer)
+
 		c.updateLocationWithCells(cellsMsg.CellsPerServer, cellsMsg.OriginServer)
 	case ws.Error:
 		errMsg := &ws.ErrorMessage{}
@@ -277,71 +315,109 @@ func (c *LocationClient) handleLocationMsg(wsMsg *ws.WebsocketMsg, authToken str
 	return nil
 }
 
-func (c *LocationClient) updateConnections(servers []string, authToken string) error {
+// updateConnections connects to the servers the location service asked for. Servers it no
+// longer asks for are disconnected once the grace period ends.
+func (c *LocationClient) updateConnections(servers []string, authToken string) {
 	c.updateConnectionsLock.Lock()
 	defer c.updateConnectionsLock.Unlock()
 
-	var isNewServer bool
-
-	var toRemove []string
-	var newServers []string
-
-	// Add new connections
-	for i := range servers {
-		isNewServer = true
+	now := time.Now()
+	c.handover.require(servers, now, c.handoverGracePeriod())
+	c.syncConnections(authToken, now)
+}
 
-		for j := range c.serversConnected {
-			if servers[i] == c.serversConnected[j] {
-				isNewServer = false
-			}
+// syncConnections connects to the servers the client wants and disconnects from the others.
+// Callers must hold updateConnectionsLock.
+func (c *LocationClient) syncConnections(authToken string, now time.Time) {
+	wanted := c.handover.wanted(now)
+
+	var serversConnected []string
+	for _, server := range c.serversConnected {
+		if _, ok := wanted[server]; ok {
+			serversConnected = append(serversConnected, server)
+			delete(wanted, server)
+			continue
 		}
 
-		if isNewServer {
-			go c.restartConnectionIfFails(servers[i], authToken)
-			newServers = append(newServers, servers[i])
+		log.Info("finishing connection to ", server)
+		if finishValue, ok := c.finishConnChans.Load(server); ok {
+			finishValue.(finishConnChansValueType).close()
 		}
+		c.finishConnChans.Delete(server)
+		c.toConnsChans.Delete(server)
+		c.connections.Delete(server)
+		c.handover.disconnected(server)
+	}
+
+	for server := range wanted {
+		finish := newConnectionFinish()
+		c.finishConnChans.Store(server, finish)
+		go c.restartConnectionIfFails(server, authToken, finish)
+		serversConnected = append(serversConnected, server)
 	}
 
-	// Remove unused connections
-	remove := true
-	for i := range c.serversConnected {
-		remove = true
-		for j := range servers {
-			if c.serversConnected[i] == servers[j] {
-				remove = false
+	c.serversConnected = serversConnected
+}
+
+// predictHandover connects ahead of time to the servers of the locations the client will go
+// through if it keeps its heading and speed. Servers are looked up before taking
+// updateConnectionsLock, so slow lookups don't hold back the servers the location service asks for.
+func (c *LocationClient) predictHandover(from s2.LatLng, elapsed time.Duration) {
+	now := time.Now()
+	lookahead := c.handoverDuration(c.config.Handover.Lookahead)
+
+	distance := gps.Distance(from, c.CurrentLocation)
+	if !c.config.Handover.DisablePrediction && distance > 0 && elapsed > 0 {
+		heading := gps.InitialBearing(from, c.CurrentLocation)
+		speed := distance / elapsed.Seconds()
+		until := now.Add(lookahead + c.handoverGracePeriod())
+
+		for ahead := elapsed; ahead <= lookahead; ahead += elapsed {
+			predicted := gps.Destination(c.CurrentLocation, heading, speed*ahead.Seconds())
+
+			server, err := c.predictedServerFor(predicted, now)
+			if err != nil {
+				log.Warn(errors2.WrapPredictHandoverError(err))
 				break
 			}
-		}
 
-		if remove {
-			finishChanValue, ok := c.finishConnChans.Load(c.serversConnected[i])
-			if !ok {
-				return errors.New("tried to finish location connection without a finish chan")
-			}
-			log.Info("finishing connection to ", c.serversConnected[i])
-			close(finishChanValue.(finishConnChansValueType))
-			c.toConnsChans.Delete(c.serversConnected[i])
-			toRemove = append(toRemove, c.serversConnected[i])
+			c.handover.predict(server, until)
 		}
 	}
 
-	var add bool
-	for i := range c.serversConnected {
-		add = true
-		for j := range toRemove {
-			if c.serversConnected[i] == toRemove[j] {
-				add = false
-			}
-		}
+	c.updateConnectionsLock.Lock()
+	defer c.updateConnectionsLock.Unlock()
 
-		if add {
-			newServers = append(newServers, c.serversConnected[i])
-		}
+	c.syncConnections(c.authToken, now)
+}
+
+func (c *LocationClient) predictedServerFor(loc s2.LatLng, now time.Time) (string, error) {
+	if server, ok := c.handover.serverFor(loc, now); ok {
+		return server, nil
 	}
 
-	c.serversConnected = newServers
+	server, err := c.GetServerForLocation(loc)
+	if err != nil {
+		return "", err
+	}
 
-	return nil
+	c.handover.cacheServer(loc, server, now)
+
+	return server, nil
+}
+
+func (c *LocationClient) handoverGracePeriod() time.Duration {
+	return c.handoverDuration(c.config.Handover.GracePeriod)
+}
+
+// handoverDuration converts seconds from the handover config, defaulting to a few update
+// intervals
+func (c *LocationClient) handoverDuration(seconds int) time.Duration {
+	if seconds <= 0 {
+		seconds = defaultHandoverIntervals * c.config.UpdateInterval
+	}
+
+	return time.Duration(seconds) * time.Second
 }
 
 func (c *LocationClient) connect(serverUrl string, outChan chan *ws.WebsocketMsg,
@@ -386,14 +462,20 @@ func (c *LocationClient) connect(serverUrl string, outChan chan *ws.WebsocketMsg
 }
 
 func (c *LocationClient) updateLocationLoop() {
-	updateTicker := time.NewTicker(time.Duration(c.config.UpdateInterval) * time.Second)
+	updateInterval := time.Duration(c.config.UpdateInterval) * time.Second
+	updateTicker := time.NewTicker(updateInterval)
 
 	for range updateTicker.C {
 		c.updateLocation()
 
-		if rand.Float64() <= c.LocationParameters.MovingProbability {
-			c.CurrentLocation = c.move(c.config.UpdateInterval)
+		// playbacks follow the recording instead of moving at random
+		_, playingBack := c.Movement.(*movement.Playback)
+		previousLocation := c.CurrentLocation
+		if playingBack || c.Rand.Float64() <= c.LocationParameters.MovingProbability {
+			c.CurrentLocation = c.Movement.Move(c.CurrentLocation, updateInterval)
 		}
+
+		c.predictHandover(previousLocation, updateInterval)
 	}
 }
 
@@ -409,8 +491,12 @@ func (c *LocationClient) updateLocation() {
 
 	log.Info("updating location: ", c.CurrentLocation)
 
-	// Only runs once
+	// Only runs once, for one of the servers the location service asked for
 	c.toConnsChans.Range(func(serverUrl, toConnChanValue interface{}) bool {
+		if !c.handover.isRequired(serverUrl.(string)) {
+			return true
+		}
+
 		log.Infof("updating location to %s", serverUrl)
 
 		toConnChan := toConnChanValue.(toConnChansValueType)
@@ -440,7 +526,7 @@ func (c *LocationClient) updateLocationWithCells(tilesPerServer map[string]s2.Ce
 	log.Infof("updating location with tiles %v", tilesPerServer)
 
 	c.toConnsChans.Range(func(serverUrl, toConnChanValue interface{}) bool {
-		if serverUrl == excludeServer {
+		if serverUrl == excludeServer || !c.handover.isRequired(serverUrl.(string)) {
 			return true
 		}
 
@@ -451,26 +537,6 @@ func (c *LocationClient) updateLocationWithCells(tilesPerServer map[string]s2.Ce
 	})
 }
 
-func (c *LocationClient) move(timePassed int) s2.LatLng {
-	randAngle := rand.Float64() * 2 * math.Pi
-	distanceTraveled := rand.Float64() * c.LocationParameters.MaxMovingSpeed * float64(timePassed)
-
-	dLat := randAngle * math.Sin(distanceTraveled)
-	if math.Abs(c.DistanceToStartLat+dLat) > float64(c.LocationParameters.MaxDistanceFromStart) {
-		dLat *= -1
-	}
-
-	dLong := randAngle * math.Cos(distanceTraveled)
-	if math.Abs(c.DistanceToStartLong+dLong) > float64(c.LocationParameters.MaxDistanceFromStart) {
-		dLong *= -1
-	}
-
-	c.DistanceToStartLat += dLat
-	c.DistanceToStartLong += dLong
-
-	return gps.CalcLocationPlusDistanceTraveled(c.CurrentLocation, dLat, dLong)
-}
-
 func (c *LocationClient) AddGymLocation(gym utils.GymWithServer) error {
 	req, err := c.BuildRequest("POST", c.LocationAddr, api.GymLocationRoute, gym)
 	if err != nil {
@@ -506,7 +572,7 @@ func (c *LocationClient) CatchWildPokemon(trainersClient *TrainersClient) error
 		return errors2.WrapCatchWildPokemonError(err)
 	}
 
-	pokeball, err := getRandomPokeball(itemsToken.Items)
+	pokeball, err := getRandomPokeball(c.Rand, itemsToken.Items)
 	if err != nil {
 		return errors2.WrapCatchWildPokemonError(err)
 	}
@@ -520,7 +586,7 @@ func (c *LocationClient) CatchWildPokemon(trainersClient *TrainersClient) error
 		return nil
 	}
 
-	toCatch := c.pokemons[rand.Intn(pokemonsLen)]
+	toCatch := c.pokemons[c.Rand.Intn(pokemonsLen)]
 	c.pokemonsLock.Unlock()
 
 	log.Info("will try to catch ", toCatch.Pokemon.Species)
@@ -546,19 +612,23 @@ func (c *LocationClient) CatchWildPokemon(trainersClient *TrainersClient) error
 	}
 
 	if !catchResponse.Caught {
-		log.Info("pokemon got away")
+		if catchResponse.Reason != "" {
+			log.Info(catchResponse.Reason)
+		} else {
+			log.Info("pokemon got away")
+		}
 		return nil
 	}
 
 	return trainersClient.AppendPokemonTokens(catchResponse.PokemonTokens)
 }
 
-func getRandomPokeball(itemsFromToken map[string]items.Item) (*items.Item, error) {
+func getRandomPokeball(rng random.Source, itemsFromToken items.Inventory) (*items.Item, error) {
 	var pokeballs []*items.Item
-	for _, item := range itemsFromToken {
+	for _, name := range itemsFromToken.Names() {
+		item, _ := itemsFromToken.Item(name)
 		if item.IsPokeBall() {
-			toAdd := item
-			pokeballs = append(pokeballs, &toAdd)
+			pokeballs = append(pokeballs, &item)
 		}
 	}
 
@@ -566,10 +636,14 @@ func getRandomPokeball(itemsFromToken map[string]items.Item) (*items.Item, error
 		return nil, errors2.ErrorNoPokeballs
 	}
 
-	return pokeballs[rand.Intn(len(pokeballs))], nil
+	return pokeballs[rng.Intn(len(pokeballs))], nil
 }
 
-func GetRandomLatLng(region string) s2.LatLng {
+// GetRandomLatLng picks a random location in one of the areas of the region. A nil rng uses the
+// global source.
+func GetRandomLatLng(rng random.Source, region string) s2.LatLng {
+	rng = random.OrGlobal(rng)
+
 	var (
 		regionsToAreaPath string
 		ok                bool
@@ -580,12 +654,12 @@ func GetRandomLatLng(region string) s2.LatLng {
 
 	regionsToArea := loadRegionsToArea(regionsToAreaPath)
 	areas := regionsToArea.Regions[region]
-	randArea := areas[rand.Intn(len(areas))]
+	randArea := areas[rng.Intn(len(areas))]
 
 	deltaLat := math.Abs(randArea.TopLeft.Lat - randArea.BotRight.Lat)
 	deltaLng := math.Abs(randArea.TopLeft.Lng - randArea.BotRight.Lng)
-	randomLat := randArea.TopLeft.Lat - rand.Float64()*(deltaLat)
-	randomLng := randArea.BotRight.Lng - rand.Float64()*(deltaLng)
+	randomLat := randArea.TopLeft.Lat - rng.Float64()*(deltaLat)
+	randomLng := randArea.BotRight.Lng - rng.Float64()*(deltaLng)
 
 	randomLatLng := s2.LatLngFromDegrees(randomLat, randomLng)
 
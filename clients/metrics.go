@@ -0,0 +1,26 @@
+package clients
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const predictedLabel = "predicted"
+
+var (
+	handoverLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "location_client_handover_latency_seconds",
+		Help:    "Time between the location service asking for a server and the connection to it being up",
+		Buckets: prometheus.ExponentialBuckets(.005, 2, 12),
+	})
+
+	handoversCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "location_client_handovers_total",
+		Help: "Servers the location service asked for, by whether the client predicted them",
+	}, []string{predictedLabel})
+
+	wastedPreconnectionsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "location_client_wasted_preconnections_total",
+		Help: "Connections opened from predictions and closed without being needed",
+	})
+)
@@ -2,7 +2,6 @@ package clients
 
 import (
 	"fmt"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -75,6 +74,24 @@ func (t *TradeLobbyClient) GetAvailableLobbies() ([]utils.Lobby, error) {
 	return tradesArray, nil
 }
 
+// GetTradeHistory returns the trades of the trainer the auth token belongs to
+func (t *TradeLobbyClient) GetTradeHistory(authToken string) ([]utils.TradeRecord, error) {
+	req, err := t.BuildRequest(http.MethodGet, t.TradesAddr, api.GetTradeHistoryPath, nil)
+	if err != nil {
+		return nil, errors.WrapGetTradeHistoryError(err)
+	}
+
+	req.Header.Set(tokens.AuthTokenHeaderName, authToken)
+
+	var history []utils.TradeRecord
+	_, err = DoRequest(t.client, req, &history, t.commsManager)
+	if err != nil {
+		return nil, errors.WrapGetTradeHistoryError(err)
+	}
+
+	return history, nil
+}
+
 func (t *TradeLobbyClient) CreateTradeLobby(username, authToken string,
 	itemsToken string) (*primitive.ObjectID, *string, error) {
 	body := api.CreateLobbyRequest{Username: username}
@@ -161,11 +178,12 @@ func (t *TradeLobbyClient) JoinTradeLobby(tradeId *primitive.ObjectID,
 
 	go ReadMessagesFromConnToChan(conn, t.readChannel, t.finished, t.commsManager)
 
-	itemIds := make([]string, len(items.Items))
-	i := 0
-	for k := range items.Items {
-		itemIds[i] = k
-		i++
+	// each item of a stack can be offered separately
+	itemIds := make([]string, 0, items.Items.Total())
+	for _, name := range items.Items.Names() {
+		for i := 0; i < items.Items.Quantity(name); i++ {
+			itemIds = append(itemIds, name)
+		}
 	}
 
 	t.WaitForStart()
@@ -290,7 +308,7 @@ func (t *TradeLobbyClient) autoTrader(availableItems []string) (*string, error)
 	if maxItemsToTrade == 0 {
 		numItemsToAdd = 0
 	} else {
-		numItemsToAdd = rand.Intn(maxItemsToTrade)
+		numItemsToAdd = t.Rand.Intn(maxItemsToTrade)
 	}
 
 	log.Infof("will trade %d items", numItemsToAdd)
@@ -351,10 +369,11 @@ func (t *TradeLobbyClient) sendTradeMessages(numItemsToAdd int, availableItems [
 				break
 			}
 
-			randomItemIdx := rand.Intn(len(availableItems))
+			randomItemIdx := t.Rand.Intn(len(availableItems))
 
-			t.writeChannel <- trades.TradeMessage{
-				ItemId: availableItems[randomItemIdx],
+			t.writeChannel <- trades.OfferMessage{
+				Asset: trades.ItemAsset,
+				Id:    availableItems[randomItemIdx],
 			}.ConvertToWSMessage()
 
 			log.Infof("adding %s to trade", availableItems[randomItemIdx])
@@ -372,7 +391,7 @@ func (t *TradeLobbyClient) sendTradeMessages(numItemsToAdd int, availableItems [
 func (t *TradeLobbyClient) setTimerRandSleepTime(timer *time.Timer) *time.Timer {
 	var randSleep int
 	if t.config.ThinkTime > 0 {
-		randSleep = rand.Intn(t.config.ThinkTime)
+		randSleep = t.Rand.Intn(t.config.ThinkTime)
 	}
 
 	log.Infof("sleeping %d milliseconds", randSleep)
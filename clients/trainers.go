@@ -117,7 +117,7 @@ func (c *TrainersClient) UpdateTrainerStats(username string, newStats utils.Trai
 // ITEMS
 
 func (c *TrainersClient) AddItems(username string, itemsToAdd []items.Item,
-	authToken string) (map[string]items.Item, error) {
+	authToken string) (items.Inventory, error) {
 	req, err := c.BuildRequest("POST", c.TrainersAddr, fmt.Sprintf(api.AddItemToBagPath, username), itemsToAdd)
 	if err != nil {
 		return nil, errors.WrapAddItemError(err)
@@ -125,7 +125,7 @@ func (c *TrainersClient) AddItems(username string, itemsToAdd []items.Item,
 
 	req.Header.Set(tokens.AuthTokenHeaderName, authToken)
 
-	var res map[string]items.Item
+	var res items.Inventory
 	resp, err := DoRequest(c.HttpClient, req, &res, c.commsManager)
 	if err != nil {
 		return nil, errors.WrapAddItemError(err)
@@ -136,8 +136,9 @@ func (c *TrainersClient) AddItems(username string, itemsToAdd []items.Item,
 	return res, errors.WrapAddItemError(err)
 }
 
+// RemoveItems removes one item from the stack of each name given
 func (c *TrainersClient) RemoveItems(username string, itemIds []string,
-	authToken string) (map[string]items.Item, error) {
+	authToken string) (items.Inventory, error) {
 	var itemIdsPath strings.Builder
 
 	itemIdsPath.WriteString(itemIds[0])
@@ -154,7 +155,7 @@ func (c *TrainersClient) RemoveItems(username string, itemIds []string,
 
 	req.Header.Set(tokens.AuthTokenHeaderName, authToken)
 
-	var res map[string]items.Item
+	var res items.Inventory
 	resp, err := DoRequest(c.HttpClient, req, &res, c.commsManager)
 	if err != nil {
 		return nil, errors.WrapRemoveItemError(err)
@@ -203,6 +204,28 @@ func (c *TrainersClient) UpdateTrainerPokemon(username, pokemonId string, pokemo
 	return &res, errors.WrapUpdatePokemonError(err)
 }
 
+// AddPokemonExperience gives experience to one of the trainer's pokemons. The pokemon tokens are
+// replaced by the returned ones, which reflect the stats grown on level up.
+func (c *TrainersClient) AddPokemonExperience(username, pokemonId string, xp float64,
+	authToken string) (*pokemons.Pokemon, error) {
+	req, err := c.BuildRequest("PUT", c.TrainersAddr, fmt.Sprintf(api.AddPokemonExperiencePath, username, pokemonId),
+		xp)
+	if err != nil {
+		return nil, errors.WrapAddPokemonExperienceError(err)
+	}
+
+	var res pokemons.Pokemon
+	req.Header.Set(tokens.AuthTokenHeaderName, authToken)
+
+	resp, err := DoRequest(c.HttpClient, req, &res, c.commsManager)
+	if err != nil {
+		return nil, errors.WrapAddPokemonExperienceError(err)
+	}
+
+	err = c.SetPokemonTokens(resp.Header)
+	return &res, errors.WrapAddPokemonExperienceError(err)
+}
+
 func (c *TrainersClient) RemovePokemonFromTrainer(username, pokemonId string) (*pokemons.Pokemon,
 	error) {
 	req, err := c.BuildRequest("GET", c.TrainersAddr, fmt.Sprintf(api.RemovePokemonPath, username, pokemonId), nil)
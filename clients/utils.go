@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/NOVAPokemon/utils/random"
 	"github.com/NOVAPokemon/utils/websockets"
 	ws "github.com/NOVAPokemon/utils/websockets"
 	"github.com/gorilla/websocket"
@@ -21,12 +22,17 @@ const (
 type BasicClient struct {
 	usingIngress bool
 	ingress      string
+
+	// Rand is derived from the global source, so clients created in the same order after
+	// seeding it with a logged seed make the same choices. It can be replaced before use.
+	Rand random.Source
 }
 
 func NewBasicClient(usingIngress bool, ingressURL string) *BasicClient {
 	return &BasicClient{
 		usingIngress: usingIngress,
 		ingress:      ingressURL,
+		Rand:         random.Derive(nil),
 	}
 }
 
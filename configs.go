@@ -17,6 +17,17 @@ type LocationClientConfig struct {
 	UpdateInterval int                `json:"update_interval"` // in seconds
 	Timeout        int                `json:"timeout"`
 	Parameters     LocationParameters `json:"params"`
+	Handover       HandoverConfig     `json:"handover"`
+}
+
+// HandoverConfig controls how the location client moves between servers. It predicts the
+// servers it will need Lookahead seconds ahead and connects to them beforehand, unless
+// DisablePrediction is set, and keeps connections it no longer needs for GracePeriod seconds.
+// Both default to two update intervals.
+type HandoverConfig struct {
+	DisablePrediction bool `json:"disable_prediction"`
+	Lookahead         int  `json:"lookahead"`
+	GracePeriod       int  `json:"grace_period"`
 }
 
 type BattleClientConfig struct {
@@ -30,14 +41,34 @@ type RaidConfig struct {
 
 // Location parameters describe how the user will move. The moving probability indicates
 // how likely is the user to move between location updates
-// MaxMovingSpeed should be in meters per second
+// MaxMovingSpeed should be in meters per second and MaxDistanceFromStart in meters, where 0
+// lets the user wander anywhere
 type LocationParameters struct {
-	StartingLocation     bool    `json:"starting_location"`
-	StartingLocationLat  float64 `json:"starting_location_lat"`
-	StartingLocationLon  float64 `json:"starting_location_lon"`
-	MaxMovingSpeed       float64 `json:"max_moving_speed"`
-	MovingProbability    float64 `json:"moving_probability"`
-	MaxDistanceFromStart int     `json:"max_distance_from_start"`
+	StartingLocation     bool               `json:"starting_location"`
+	StartingLocationLat  float64            `json:"starting_location_lat"`
+	StartingLocationLon  float64            `json:"starting_location_lon"`
+	MaxMovingSpeed       float64            `json:"max_moving_speed"`
+	MovingProbability    float64            `json:"moving_probability"`
+	MaxDistanceFromStart int                `json:"max_distance_from_start"`
+	Movement             MovementParameters `json:"movement"`
+}
+
+// MovementParameters choose and configure the movement model, a random walk by default.
+// PauseTime is in seconds and MinFlight in meters. MaxTurnAngle, in degrees, bounds how much
+// random walkers turn between updates, where 0 lets them turn to any direction. Commuters
+// travel between Home, the starting location when missing, and Work. Trajectory is a GPX or
+// GeoJSON file to play back, optionally looping and sped up by TimeScale.
+type MovementParameters struct {
+	Model        string  `json:"model"`
+	PauseTime    int     `json:"pause_time"`
+	MaxTurnAngle float64 `json:"max_turn_angle"`
+	Home         *Coord  `json:"home,omitempty"`
+	Work         *Coord  `json:"work,omitempty"`
+	LevyExponent float64 `json:"levy_exponent"`
+	MinFlight    float64 `json:"min_flight"`
+	Trajectory   string  `json:"trajectory,omitempty"`
+	Loop         bool    `json:"loop"`
+	TimeScale    float64 `json:"time_scale"`
 }
 
 type Coord struct {
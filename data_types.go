@@ -16,9 +16,33 @@ type Trainer struct {
 	// game info
 	Username string `json:"username" bson:"username,omitempty"`
 	Pokemons map[string]pokemons.Pokemon
-	Items    map[string]items.Item
+	Items    items.Inventory
 	Stats    TrainerStats
 	Location s2.LatLng
+
+	// trades being executed and the ones received while their escrow is being cleared, used to
+	// resume trades after a crash
+	Escrow         map[string]TradeEscrow `json:"-" bson:"escrow,omitempty"`
+	ReceivedTrades []string               `json:"-" bson:"receivedtrades,omitempty"`
+}
+
+// TradeOffer is what one of the trainers gives in a trade. ItemIds are item names, repeated
+// once for each item of the stack given.
+type TradeOffer struct {
+	Username   string
+	ItemIds    []string
+	PokemonIds []string
+	Coins      int
+}
+
+// TradeEscrow holds the assets a trainer gave in a trade until they are delivered to the recipient.
+// A trade is only committed once both trainers' assets are in escrow.
+type TradeEscrow struct {
+	Recipient string
+	Items     items.Inventory
+	Pokemons  map[string]pokemons.Pokemon
+	Coins     int
+	Committed bool
 }
 
 type TransactionTemplate struct {
@@ -40,11 +64,60 @@ type TrainerStats struct {
 }
 
 type Battle struct {
-	Trainer1 string
-	Trainer2 string
-	Winner   string
-	// TODO add more stuff? perharps a log of a battle
-	// logAddr url.URL
+	Id           string                   `json:"id" bson:"_id,omitempty"`
+	Trainer1     string                   `json:"trainer1" bson:"trainer1"`
+	Trainer2     string                   `json:"trainer2" bson:"trainer2"`
+	Winner       string                   `json:"winner" bson:"winner"`
+	Raid         bool                     `json:"raid" bson:"raid"`
+	Participants []string                 `json:"participants" bson:"participants"`
+	StartedAt    int64                    `json:"startedAt" bson:"startedat"`
+	FinishedAt   int64                    `json:"finishedAt" bson:"finishedat"`
+	Log          []BattleLogEntry         `json:"log" bson:"log"`
+	Summaries    map[string]BattleSummary `json:"summaries" bson:"summaries"`
+}
+
+// BattleLogEntry is a compact record of an action applied to a battle or raid and of the events
+// it produced. Offset is in milliseconds since the battle started and Player indexes Participants.
+type BattleLogEntry struct {
+	Offset int64            `json:"t" bson:"t"`
+	Player int              `json:"p" bson:"p"`
+	Action string           `json:"a" bson:"a"`
+	Arg    string           `json:"arg,omitempty" bson:"arg,omitempty"`
+	Events []BattleLogEvent `json:"e,omitempty" bson:"e,omitempty"`
+}
+
+type BattleLogEvent struct {
+	Type      string `json:"t" bson:"t"`
+	Player    int    `json:"p" bson:"p"`
+	PokemonId string `json:"pk,omitempty" bson:"pk,omitempty"`
+	HP        int    `json:"hp,omitempty" bson:"hp,omitempty"`
+	Damage    int    `json:"d,omitempty" bson:"d,omitempty"`
+	Info      string `json:"i,omitempty" bson:"i,omitempty"`
+}
+
+type BattleSummary struct {
+	DamageDealt int
+	DamageTaken int
+	ItemsUsed   int
+	Turns       int
+	Duration    int64 // in milliseconds
+}
+
+// Trade outcomes
+const (
+	TradeCompleted = "COMPLETED"
+	TradeRejected  = "REJECTED"
+	TradeAborted   = "ABORTED"
+)
+
+// TradeRecord keeps what was exchanged in a trade once its lobby closes. Times are in milliseconds.
+type TradeRecord struct {
+	Id           string        `json:"id" bson:"_id,omitempty"`
+	Participants []string      `json:"participants" bson:"participants"`
+	Offers       [2]TradeOffer `json:"offers" bson:"offers"`
+	Outcome      string        `json:"outcome" bson:"outcome"`
+	StartedAt    int64         `json:"startedAt" bson:"startedat"`
+	FinishedAt   int64         `json:"finishedAt" bson:"finishedat"`
 }
 
 type Lobby struct {
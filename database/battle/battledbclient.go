@@ -0,0 +1,102 @@
+package battle
+
+import (
+	"context"
+	"os"
+
+	"github.com/NOVAPokemon/utils"
+	databaseUtils "github.com/NOVAPokemon/utils/database"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const databaseName = "NOVAPokemonDB"
+const collectionName = "Battles"
+
+var dbClient databaseUtils.DBClient
+
+func init() {
+	url, exists := os.LookupEnv(utils.MongoEnvVar)
+	if !exists {
+		url = databaseUtils.DefaultMongoDBUrl
+	}
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(url))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	err = client.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	collection := client.Database(databaseName).Collection(collectionName)
+
+	index := mongo.IndexModel{
+		Keys: bson.M{"participants": 1},
+	}
+
+	_, _ = collection.Indexes().CreateOne(ctx, index)
+	dbClient = databaseUtils.DBClient{Client: client, Ctx: &ctx, Collection: collection}
+}
+
+func AddBattle(battle utils.Battle) (string, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	_, err := collection.InsertOne(*ctx, battle)
+	if err != nil {
+		return "", wrapAddBattleError(err, battle.Id)
+	}
+
+	log.Infof("Added battle %s with %d log entries", battle.Id, len(battle.Log))
+	return battle.Id, nil
+}
+
+func GetBattleById(battleId string) (*utils.Battle, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	var result utils.Battle
+	err := collection.FindOne(*ctx, bson.M{"_id": battleId}).Decode(&result)
+	if err != nil {
+		return nil, wrapGetBattleError(err, battleId)
+	}
+
+	return &result, nil
+}
+
+// GetBattlesFromTrainer returns the battles and raids the trainer took part in, without their logs
+func GetBattlesFromTrainer(username string) ([]utils.Battle, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	filter := bson.M{"participants": username}
+	opts := options.Find().SetProjection(bson.M{"log": 0})
+
+	cursor, err := collection.Find(*ctx, filter, opts)
+	if err != nil {
+		return nil, wrapGetTrainerBattlesError(err, username)
+	}
+
+	defer databaseUtils.CloseCursor(cursor, ctx)
+
+	var results []utils.Battle
+	if err = cursor.All(*ctx, &results); err != nil {
+		return nil, wrapGetTrainerBattlesError(err, username)
+	}
+
+	return results, nil
+}
+
+func removeAll() error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+	_, err := collection.DeleteMany(*ctx, bson.M{})
+
+	return wrapRemoveAllBattlesError(err)
+}
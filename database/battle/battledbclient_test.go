@@ -0,0 +1,79 @@
+package battle
+
+import (
+	"os"
+	"testing"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var battleMockup = utils.Battle{
+	Trainer1:     "trainer1",
+	Trainer2:     "trainer2",
+	Winner:       "trainer1",
+	Participants: []string{"trainer1", "trainer2"},
+	StartedAt:    0,
+	FinishedAt:   1000,
+	Log: []utils.BattleLogEntry{
+		{Offset: 0, Player: 0, Action: "ATTACK", Events: []utils.BattleLogEvent{
+			{Type: "POKEMON_UPDATED", Player: 1, PokemonId: "p1", HP: 10, Damage: 5},
+		}},
+	},
+}
+
+func TestMain(m *testing.M) {
+	_ = removeAll()
+	res := m.Run()
+	_ = removeAll()
+
+	os.Exit(res)
+}
+
+func TestAddAndGetBattle(t *testing.T) {
+	toAdd := battleMockup
+	toAdd.Id = primitive.NewObjectID().Hex()
+
+	id, err := AddBattle(toAdd)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	battle, err := GetBattleById(id)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	assert.Equal(t, toAdd.Log, battle.Log)
+	assert.Equal(t, toAdd.Winner, battle.Winner)
+}
+
+func TestGetBattlesFromTrainer(t *testing.T) {
+	toAdd := battleMockup
+	toAdd.Id = primitive.NewObjectID().Hex()
+
+	_, err := AddBattle(toAdd)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	battles, err := GetBattlesFromTrainer(battleMockup.Trainer2)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	contains := false
+	for _, battle := range battles {
+		if battle.Id == toAdd.Id {
+			contains = true
+			assert.Empty(t, battle.Log)
+		}
+	}
+
+	assert.True(t, contains)
+}
@@ -0,0 +1,31 @@
+package battle
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorRemoveAllBattles = "error removing all battles"
+
+	errorAddBattleFormat         = "error adding battle %s"
+	errorGetBattleFormat         = "error getting battle %s"
+	errorGetTrainerBattlesFormat = "error getting trainer %s battles"
+)
+
+func wrapAddBattleError(err error, battleId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorAddBattleFormat, battleId))
+}
+
+func wrapGetBattleError(err error, battleId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorGetBattleFormat, battleId))
+}
+
+func wrapGetTrainerBattlesError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorGetTrainerBattlesFormat, username))
+}
+
+func wrapRemoveAllBattlesError(err error) error {
+	return errors.Wrap(err, errorRemoveAllBattles)
+}
@@ -35,9 +35,21 @@ func init() {
 		log.Fatal(err)
 	}
 	gymsLocationCollection := client.Database(databaseName).Collection(gymsConfigCollectionName)
+
+	// gym servers load their gyms by server name
+	_, err = gymsLocationCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"servername": 1},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	dbClient = databaseUtils.DBClient{Client: client, Ctx: &ctx, Collection: gymsLocationCollection}
 }
 
+// GetGymsForServer returns the gyms hosted by a gym server, looked up through the server name index.
+// Gyms are assigned to gym servers by name, not by location, so there is no geospatial query here;
+// location servers find gyms near trainers with the queries of the location database.
 func GetGymsForServer(serverName string) ([]utils.GymWithServer, error) {
 	var (
 		ctx        = dbClient.Ctx
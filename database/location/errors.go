@@ -10,9 +10,21 @@ const (
 	errorAddGym  = "error adding gym"
 	errorGetGyms = "error getting gyms"
 
+	errorMigrateGymsLocations = "error migrating gyms locations"
+
+	errorAddWildPokemons         = "error adding wild pokemons"
+	errorGetWildPokemons         = "error getting wild pokemons"
+	errorRemoveWildPokemonFormat = "error removing wild pokemon %s"
+
 	errorGetServerConfig       = "error getting server %s configs"
 	errorUpdateServerConfig    = "error updating server %s configs"
 	errorGetGlobalServerConfig = "error getting global server configs"
+	errorReplaceServerConfigs  = "error replacing server configs"
+)
+
+var (
+	ErrorWildPokemonNotFound  = errors.New("wild pokemon not found")
+	ErrorServerConfigsChanged = errors.New("server configs changed")
 )
 
 func wrapAddGymError(err error) error {
@@ -23,6 +35,22 @@ func wrapGetGymsError(err error) error {
 	return errors.Wrap(err, errorGetGyms)
 }
 
+func wrapMigrateGymsLocationsError(err error) error {
+	return errors.Wrap(err, errorMigrateGymsLocations)
+}
+
+func wrapAddWildPokemonsError(err error) error {
+	return errors.Wrap(err, errorAddWildPokemons)
+}
+
+func wrapGetWildPokemonsError(err error) error {
+	return errors.Wrap(err, errorGetWildPokemons)
+}
+
+func wrapRemoveWildPokemonError(err error, pokemonId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorRemoveWildPokemonFormat, pokemonId))
+}
+
 func wrapUpdateServerConfig(err error, serverName string) error {
 	return errors.Wrap(err, fmt.Sprintf(errorUpdateServerConfig, serverName))
 }
@@ -34,3 +62,7 @@ func wrapGetServerConfig(err error, serverName string) error {
 func wrapGetGlobalServerConfigs(err error) error {
 	return errors.Wrap(err, errorGetGlobalServerConfig)
 }
+
+func wrapReplaceServerConfigsError(err error) error {
+	return errors.Wrap(err, errorReplaceServerConfigs)
+}
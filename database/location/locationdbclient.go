@@ -6,6 +6,8 @@ import (
 
 	"github.com/NOVAPokemon/utils"
 	databaseUtils "github.com/NOVAPokemon/utils/database"
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/golang/geo/s2"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -18,6 +20,12 @@ const gymsLocationCollectionName = "GymsLocation"
 const globalConfigCollectionName = "RegionConfigs"
 const wildPokemonCollectionName = "WildPokemons"
 
+const (
+	gymLocationField         = "gym.location"
+	wildPokemonLocationField = "location"
+	wildPokemonIdField       = "pokemon._id"
+)
+
 var dbClient databaseUtils.DBClientMultipleCollections
 
 func init() {
@@ -41,6 +49,22 @@ func init() {
 	gymsLocationCollection := client.Database(databaseName).Collection(gymsLocationCollectionName)
 	wildPokemonsCollection := client.Database(databaseName).Collection(wildPokemonCollectionName)
 	globalConfigCollection := client.Database(databaseName).Collection(globalConfigCollectionName)
+
+	// the geospatial queries fail without these indexes
+	_, err = gymsLocationCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{gymLocationField: "2dsphere"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = wildPokemonsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{wildPokemonLocationField: "2dsphere"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	collections := map[string]*mongo.Collection{
 		usersLocationCollectionName: usersLocationCollection,
 		gymsLocationCollectionName:  gymsLocationCollection,
@@ -71,6 +95,9 @@ func UpdateIfAbsentAddGym(gymWithSrv utils.GymWithServer) error {
 	return nil
 }
 
+// GetGyms returns every gym.
+//
+// Deprecated: location servers should load the gyms in their cells with GetGymsForServerCells.
 func GetGyms() ([]utils.GymWithServer, error) {
 	ctx := dbClient.Ctx
 	collection := dbClient.Collections[gymsLocationCollectionName]
@@ -88,10 +115,225 @@ func GetGyms() ([]utils.GymWithServer, error) {
 	return gymsWithSrv, nil
 }
 
+// MigrateGymsLocations rewrites the locations of gyms stored before they were GeoJSON points,
+// which the geospatial queries can't find
+func MigrateGymsLocations() error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[gymsLocationCollectionName]
+
+	gyms, err := findGyms(bson.M{gymLocationField + ".type": bson.M{"$exists": false}})
+	if err != nil {
+		return wrapMigrateGymsLocationsError(err)
+	}
+
+	for _, gymWithSrv := range gyms {
+		filter := bson.M{"gym.name": gymWithSrv.Gym.Name}
+		if _, err = collection.ReplaceOne(*ctx, filter, gymWithSrv); err != nil {
+			return wrapMigrateGymsLocationsError(err)
+		}
+	}
+
+	log.Infof("Migrated the locations of %d gyms", len(gyms))
+
+	return nil
+}
+
+// GetGymsForServerCells returns the gyms inside the cells assigned to a location server
+func GetGymsForServerCells(serverCells utils.LocationServerCells) ([]utils.GymWithServer, error) {
+	cells := make(s2.CellUnion, len(serverCells.CellIdsStrings))
+	for i, token := range serverCells.CellIdsStrings {
+		cells[i] = s2.CellIDFromToken(token)
+	}
+
+	return GetGymsWithinCells(cells)
+}
+
+// GetGymsWithinRadius returns the gyms at most radius meters away from the location
+func GetGymsWithinRadius(location s2.LatLng, radius float64) ([]utils.GymWithServer, error) {
+	gyms, err := findGyms(withinRadiusFilter(gymLocationField, location, radius))
+	if err != nil {
+		return nil, wrapGetGymsError(err)
+	}
+
+	return gyms, nil
+}
+
+// GetGymsWithinCells returns the gyms inside the cells
+func GetGymsWithinCells(cells s2.CellUnion) ([]utils.GymWithServer, error) {
+	if len(cells) == 0 {
+		return nil, nil
+	}
+
+	gyms, err := findGyms(withinCellsFilter(gymLocationField, cells))
+	if err != nil {
+		return nil, wrapGetGymsError(err)
+	}
+
+	return gyms, nil
+}
+
+// GetNearestGyms returns up to n gyms, from the nearest to the location to the farthest
+func GetNearestGyms(location s2.LatLng, n int) ([]utils.GymWithServer, error) {
+	gyms, err := findGyms(nearestFilter(gymLocationField, location), options.Find().SetLimit(int64(n)))
+	if err != nil {
+		return nil, wrapGetGymsError(err)
+	}
+
+	return gyms, nil
+}
+
+func findGyms(filter bson.M, opts ...*options.FindOptions) ([]utils.GymWithServer, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[gymsLocationCollectionName]
+
+	cur, err := collection.Find(*ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var gymsWithSrv []utils.GymWithServer
+	if err = cur.All(*ctx, &gymsWithSrv); err != nil {
+		return nil, err
+	}
+
+	return gymsWithSrv, nil
+}
+
+func AddWildPokemons(wildPokemons []utils.WildPokemonWithServer) error {
+	if len(wildPokemons) == 0 {
+		return nil
+	}
+
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[wildPokemonCollectionName]
+
+	documents := make([]interface{}, len(wildPokemons))
+	for i := range wildPokemons {
+		documents[i] = wildPokemons[i]
+	}
+
+	_, err := collection.InsertMany(*ctx, documents)
+	if err != nil {
+		return wrapAddWildPokemonsError(err)
+	}
+
+	return nil
+}
+
+func RemoveWildPokemon(pokemonId string) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[wildPokemonCollectionName]
+
+	res, err := collection.DeleteOne(*ctx, bson.M{wildPokemonIdField: pokemonId})
+	if err != nil {
+		return wrapRemoveWildPokemonError(err, pokemonId)
+	}
+
+	if res.DeletedCount == 0 {
+		return wrapRemoveWildPokemonError(ErrorWildPokemonNotFound, pokemonId)
+	}
+
+	return nil
+}
+
+// GetWildPokemonsWithinRadius returns the wild pokemons at most radius meters away from the location
+func GetWildPokemonsWithinRadius(location s2.LatLng, radius float64) ([]utils.WildPokemonWithServer, error) {
+	wildPokemons, err := findWildPokemons(withinRadiusFilter(wildPokemonLocationField, location, radius))
+	if err != nil {
+		return nil, wrapGetWildPokemonsError(err)
+	}
+
+	return wildPokemons, nil
+}
+
+// GetWildPokemonsWithinCells returns the wild pokemons inside the cells
+func GetWildPokemonsWithinCells(cells s2.CellUnion) ([]utils.WildPokemonWithServer, error) {
+	if len(cells) == 0 {
+		return nil, nil
+	}
+
+	wildPokemons, err := findWildPokemons(withinCellsFilter(wildPokemonLocationField, cells))
+	if err != nil {
+		return nil, wrapGetWildPokemonsError(err)
+	}
+
+	return wildPokemons, nil
+}
+
+// GetNearestWildPokemons returns up to n wild pokemons, from the nearest to the location to the farthest
+func GetNearestWildPokemons(location s2.LatLng, n int) ([]utils.WildPokemonWithServer, error) {
+	wildPokemons, err := findWildPokemons(nearestFilter(wildPokemonLocationField, location),
+		options.Find().SetLimit(int64(n)))
+	if err != nil {
+		return nil, wrapGetWildPokemonsError(err)
+	}
+
+	return wildPokemons, nil
+}
+
+func findWildPokemons(filter bson.M, opts ...*options.FindOptions) ([]utils.WildPokemonWithServer, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[wildPokemonCollectionName]
+
+	cur, err := collection.Find(*ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var wildPokemons []utils.WildPokemonWithServer
+	if err = cur.All(*ctx, &wildPokemons); err != nil {
+		return nil, err
+	}
+
+	return wildPokemons, nil
+}
+
+func withinRadiusFilter(field string, location s2.LatLng, radius float64) bson.M {
+	return bson.M{field: bson.M{"$geoWithin": bson.M{
+		"$centerSphere": bson.A{
+			utils.NewGeoJSONPoint(location).Coordinates,
+			gps.MetersToAngle(radius).Radians(),
+		},
+	}}}
+}
+
+// withinCellsFilter matches locations inside any of the cells, each queried as the polygon of its
+// vertices, since cell edges are geodesics like the edges of MongoDB polygons
+func withinCellsFilter(field string, cells s2.CellUnion) bson.M {
+	normalized := append(s2.CellUnion(nil), cells...)
+	normalized.Normalize()
+
+	polygons := make(bson.A, len(normalized))
+	for i, cellId := range normalized {
+		cell := s2.CellFromCellID(cellId)
+
+		ring := make(bson.A, 0, 5)
+		for v := 0; v < 4; v++ {
+			ring = append(ring, utils.NewGeoJSONPoint(s2.LatLngFromPoint(cell.Vertex(v))).Coordinates)
+		}
+		ring = append(ring, ring[0])
+
+		polygons[i] = bson.A{ring}
+	}
+
+	return bson.M{field: bson.M{"$geoWithin": bson.M{
+		"$geometry": bson.M{
+			"type":        "MultiPolygon",
+			"coordinates": polygons,
+		},
+	}}}
+}
+
+func nearestFilter(field string, location s2.LatLng) bson.M {
+	return bson.M{field: bson.M{"$nearSphere": bson.M{
+		"$geometry": utils.NewGeoJSONPoint(location),
+	}}}
+}
+
 func UpdateServerConfig(serverName string, config utils.LocationServerCells) error {
 	var ctx = dbClient.Ctx
 	var collection = dbClient.Collections[globalConfigCollectionName]
-	var filter = bson.D{{"servername:", serverName}}
+	var filter = bson.D{{"servername", serverName}}
 	upsert := true
 	updateOptions := options.ReplaceOptions{
 		Upsert: &upsert,
@@ -124,23 +366,110 @@ func GetServerConfig(serverName string) (*utils.LocationServerCells, error) {
 }
 
 func GetAllServerConfigs() (map[string]utils.LocationServerCells, error) {
-	var ctx = dbClient.Ctx
-	var collection = dbClient.Collections[globalConfigCollectionName]
-	var filter = bson.M{}
+	configs, err := getAllServerConfigs(*dbClient.Ctx)
+	if err != nil {
+		return nil, wrapGetGlobalServerConfigs(err)
+	}
 
-	cursor, err := collection.Find(*ctx, filter)
+	return configs, nil
+}
 
+// ReplaceServerConfigs swaps every server's cells for the next ones in a single transaction,
+// which needs MongoDB to run as a replica set. It fails with ErrorServerConfigsChanged, changing
+// nothing, when the stored configs no longer match the previous ones the next were computed from.
+// Servers missing from next are removed.
+//
+// Only the stored configs change: each location server keeps serving its old cells until it is
+// sent a request to api.ForceLoadConfigPath, so that must follow every successful replace. Clients
+// learn the new cells from the servers they are connected to, while the servers they cached for
+// other cells expire after five minutes.
+func ReplaceServerConfigs(previous, next map[string]utils.LocationServerCells) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collections[globalConfigCollectionName]
+
+	session, err := dbClient.Client.StartSession()
 	if err != nil {
-		return nil, wrapGetGlobalServerConfigs(err)
+		return wrapReplaceServerConfigsError(err)
+	}
+	defer session.EndSession(*ctx)
+
+	_, err = session.WithTransaction(*ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		current, err := getAllServerConfigs(sessionCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !sameServerConfigs(current, previous) {
+			return nil, ErrorServerConfigsChanged
+		}
+
+		upsert := true
+		servers := make(bson.A, 0, len(next))
+		for serverName, config := range next {
+			config.ServerName = serverName
+			filter := bson.M{"servername": serverName}
+			replaceOptions := &options.ReplaceOptions{Upsert: &upsert}
+			if _, err = collection.ReplaceOne(sessionCtx, filter, config, replaceOptions); err != nil {
+				return nil, err
+			}
+
+			servers = append(servers, serverName)
+		}
+
+		_, err = collection.DeleteMany(sessionCtx, bson.M{"servername": bson.M{"$nin": servers}})
+		return nil, err
+	})
+	if err != nil {
+		return wrapReplaceServerConfigsError(err)
 	}
 
+	return nil
+}
+
+func getAllServerConfigs(ctx context.Context) (map[string]utils.LocationServerCells, error) {
+	var collection = dbClient.Collections[globalConfigCollectionName]
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer databaseUtils.CloseCursor(cursor, &ctx)
+
 	var out = make(map[string]utils.LocationServerCells, 0)
-	for cursor.Next(*ctx) {
+	for cursor.Next(ctx) {
 		var serverCells utils.LocationServerCells
 		if err = cursor.Decode(&serverCells); err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		out[serverCells.ServerName] = serverCells
 	}
-	return out, nil
+
+	return out, cursor.Err()
+}
+
+// sameServerConfigs tells if both configs assign the same cells to the same servers, in any order
+func sameServerConfigs(a, b map[string]utils.LocationServerCells) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for serverName, config := range a {
+		other, ok := b[serverName]
+		if !ok || len(config.CellIdsStrings) != len(other.CellIdsStrings) {
+			return false
+		}
+
+		cells := make(map[string]struct{}, len(config.CellIdsStrings))
+		for _, cell := range config.CellIdsStrings {
+			cells[cell] = struct{}{}
+		}
+
+		for _, cell := range other.CellIdsStrings {
+			if _, ok = cells[cell]; !ok {
+				return false
+			}
+		}
+	}
+
+	return true
 }
@@ -1 +1,157 @@
 package location
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const testServer = "geo-test-server"
+
+var geoTestCenter = s2.LatLngFromDegrees(38.7223, -9.1393)
+
+func atDistance(meters float64) s2.LatLng {
+	return gps.Destination(geoTestCenter, 90*s1.Degree, meters)
+}
+
+func addGeoTestData(t *testing.T) func() {
+	for i, distance := range []float64{100, 1000, 10000} {
+		err := UpdateIfAbsentAddGym(utils.GymWithServer{
+			Gym:        utils.Gym{Name: testServer + string(rune('a'+i)), Location: atDistance(distance)},
+			ServerName: testServer,
+		})
+		assert.NoError(t, err)
+	}
+
+	err := AddWildPokemons([]utils.WildPokemonWithServer{
+		{Pokemon: pokemons.Pokemon{Id: testServer + "-near", Species: "pidgey"}, Location: atDistance(50),
+			Server: testServer},
+		{Pokemon: pokemons.Pokemon{Id: testServer + "-far", Species: "rattata"}, Location: atDistance(5000),
+			Server: testServer},
+	})
+	assert.NoError(t, err)
+
+	return func() {
+		ctx := dbClient.Ctx
+		_, _ = dbClient.Collections[gymsLocationCollectionName].DeleteMany(*ctx,
+			bson.M{"servername": testServer})
+		_, _ = dbClient.Collections[wildPokemonCollectionName].DeleteMany(*ctx, bson.M{"server": testServer})
+	}
+}
+
+func TestGetGymsGeoQueries(t *testing.T) {
+	defer addGeoTestData(t)()
+
+	gyms, err := GetGymsWithinRadius(geoTestCenter, 2000)
+	assert.NoError(t, err)
+	assert.Len(t, gyms, 2)
+
+	gyms, err = GetNearestGyms(geoTestCenter, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, gyms, 1) {
+		assert.Equal(t, testServer+"a", gyms[0].Gym.Name)
+		assert.InDelta(t, 100, gps.Distance(geoTestCenter, gyms[0].Gym.Location), 1e-3)
+	}
+
+	cells := s2.CellUnion{s2.CellIDFromLatLng(atDistance(1000)).Parent(15)}
+	gyms, err = GetGymsWithinCells(cells)
+	assert.NoError(t, err)
+	if assert.Len(t, gyms, 1) {
+		assert.Equal(t, testServer+"b", gyms[0].Gym.Name)
+	}
+
+	gyms, err = GetGymsForServerCells(utils.LocationServerCells{CellIdsStrings: []string{cells[0].ToToken()}})
+	assert.NoError(t, err)
+	assert.Len(t, gyms, 1)
+}
+
+func TestMigrateGymsLocations(t *testing.T) {
+	defer addGeoTestData(t)()
+
+	legacy := atDistance(500)
+	_, err := dbClient.Collections[gymsLocationCollectionName].InsertOne(*dbClient.Ctx, bson.M{
+		"gym": bson.M{
+			"name":     testServer + "legacy",
+			"location": bson.M{"lat": legacy.Lat.Radians(), "lng": legacy.Lng.Radians()},
+		},
+		"servername": testServer,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, MigrateGymsLocations())
+
+	gyms, err := GetGymsWithinRadius(geoTestCenter, 600)
+	assert.NoError(t, err)
+	assert.Len(t, gyms, 2)
+}
+
+func TestGetWildPokemonsGeoQueries(t *testing.T) {
+	defer addGeoTestData(t)()
+
+	wildPokemons, err := GetWildPokemonsWithinRadius(geoTestCenter, 1000)
+	assert.NoError(t, err)
+	if assert.Len(t, wildPokemons, 1) {
+		assert.Equal(t, testServer+"-near", wildPokemons[0].Pokemon.Id)
+	}
+
+	wildPokemons, err = GetNearestWildPokemons(geoTestCenter, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, wildPokemons, 2) {
+		assert.Equal(t, testServer+"-near", wildPokemons[0].Pokemon.Id)
+		assert.Equal(t, testServer+"-far", wildPokemons[1].Pokemon.Id)
+	}
+
+	wildPokemons, err = GetWildPokemonsWithinCells(s2.CellUnion{s2.CellIDFromLatLng(atDistance(5000)).Parent(14)})
+	assert.NoError(t, err)
+	assert.Len(t, wildPokemons, 1)
+
+	assert.NoError(t, RemoveWildPokemon(testServer+"-near"))
+	assert.Error(t, RemoveWildPokemon(testServer+"-near"))
+}
+
+func TestWithinCellsFilterClosesRings(t *testing.T) {
+	cells := s2.CellUnion{s2.CellIDFromLatLng(geoTestCenter).Parent(10)}
+
+	filter := withinCellsFilter(wildPokemonLocationField, cells)
+	geometry := filter[wildPokemonLocationField].(bson.M)["$geoWithin"].(bson.M)["$geometry"].(bson.M)
+	polygons := geometry["coordinates"].(bson.A)
+
+	assert.Len(t, polygons, 1)
+	ring := polygons[0].(bson.A)[0].(bson.A)
+	assert.Len(t, ring, 5)
+	assert.Equal(t, ring[0], ring[4])
+}
+
+func TestSameServerConfigs(t *testing.T) {
+	configs := map[string]utils.LocationServerCells{
+		"location-0": {ServerName: "location-0", CellIdsStrings: []string{"a", "b"}},
+		"location-1": {ServerName: "location-1", CellIdsStrings: []string{"c"}},
+	}
+
+	reordered := map[string]utils.LocationServerCells{
+		"location-0": {ServerName: "location-0", CellIdsStrings: []string{"b", "a"}},
+		"location-1": {ServerName: "location-1", CellIdsStrings: []string{"c"}},
+	}
+	assert.True(t, sameServerConfigs(configs, reordered))
+
+	moved := map[string]utils.LocationServerCells{
+		"location-0": {ServerName: "location-0", CellIdsStrings: []string{"a", "c"}},
+		"location-1": {ServerName: "location-1", CellIdsStrings: []string{"b"}},
+	}
+	assert.False(t, sameServerConfigs(configs, moved))
+
+	renamed := map[string]utils.LocationServerCells{
+		"location-0": configs["location-0"],
+		"location-2": configs["location-1"],
+	}
+	assert.False(t, sameServerConfigs(configs, renamed))
+
+	assert.False(t, sameServerConfigs(configs, map[string]utils.LocationServerCells{}))
+	assert.True(t, sameServerConfigs(nil, map[string]utils.LocationServerCells{}))
+}
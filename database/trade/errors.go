@@ -0,0 +1,31 @@
+package trade
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorRemoveAllTrades = "error removing all trades"
+
+	errorAddTradeFormat         = "error adding trade %s"
+	errorGetTradeFormat         = "error getting trade %s"
+	errorGetTrainerTradesFormat = "error getting trainer %s trades"
+)
+
+func wrapAddTradeError(err error, tradeId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorAddTradeFormat, tradeId))
+}
+
+func wrapGetTradeError(err error, tradeId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorGetTradeFormat, tradeId))
+}
+
+func wrapGetTrainerTradesError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorGetTrainerTradesFormat, username))
+}
+
+func wrapRemoveAllTradesError(err error) error {
+	return errors.Wrap(err, errorRemoveAllTrades)
+}
@@ -0,0 +1,110 @@
+package trade
+
+import (
+	"context"
+	"os"
+
+	"github.com/NOVAPokemon/utils"
+	databaseUtils "github.com/NOVAPokemon/utils/database"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const databaseName = "NOVAPokemonDB"
+const collectionName = "Trades"
+
+var dbClient databaseUtils.DBClient
+
+func init() {
+	url, exists := os.LookupEnv(utils.MongoEnvVar)
+	if !exists {
+		url = databaseUtils.DefaultMongoDBUrl
+	}
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(url))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	err = client.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	collection := client.Database(databaseName).Collection(collectionName)
+
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: "participants", Value: 1}, {Key: "finishedat", Value: -1}},
+	}
+
+	_, _ = collection.Indexes().CreateOne(ctx, index)
+	dbClient = databaseUtils.DBClient{Client: client, Ctx: &ctx, Collection: collection}
+}
+
+func AddTrade(trade utils.TradeRecord) (string, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	_, err := collection.InsertOne(*ctx, trade)
+	if err != nil {
+		return "", wrapAddTradeError(err, trade.Id)
+	}
+
+	log.Infof("Added trade %s (%s)", trade.Id, trade.Outcome)
+	return trade.Id, nil
+}
+
+func GetTradeById(tradeId string) (*utils.TradeRecord, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	var result utils.TradeRecord
+	err := collection.FindOne(*ctx, bson.M{"_id": tradeId}).Decode(&result)
+	if err != nil {
+		return nil, wrapGetTradeError(err, tradeId)
+	}
+
+	return &result, nil
+}
+
+// GetTradesFromTrainer returns the trades the trainer took part in, most recent first
+func GetTradesFromTrainer(username string) ([]utils.TradeRecord, error) {
+	return getTrades(bson.M{"participants": username}, username)
+}
+
+// GetTradesFromTrainerWithOutcome returns the trainer's trades that ended with the given outcome
+func GetTradesFromTrainerWithOutcome(username, outcome string) ([]utils.TradeRecord, error) {
+	return getTrades(bson.M{"participants": username, "outcome": outcome}, username)
+}
+
+func getTrades(filter bson.M, username string) ([]utils.TradeRecord, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	opts := options.Find().SetSort(bson.M{"finishedat": -1})
+
+	cursor, err := collection.Find(*ctx, filter, opts)
+	if err != nil {
+		return nil, wrapGetTrainerTradesError(err, username)
+	}
+
+	defer databaseUtils.CloseCursor(cursor, ctx)
+
+	results := make([]utils.TradeRecord, 0)
+	if err = cursor.All(*ctx, &results); err != nil {
+		return nil, wrapGetTrainerTradesError(err, username)
+	}
+
+	return results, nil
+}
+
+func removeAll() error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+	_, err := collection.DeleteMany(*ctx, bson.M{})
+
+	return wrapRemoveAllTradesError(err)
+}
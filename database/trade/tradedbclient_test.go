@@ -0,0 +1,80 @@
+package trade
+
+import (
+	"os"
+	"testing"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var tradeMockup = utils.TradeRecord{
+	Participants: []string{"trainer1", "trainer2"},
+	Offers: [2]utils.TradeOffer{
+		{Username: "trainer1", ItemIds: []string{"item1"}, Coins: 10},
+		{Username: "trainer2", PokemonIds: []string{"pokemon1"}},
+	},
+	Outcome:    utils.TradeCompleted,
+	StartedAt:  0,
+	FinishedAt: 1000,
+}
+
+func TestMain(m *testing.M) {
+	_ = removeAll()
+	res := m.Run()
+	_ = removeAll()
+
+	os.Exit(res)
+}
+
+func TestAddAndGetTrade(t *testing.T) {
+	toAdd := tradeMockup
+	toAdd.Id = primitive.NewObjectID().Hex()
+
+	id, err := AddTrade(toAdd)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	trade, err := GetTradeById(id)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	assert.Equal(t, toAdd, *trade)
+}
+
+func TestGetTradesFromTrainer(t *testing.T) {
+	completed := tradeMockup
+	completed.Id = primitive.NewObjectID().Hex()
+
+	rejected := tradeMockup
+	rejected.Id = primitive.NewObjectID().Hex()
+	rejected.Outcome = utils.TradeRejected
+	rejected.FinishedAt = 2000
+
+	_, _ = AddTrade(completed)
+	_, _ = AddTrade(rejected)
+
+	trades, err := GetTradesFromTrainer("trainer2")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	assert.GreaterOrEqual(t, len(trades), 2)
+	assert.Equal(t, rejected.Id, trades[0].Id)
+
+	trades, err = GetTradesFromTrainerWithOutcome("trainer1", utils.TradeRejected)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	for _, trade := range trades {
+		assert.Equal(t, utils.TradeRejected, trade.Outcome)
+	}
+}
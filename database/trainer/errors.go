@@ -24,12 +24,33 @@ const (
 	errorAddPokemonToTrainerFormat      = "error add pokemon to trainer %s"
 	errorUpdateTrainerPokemonsFormat    = "error update trainer %s pokemons"
 	errorRemovePokemonFromTrainerFormat = "error removing pokemon from trainer %s"
+	errorEvolveTrainerPokemonFormat     = "error evolving pokemon of trainer %s"
+	errorAddPokemonExperienceFormat     = "error adding experience to pokemon of trainer %s"
+
+	errorExecuteTradeFormat = "error executing trade %s"
+	errorEscrowTradeFormat  = "error moving trainer %s assets to escrow"
+	errorCommitTradeFormat  = "error committing trainer %s escrow"
+	errorDeliverTradeFormat = "error delivering trainer %s escrow"
+	errorReleaseTradeFormat = "error releasing trainer %s escrow"
+	errorRecoverTrades      = "error recovering trades"
+	errorMigrateItems       = "error migrating trainers items"
 )
 
 var (
 	ErrorTrainerNotFound = errors.New("trainer not found")
 	ErrorInvalidLevel    = errors.New("invalid level")
 	ErrorInvalidCoins    = errors.New("invalid coin ammount")
+
+	ErrorItemNotFound       = errors.New("item not found")
+	ErrorPokemonNotFound    = errors.New("pokemon not found")
+	ErrorTradeAssetsChanged = errors.New("trainer no longer has the offered assets")
+	ErrorEscrowNotFound     = errors.New("escrow not found")
+	ErrorEvolutionConflict  = errors.New("pokemon or evolution item changed while evolving")
+	ErrorConcurrentUpdate   = errors.New("trainer kept changing while updating")
+
+	errorStatsChanged   = errors.New("stats changed while updating")
+	errorPokemonChanged = errors.New("pokemon changed while updating")
+	errorItemsChanged   = errors.New("items changed while updating")
 )
 
 func wrapAddTrainerError(err error, username string) error {
@@ -83,3 +104,39 @@ func wrapUpdateTrainerPokemonError(err error, username string) error {
 func wrapRemovePokemonFromTrainerError(err error, username string) error {
 	return errors.Wrap(err, fmt.Sprintf(errorRemovePokemonFromTrainerFormat, username))
 }
+
+func wrapEvolveTrainerPokemonError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorEvolveTrainerPokemonFormat, username))
+}
+
+func wrapAddPokemonExperienceError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorAddPokemonExperienceFormat, username))
+}
+
+func wrapExecuteTradeError(err error, tradeId string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorExecuteTradeFormat, tradeId))
+}
+
+func wrapEscrowTradeError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorEscrowTradeFormat, username))
+}
+
+func wrapCommitTradeError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorCommitTradeFormat, username))
+}
+
+func wrapDeliverTradeError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorDeliverTradeFormat, username))
+}
+
+func wrapReleaseTradeError(err error, username string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorReleaseTradeFormat, username))
+}
+
+func wrapRecoverTradesError(err error) error {
+	return errors.Wrap(err, errorRecoverTrades)
+}
+
+func wrapMigrateItemsError(err error) error {
+	return errors.Wrap(err, errorMigrateItems)
+}
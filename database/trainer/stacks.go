@@ -0,0 +1,211 @@
+package trainer
+
+import (
+	"github.com/NOVAPokemon/utils/items"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// addStacksUpdate builds the filter and the change that add the stacks to a trainer. The filter
+// requires every stack to have room for the new items when the update is applied.
+func addStacksUpdate(toAdd items.Inventory) (bson.M, bson.M, error) {
+	stackConditions := make([]bson.M, 0, len(toAdd))
+	for _, name := range toAdd.Names() {
+		maxQuantity := items.MaxStackFor(name) - toAdd[name].Quantity
+		if maxQuantity < 0 {
+			return nil, nil, items.ErrorStackFull
+		}
+
+		quantityField := "items." + name + ".quantity"
+		stackConditions = append(stackConditions, bson.M{"$or": []bson.M{
+			{quantityField: bson.M{"$exists": false}},
+			{quantityField: bson.M{"$lte": maxQuantity}},
+		}})
+	}
+
+	filter := bson.M{}
+	if len(stackConditions) > 0 {
+		filter["$and"] = stackConditions
+	}
+
+	return filter, stacksChange(toAdd, 0), nil
+}
+
+// removeStacksUpdate builds the filter and the change that remove the counted items from the
+// inventory read from the trainer, and returns the inventory left. The filter requires the stacks
+// to be as they were read, so the ones that run out are unset in the same update.
+func removeStacksUpdate(inventory items.Inventory, counts map[string]int) (bson.M, bson.M, items.Inventory,
+	error) {
+	remaining := inventory.Clone()
+	filter := bson.M{}
+	decrements := bson.M{}
+	unset := bson.M{}
+
+	for name, count := range counts {
+		if err := remaining.Remove(name, count); err != nil {
+			return nil, nil, nil, err
+		}
+
+		quantityField := "items." + name + ".quantity"
+		filter[quantityField] = inventory[name].Quantity
+		if _, ok := remaining[name]; ok {
+			decrements[quantityField] = -count
+		} else {
+			unset["items."+name] = nil
+		}
+	}
+
+	change := bson.M{}
+	if len(decrements) > 0 {
+		change["$inc"] = decrements
+	}
+
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+
+	return filter, change, remaining, nil
+}
+
+// unchangedStacksFilter requires the trainer's stacks with the names of the given ones to be as
+// they were read
+func unchangedStacksFilter(inventory items.Inventory, stacks items.Inventory) bson.M {
+	filter := bson.M{}
+	for name := range stacks {
+		quantityField := "items." + name + ".quantity"
+		if stack, ok := inventory[name]; ok {
+			filter[quantityField] = stack.Quantity
+		} else {
+			filter[quantityField] = bson.M{"$exists": false}
+		}
+	}
+
+	return filter
+}
+
+// fittingStacks splits the stacks to add into the part that fits in the trainer's stacks and the
+// part that would go over their caps
+func fittingStacks(inventory items.Inventory, toAdd items.Inventory) (items.Inventory, items.Inventory) {
+	fit := items.Inventory{}
+	overflow := items.Inventory{}
+
+	for name, stack := range toAdd {
+		room := items.MaxStackFor(name) - inventory.Quantity(name)
+		if room < 0 {
+			room = 0
+		}
+
+		if stack.Quantity <= room {
+			fit[name] = stack
+			continue
+		}
+
+		if room > 0 {
+			fitting := stack
+			fitting.Quantity = room
+			fit[name] = fitting
+		}
+
+		stack.Quantity -= room
+		overflow[name] = stack
+	}
+
+	return fit, overflow
+}
+
+// mergeChanges adds the fields of each update operator of other to change
+func mergeChanges(change, other bson.M) {
+	for operator, fields := range other {
+		existing, ok := change[operator].(bson.M)
+		if !ok {
+			change[operator] = fields
+			continue
+		}
+
+		for field, value := range fields.(bson.M) {
+			existing[field] = value
+		}
+	}
+}
+
+// stacksChange increments the given stacks and the trainer's coins
+func stacksChange(inventory items.Inventory, coins int) bson.M {
+	increments := bson.M{}
+	set := bson.M{}
+	for name, stack := range inventory {
+		increments["items."+name+".quantity"] = stack.Quantity
+		set["items."+name+".name"] = stack.Name
+		set["items."+name+".effect"] = stack.Effect
+	}
+
+	if coins != 0 {
+		increments["stats.coins"] = coins
+	}
+
+	change := bson.M{}
+	if len(increments) > 0 {
+		change["$inc"] = increments
+	}
+
+	if len(set) > 0 {
+		change["$set"] = set
+	}
+
+	return change
+}
+
+// rewardStacks groups the reward items that fit in the trainer's stacks
+func rewardStacks(inventory items.Inventory, itemNames []string) items.Inventory {
+	rewards := items.Inventory{}
+	for _, name := range itemNames {
+		item, err := items.DefaultCatalogue.NewItem(name)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		if inventory.Quantity(name)+rewards.Quantity(name) >= items.MaxStackFor(name) {
+			continue
+		}
+
+		rewards.AddUncapped(item, 1)
+	}
+
+	return rewards
+}
+
+// migrateInventory regroups the entries of items stored one per id, which are the ones whose
+// key is not their name
+func migrateInventory(inventory items.Inventory) (items.Inventory, bool) {
+	legacyItems := map[string]items.Item{}
+	migrated := items.Inventory{}
+
+	for key, stack := range inventory {
+		if key == stack.Name && stack.Quantity > 0 {
+			migrated[key] = stack
+		} else if key != stack.Name {
+			legacyItems[key] = items.Item{Id: key, Name: stack.Name, Effect: stack.Effect}
+		}
+	}
+
+	if len(legacyItems) == 0 {
+		return inventory, false
+	}
+
+	for name, stack := range items.InventoryFromItems(legacyItems) {
+		existing := migrated[name]
+		stack.Quantity += existing.Quantity
+		migrated[name] = stack
+	}
+
+	return migrated, true
+}
+
+func countNames(names []string) map[string]int {
+	counts := make(map[string]int, len(names))
+	for _, name := range names {
+		counts[name]++
+	}
+
+	return counts
+}
@@ -0,0 +1,121 @@
+package trainer
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAddStacksUpdate(t *testing.T) {
+	toAdd := items.Inventory{}
+	toAdd.AddUncapped(items.HealItem, 2)
+
+	filter, change, err := addStacksUpdate(toAdd)
+	assert.NoError(t, err)
+
+	quantityField := "items." + items.HealName + ".quantity"
+	assert.Equal(t, []bson.M{{"$or": []bson.M{
+		{quantityField: bson.M{"$exists": false}},
+		{quantityField: bson.M{"$lte": items.MaxStackFor(items.HealName) - 2}},
+	}}}, filter["$and"])
+	assert.Equal(t, bson.M{quantityField: 2}, change["$inc"])
+	assert.Equal(t, items.HealName, change["$set"].(bson.M)["items."+items.HealName+".name"])
+
+	toAdd.AddUncapped(items.HealItem, items.MaxStackFor(items.HealName))
+	_, _, err = addStacksUpdate(toAdd)
+	assert.Equal(t, items.ErrorStackFull, err)
+}
+
+func TestRemoveStacksUpdate(t *testing.T) {
+	inventory := items.Inventory{
+		items.HealName:   {Name: items.HealName, Effect: items.HealEffect, Quantity: 3},
+		items.ReviveName: {Name: items.ReviveName, Effect: items.ReviveEffect, Quantity: 1},
+	}
+
+	filter, change, remaining, err := removeStacksUpdate(inventory,
+		map[string]int{items.HealName: 2, items.ReviveName: 1})
+	assert.NoError(t, err)
+
+	// the stacks must be as read, and the ones that run out are unset in the same update
+	assert.Equal(t, bson.M{"items." + items.HealName + ".quantity": 3, "items." + items.ReviveName + ".quantity": 1},
+		filter)
+	assert.Equal(t, bson.M{"items." + items.HealName + ".quantity": -2}, change["$inc"])
+	assert.Equal(t, bson.M{"items." + items.ReviveName: nil}, change["$unset"])
+	assert.Equal(t, items.Inventory{items.HealName: {Name: items.HealName, Effect: items.HealEffect, Quantity: 1}},
+		remaining)
+	assert.Equal(t, 3, inventory.Quantity(items.HealName))
+
+	_, _, _, err = removeStacksUpdate(inventory, map[string]int{items.ReviveName: 2})
+	assert.Equal(t, items.ErrorNotEnoughItems, err)
+}
+
+func TestMergeChanges(t *testing.T) {
+	change := bson.M{"$inc": bson.M{"stats.coins": -10}}
+	mergeChanges(change, bson.M{"$inc": bson.M{"items.heal.quantity": -1}, "$unset": bson.M{"items.revive": nil}})
+
+	assert.Equal(t, bson.M{
+		"$inc":   bson.M{"stats.coins": -10, "items.heal.quantity": -1},
+		"$unset": bson.M{"items.revive": nil},
+	}, change)
+}
+
+func TestRewardStacks(t *testing.T) {
+	full := items.Inventory{}
+	full.AddUncapped(items.ReviveItem, items.MaxStackFor(items.ReviveName))
+
+	rewards := rewardStacks(full, []string{items.HealName, items.HealName, items.ReviveName, "unknown"})
+	assert.Equal(t, 2, rewards.Quantity(items.HealName))
+	assert.Equal(t, 0, rewards.Quantity(items.ReviveName))
+	assert.Len(t, rewards, 1)
+}
+
+func TestUnchangedStacksFilter(t *testing.T) {
+	inventory := items.Inventory{items.HealName: {Name: items.HealName, Effect: items.HealEffect, Quantity: 3}}
+	toAdd := items.Inventory{}
+	toAdd.AddUncapped(items.HealItem, 1)
+	toAdd.AddUncapped(items.ReviveItem, 1)
+
+	assert.Equal(t, bson.M{
+		"items." + items.HealName + ".quantity":   3,
+		"items." + items.ReviveName + ".quantity": bson.M{"$exists": false},
+	}, unchangedStacksFilter(inventory, toAdd))
+}
+
+func TestFittingStacks(t *testing.T) {
+	inventory := items.Inventory{}
+	inventory.AddUncapped(items.HealItem, items.MaxStackFor(items.HealName)-2)
+	inventory.AddUncapped(items.ReviveItem, items.MaxStackFor(items.ReviveName))
+
+	toAdd := items.Inventory{}
+	toAdd.AddUncapped(items.HealItem, 5)
+	toAdd.AddUncapped(items.ReviveItem, 1)
+	toAdd.AddUncapped(items.PokeBallItem, 1)
+
+	fit, overflow := fittingStacks(inventory, toAdd)
+	assert.Equal(t, 2, fit.Quantity(items.HealName))
+	assert.Equal(t, 1, fit.Quantity(items.PokeBallName))
+	assert.Len(t, fit, 2)
+	assert.Equal(t, 3, overflow.Quantity(items.HealName))
+	assert.Equal(t, 1, overflow.Quantity(items.ReviveName))
+	assert.Len(t, overflow, 2)
+}
+
+func TestMigrateInventory(t *testing.T) {
+	legacy := items.Inventory{
+		primitive.NewObjectID().Hex(): {Name: items.HealName, Effect: items.HealEffect},
+		primitive.NewObjectID().Hex(): {Name: items.HealName, Effect: items.HealEffect},
+		items.ReviveName:              {Name: items.ReviveName, Effect: items.ReviveEffect, Quantity: 1},
+	}
+
+	migrated, ok := migrateInventory(legacy)
+	assert.True(t, ok)
+	assert.Len(t, migrated, 2)
+	assert.Equal(t, 2, migrated.Quantity(items.HealName))
+	assert.Equal(t, 1, migrated.Quantity(items.ReviveName))
+
+	_, ok = migrateInventory(migrated)
+	assert.False(t, ok)
+}
@@ -3,12 +3,14 @@ package trainer
 import (
 	"context"
 	"os"
+	"sync"
 
 	"github.com/NOVAPokemon/utils"
 	databaseUtils "github.com/NOVAPokemon/utils/database"
 	"github.com/NOVAPokemon/utils/experience"
 	"github.com/NOVAPokemon/utils/items"
 	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/species"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,8 +21,14 @@ import (
 const databaseName = "NOVAPokemonDB"
 const collectionName = "Trainers"
 
+// times a trainer is read again when it changes between the read and the update
+const maxUpdateRetries = 5
+
 var dbClient databaseUtils.DBClient
 
+// keeps RecoverTrades from releasing the escrow of trades still being executed by this process
+var tradesLock sync.RWMutex
+
 func init() {
 	url, exists := os.LookupEnv(utils.MongoEnvVar)
 	if !exists {
@@ -104,10 +112,10 @@ func GetTrainerByUsername(username string) (*utils.Trainer, error) {
 	return &result, nil
 }
 
+// UpdateTrainerStats sets the trainer's stats, computing the level from the experience. The rewards of
+// the levels gained are applied in the same update, so they are given exactly once. Reward items
+// that do not fit in their stack are dropped.
 func UpdateTrainerStats(username string, stats utils.TrainerStats) (*utils.TrainerStats, error) {
-	ctx := dbClient.Ctx
-	collection := dbClient.Collection
-
 	if stats.Level < 0 {
 		return nil, wrapUpdateTrainerStatsError(ErrorInvalidLevel, username)
 	}
@@ -116,20 +124,58 @@ func UpdateTrainerStats(username string, stats utils.TrainerStats) (*utils.Train
 		return nil, wrapUpdateTrainerStatsError(ErrorInvalidCoins, username)
 	}
 
-	filter := bson.M{"username": username}
-	stats.Level = experience.CalculateLevel(stats.XP)
-	changes := bson.M{"$set": bson.M{"stats.level": stats.Level, "stats.coins": stats.Coins, "stats.xp": stats.XP}}
+	for i := 0; i < maxUpdateRetries; i++ {
+		updated, err := updateTrainerStats(username, stats)
+		if err != errorStatsChanged {
+			return updated, wrapUpdateTrainerStatsError(err, username)
+		}
+	}
+
+	return nil, wrapUpdateTrainerStatsError(ErrorConcurrentUpdate, username)
+}
 
-	res, err := collection.UpdateOne(*ctx, filter, changes)
+func updateTrainerStats(username string, stats utils.TrainerStats) (*utils.TrainerStats, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(username)
 	if err != nil {
-		return nil, wrapUpdateTrainerStatsError(err, username)
+		return nil, ErrorTrainerNotFound
 	}
 
-	if res.MatchedCount > 0 {
-		log.Infof("Updated Trainer %s", username)
-	} else {
-		return nil, wrapUpdateTrainerStatsError(ErrorTrainerNotFound, username)
+	progression := experience.DefaultProgression
+	levels, reward := progression.RewardsFor(trainer.Stats.XP, stats.XP-trainer.Stats.XP)
+
+	stats.Level = progression.Curve.Level(stats.XP)
+	stats.Coins += reward.Coins
+
+	// the update only applies if the experience and the rewarded stacks were not changed meanwhile
+	rewardItems := rewardStacks(trainer.Items, reward.Items)
+	filter := unchangedStacksFilter(trainer.Items, rewardItems)
+	filter["username"], filter["stats.xp"] = username, trainer.Stats.XP
+
+	change := stacksChange(rewardItems, 0)
+	set, ok := change["$set"].(bson.M)
+	if !ok {
+		set = bson.M{}
+		change["$set"] = set
 	}
+	set["stats.level"], set["stats.coins"], set["stats.xp"] = stats.Level, stats.Coins, stats.XP
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.MatchedCount == 0 {
+		return nil, errorStatsChanged
+	}
+
+	if len(levels) > 0 {
+		log.Infof("Trainer %s reached level %d, rewarded %d coins and %d items", username,
+			levels[len(levels)-1], reward.Coins, rewardItems.Total())
+	}
+	log.Infof("Updated Trainer %s", username)
 
 	return &stats, nil
 }
@@ -154,99 +200,136 @@ func removeAll() error {
 
 // BAG OPERATIONS
 
-func AddItemToTrainer(username string, item items.Item) (map[string]items.Item, error) {
+func AddItemToTrainer(username string, item items.Item) (items.Inventory, error) {
+	inventory, err := addItems(username, []items.Item{item})
+	return inventory, wrapAddItemToTrainerError(err, username)
+}
+
+// AddItemsToTrainer adds the items to the trainer's stacks. No item is added if any of the stacks
+// would go over its cap.
+func AddItemsToTrainer(username string, itemsToAdd []items.Item) (items.Inventory, error) {
+	inventory, err := addItems(username, itemsToAdd)
+	if err == nil {
+		log.Infof("Added %d items to user %s", len(itemsToAdd), username)
+	}
+
+	return inventory, wrapAddItemsToTrainerError(err, username)
+}
+
+func RemoveItemFromTrainer(username string, itemName string) (items.Inventory, error) {
+	inventory, err := removeItems(username, []string{itemName})
+	return inventory, wrapRemoveItemToTrainerError(err, username)
+}
+
+// RemoveItemsFromTrainer removes one item from the stack of each name given, so names can be
+// repeated. Nothing is removed if the trainer does not have enough items.
+func RemoveItemsFromTrainer(username string, itemNames []string) (items.Inventory, error) {
+	inventory, err := removeItems(username, itemNames)
+	return inventory, wrapRemoveItemsToTrainerError(err, username)
+}
+
+// MigrateTrainersItems groups the items of trainers stored with one entry per item into stacks
+func MigrateTrainersItems() error {
 	ctx := dbClient.Ctx
 	collection := dbClient.Collection
 
-	itemId := primitive.NewObjectID()
-	item.Id = itemId.Hex()
+	trainers, err := GetAllTrainers()
+	if err != nil {
+		return wrapMigrateItemsError(err)
+	}
 
-	filter := bson.M{"username": username}
-	change := bson.M{"$set": bson.M{"items." + itemId.Hex(): item}}
-	opts := &options.FindOneAndUpdateOptions{}
-	opts.SetReturnDocument(options.After)
+	for _, trainer := range trainers {
+		inventory, migrated := migrateInventory(trainer.Items)
+		if !migrated {
+			continue
+		}
 
-	res := collection.FindOneAndUpdate(*ctx, filter, change, opts)
-	if res.Err() != nil {
-		return nil, wrapAddItemToTrainerError(ErrorTrainerNotFound, username)
+		filter := bson.M{"username": trainer.Username}
+		change := bson.M{"$set": bson.M{"items": inventory}}
+		if _, err = collection.UpdateOne(*ctx, filter, change); err != nil {
+			return wrapMigrateItemsError(err)
+		}
+
+		log.Infof("Migrated %d items of trainer %s to %d stacks", inventory.Total(), trainer.Username,
+			len(inventory))
 	}
 
-	trainer := utils.Trainer{}
-	err := res.Decode(&trainer)
-	return trainer.Items, wrapAddItemToTrainerError(err, username)
+	return nil
 }
 
-func AddItemsToTrainer(username string, itemsToAdd []items.Item) (map[string]items.Item, error) {
+func addItems(username string, itemsToAdd []items.Item) (items.Inventory, error) {
 	ctx := dbClient.Ctx
 	collection := dbClient.Collection
 
-	itemsObjects := make(map[string]items.Item, len(itemsToAdd))
-
+	toAdd := items.Inventory{}
 	for _, item := range itemsToAdd {
-		itemsObjects["items."+item.Id] = item
+		toAdd.AddUncapped(item, 1)
 	}
 
-	filter := bson.M{"username": username}
-	change := bson.M{"$set": itemsObjects}
+	filter, change, err := addStacksUpdate(toAdd)
+	if err != nil {
+		return nil, err
+	}
+	filter["username"] = username
+
 	opts := &options.FindOneAndUpdateOptions{}
 	opts.SetReturnDocument(options.After)
 
 	res := collection.FindOneAndUpdate(*ctx, filter, change, opts)
 	if res.Err() != nil {
-		log.Error(res.Err())
-		return nil, wrapAddItemsToTrainerError(ErrorTrainerNotFound, username)
-	}
-
-	log.Infof("Added items to user %s:", username)
-	for _, item := range itemsToAdd {
-		log.Info(item.Id)
+		if _, err = GetTrainerByUsername(username); err != nil {
+			return nil, ErrorTrainerNotFound
+		}
+		return nil, items.ErrorStackFull
 	}
 
 	trainer := utils.Trainer{}
-	err := res.Decode(&trainer)
-	return trainer.Items, wrapAddItemsToTrainerError(err, username)
+	err = res.Decode(&trainer)
+	return trainer.Items, err
 }
 
-func RemoveItemFromTrainer(username string, itemId string) (map[string]items.Item, error) {
-	ctx := dbClient.Ctx
-	collection := dbClient.Collection
-	filter := bson.M{"username": username}
-	change := bson.M{"$unset": bson.M{"items." + itemId: nil}}
-	opts := &options.FindOneAndUpdateOptions{}
-	opts.SetReturnDocument(options.After)
+func removeItems(username string, itemNames []string) (items.Inventory, error) {
+	counts := countNames(itemNames)
 
-	res := collection.FindOneAndUpdate(*ctx, filter, change, opts)
-	if res.Err() != nil {
-		return nil, wrapRemoveItemToTrainerError(ErrorTrainerNotFound, username)
+	for i := 0; i < maxUpdateRetries; i++ {
+		inventory, err := tryRemoveItems(username, counts)
+		if err != errorItemsChanged {
+			return inventory, err
+		}
 	}
 
-	trainer := utils.Trainer{}
-	err := res.Decode(&trainer)
-	return trainer.Items, wrapRemoveItemToTrainerError(err, username)
+	return nil, ErrorConcurrentUpdate
 }
 
-func RemoveItemsFromTrainer(username string, itemIds []primitive.ObjectID) (map[string]items.Item, error) {
+func tryRemoveItems(username string, counts map[string]int) (items.Inventory, error) {
 	ctx := dbClient.Ctx
 	collection := dbClient.Collection
-	filter := bson.M{"username": username}
 
-	itemsObjects := make(map[string]*struct{}, len(itemIds))
-	for _, id := range itemIds {
-		itemsObjects["items."+id.Hex()] = nil
+	trainer, err := GetTrainerByUsername(username)
+	if err != nil {
+		return nil, ErrorTrainerNotFound
 	}
 
-	change := bson.M{"$unset": itemsObjects}
-	opts := &options.FindOneAndUpdateOptions{}
-	opts.SetReturnDocument(options.Before)
+	if len(counts) == 0 {
+		return trainer.Items, nil
+	}
 
-	res := collection.FindOneAndUpdate(*ctx, filter, change, opts)
-	if res.Err() != nil {
-		return nil, wrapRemoveItemsToTrainerError(ErrorTrainerNotFound, username)
+	filter, change, remaining, err := removeStacksUpdate(trainer.Items, counts)
+	if err != nil {
+		return nil, err
 	}
+	filter["username"] = username
 
-	trainer := utils.Trainer{}
-	err := res.Decode(&trainer)
-	return trainer.Items, wrapRemoveItemsToTrainerError(err, username)
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.MatchedCount == 0 {
+		return nil, errorItemsChanged
+	}
+
+	return remaining, nil
 }
 
 // POKEMON OPERATIONS
@@ -309,3 +392,474 @@ func RemovePokemonFromTrainer(username string, pokemonId string) (map[string]pok
 	err := res.Decode(&trainer)
 	return trainer.Pokemons, wrapRemovePokemonFromTrainerError(err, username)
 }
+
+// EvolveTrainerPokemon evolves one of the trainer's pokemons, using up the given item for item
+// based evolutions. The update only goes through if the pokemon was not changed meanwhile and
+// the trainer still has the item.
+func EvolveTrainerPokemon(username string, pokemonId string, itemName string) (*pokemons.Pokemon, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(username)
+	if err != nil {
+		return nil, wrapEvolveTrainerPokemonError(err, username)
+	}
+
+	pokemon, ok := trainer.Pokemons[pokemonId]
+	if !ok {
+		return nil, wrapEvolveTrainerPokemonError(ErrorPokemonNotFound, username)
+	}
+
+	previousSpecies := pokemon.Species
+	if _, err = pokemon.Evolve(species.DefaultCatalogue, itemName); err != nil {
+		return nil, wrapEvolveTrainerPokemonError(err, username)
+	}
+	pokemon.Id = pokemonId
+
+	filter := bson.M{"username": username, "pokemons." + pokemonId + ".species": previousSpecies}
+	change := bson.M{"$set": bson.M{"pokemons." + pokemonId: pokemon}}
+	if itemName != "" {
+		itemFilter, itemChange, _, err := removeStacksUpdate(trainer.Items, map[string]int{itemName: 1})
+		if err != nil {
+			return nil, wrapEvolveTrainerPokemonError(err, username)
+		}
+
+		for field, condition := range itemFilter {
+			filter[field] = condition
+		}
+		mergeChanges(change, itemChange)
+	}
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return nil, wrapEvolveTrainerPokemonError(err, username)
+	}
+
+	if res.MatchedCount == 0 {
+		return nil, wrapEvolveTrainerPokemonError(ErrorEvolutionConflict, username)
+	}
+
+	return &pokemon, nil
+}
+
+// AddExperienceToTrainerPokemon applies the experience gain to one of the trainer's pokemons,
+// growing its stats when it levels up
+func AddExperienceToTrainerPokemon(username, pokemonId string, xp float64) (*pokemons.Pokemon, error) {
+	for i := 0; i < maxUpdateRetries; i++ {
+		pokemon, err := addExperienceToTrainerPokemon(username, pokemonId, xp)
+		if err != errorPokemonChanged {
+			return pokemon, wrapAddPokemonExperienceError(err, username)
+		}
+	}
+
+	return nil, wrapAddPokemonExperienceError(ErrorConcurrentUpdate, username)
+}
+
+func addExperienceToTrainerPokemon(username, pokemonId string, xp float64) (*pokemons.Pokemon, error) {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(username)
+	if err != nil {
+		return nil, ErrorTrainerNotFound
+	}
+
+	pokemon, ok := trainer.Pokemons[pokemonId]
+	if !ok {
+		return nil, ErrorPokemonNotFound
+	}
+
+	grown, levels := pokemons.GainExperience(pokemon, xp)
+	grown.Id = pokemonId
+
+	filter := bson.M{"username": username, "pokemons." + pokemonId + ".xp": pokemon.XP}
+	change := bson.M{"$set": bson.M{"pokemons." + pokemonId: grown}}
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.MatchedCount == 0 {
+		return nil, errorPokemonChanged
+	}
+
+	if len(levels) > 0 {
+		log.Infof("Pokemon %s of trainer %s reached level %d", pokemonId, username, grown.Level)
+	}
+
+	return &grown, nil
+}
+
+// TRADE OPERATIONS
+
+// ExecuteTrade moves the offered assets between the two trainers. The assets of each trainer are
+// first moved to escrow, so they can be given back if the other trainer no longer has what they
+// offered. Once both are in escrow the trade is committed and the assets are delivered, which
+// RecoverTrades finishes if the process stops midway.
+func ExecuteTrade(tradeId string, offers [2]utils.TradeOffer) error {
+	tradesLock.RLock()
+	defer tradesLock.RUnlock()
+
+	if err := escrowTradeOffer(tradeId, offers[0], offers[1].Username); err != nil {
+		return wrapExecuteTradeError(err, tradeId)
+	}
+
+	if err := escrowTradeOffer(tradeId, offers[1], offers[0].Username); err != nil {
+		if releaseErr := releaseTradeEscrow(tradeId, offers[0].Username); releaseErr != nil {
+			log.Error(releaseErr)
+		}
+		return wrapExecuteTradeError(err, tradeId)
+	}
+
+	for _, offer := range offers {
+		if err := commitTradeEscrow(tradeId, offer.Username); err != nil {
+			return wrapExecuteTradeError(err, tradeId)
+		}
+	}
+
+	for _, offer := range offers {
+		if err := deliverTradeEscrow(tradeId, offer.Username); err != nil {
+			return wrapExecuteTradeError(err, tradeId)
+		}
+	}
+
+	return nil
+}
+
+// RecoverTrades finishes the trades left in escrow. Committed trades are delivered and the
+// others are given back to their owners. The received trades kept to not deliver twice are then
+// cleared. It waits for the trades this process is executing, but it cannot see the ones executed
+// by other processes, so it must run when the trades server starts, before it serves any trade.
+func RecoverTrades() error {
+	tradesLock.Lock()
+	defer tradesLock.Unlock()
+
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	filter := bson.M{"escrow": bson.M{"$exists": true}}
+	cur, err := collection.Find(*ctx, filter)
+	if err != nil {
+		return wrapRecoverTradesError(err)
+	}
+
+	defer databaseUtils.CloseCursor(cur, ctx)
+
+	owners := map[string][]string{}
+	committed := map[string]bool{}
+	for cur.Next(*ctx) {
+		var trainer utils.Trainer
+		if err = cur.Decode(&trainer); err != nil {
+			return wrapRecoverTradesError(err)
+		}
+
+		for tradeId, escrow := range trainer.Escrow {
+			owners[tradeId] = append(owners[tradeId], trainer.Username)
+			committed[tradeId] = committed[tradeId] || escrow.Committed
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		return wrapRecoverTradesError(err)
+	}
+
+	for tradeId, usernames := range owners {
+		for _, username := range usernames {
+			if committed[tradeId] {
+				err = deliverTradeEscrow(tradeId, username)
+			} else {
+				err = releaseTradeEscrow(tradeId, username)
+			}
+
+			if err != nil {
+				return wrapRecoverTradesError(err)
+			}
+		}
+	}
+
+	// every escrow is gone, so the received trades left by deliveries that stopped midway are stale
+	filter = bson.M{"receivedtrades": bson.M{"$exists": true}}
+	change := bson.M{"$unset": bson.M{"receivedtrades": nil}}
+	if _, err = collection.UpdateMany(*ctx, filter, change); err != nil {
+		return wrapRecoverTradesError(err)
+	}
+
+	return nil
+}
+
+func escrowTradeOffer(tradeId string, offer utils.TradeOffer, recipient string) error {
+	if offer.Coins < 0 {
+		return wrapEscrowTradeError(ErrorInvalidCoins, offer.Username)
+	}
+
+	for i := 0; i < maxUpdateRetries; i++ {
+		err := tryEscrowTradeOffer(tradeId, offer, recipient)
+		if err != errorItemsChanged {
+			return wrapEscrowTradeError(err, offer.Username)
+		}
+	}
+
+	return wrapEscrowTradeError(ErrorTradeAssetsChanged, offer.Username)
+}
+
+func tryEscrowTradeOffer(tradeId string, offer utils.TradeOffer, recipient string) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(offer.Username)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := trainer.Escrow[tradeId]; ok || trainer.Stats.Coins < offer.Coins {
+		return ErrorTradeAssetsChanged
+	}
+
+	escrow := utils.TradeEscrow{
+		Recipient: recipient,
+		Items:     items.Inventory{},
+		Pokemons:  make(map[string]pokemons.Pokemon, len(offer.PokemonIds)),
+		Coins:     offer.Coins,
+	}
+
+	itemCounts := countNames(offer.ItemIds)
+	for name, count := range itemCounts {
+		stack, ok := trainer.Items[name]
+		if !ok || stack.Quantity < count {
+			return ErrorItemNotFound
+		}
+
+		stack.Quantity = count
+		escrow.Items[name] = stack
+	}
+
+	// the trainer must still own exactly what was offered when the escrow is written
+	filter, change, _, err := removeStacksUpdate(trainer.Items, itemCounts)
+	if err != nil {
+		return err
+	}
+	filter["username"] = offer.Username
+	filter["stats.coins"] = bson.M{"$gte": offer.Coins}
+	filter["escrow."+tradeId] = bson.M{"$exists": false}
+
+	unset := bson.M{}
+	for _, pokemonId := range offer.PokemonIds {
+		pokemon, ok := trainer.Pokemons[pokemonId]
+		if !ok {
+			return ErrorPokemonNotFound
+		}
+
+		escrow.Pokemons[pokemonId] = pokemon
+		filter["pokemons."+pokemonId] = bson.M{"$exists": true}
+		unset["pokemons."+pokemonId] = nil
+	}
+
+	mergeChanges(change, bson.M{
+		"$set": bson.M{"escrow." + tradeId: escrow},
+		"$inc": bson.M{"stats.coins": -offer.Coins},
+	})
+	if len(unset) > 0 {
+		mergeChanges(change, bson.M{"$unset": unset})
+	}
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return errorItemsChanged
+	}
+
+	return nil
+}
+
+func commitTradeEscrow(tradeId, username string) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	filter := bson.M{"username": username, "escrow." + tradeId: bson.M{"$exists": true}}
+	change := bson.M{"$set": bson.M{"escrow." + tradeId + ".committed": true}}
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return wrapCommitTradeError(err, username)
+	}
+
+	if res.MatchedCount == 0 {
+		return wrapCommitTradeError(ErrorEscrowNotFound, username)
+	}
+
+	return nil
+}
+
+// deliverTradeEscrow gives the assets in escrow to the recipient and then clears the escrow. The
+// recipient keeps the trades it received, so delivering twice has no effect. Items that do not fit
+// in the recipient's stacks are first given back to the sender, ignoring its stack caps so no item
+// is lost.
+func deliverTradeEscrow(tradeId, username string) error {
+	for i := 0; i < maxUpdateRetries; i++ {
+		err := tryDeliverTradeEscrow(tradeId, username)
+		if err != errorItemsChanged {
+			return wrapDeliverTradeError(err, username)
+		}
+	}
+
+	return wrapDeliverTradeError(ErrorConcurrentUpdate, username)
+}
+
+func tryDeliverTradeEscrow(tradeId, username string) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	escrow, ok := trainer.Escrow[tradeId]
+	if !ok {
+		return nil
+	}
+
+	recipient, err := GetTrainerByUsername(escrow.Recipient)
+	if err != nil {
+		return err
+	}
+
+	tradeKey := receivedTradeKey(tradeId, username)
+	if !containsString(recipient.ReceivedTrades, tradeKey) {
+		fit, overflow := fittingStacks(recipient.Items, escrow.Items)
+		if len(overflow) > 0 {
+			if err = returnEscrowOverflow(tradeId, username, escrow.Items, fit, overflow); err != nil {
+				return err
+			}
+			escrow.Items = fit
+		}
+
+		filter := unchangedStacksFilter(recipient.Items, escrow.Items)
+		filter["username"] = escrow.Recipient
+		filter["receivedtrades"] = bson.M{"$ne": tradeKey}
+		change := assetsChange(escrow)
+		change["$push"] = bson.M{"receivedtrades": tradeKey}
+
+		res, err := collection.UpdateOne(*ctx, filter, change)
+		if err != nil {
+			return err
+		}
+
+		if res.MatchedCount == 0 {
+			return errorItemsChanged
+		}
+	}
+
+	filter := bson.M{"username": username}
+	change := bson.M{"$unset": bson.M{"escrow." + tradeId: nil}}
+
+	if _, err = collection.UpdateOne(*ctx, filter, change); err != nil {
+		return err
+	}
+
+	// the escrow is gone, so the trade can no longer be delivered twice
+	filter = bson.M{"username": escrow.Recipient}
+	change = bson.M{"$pull": bson.M{"receivedtrades": tradeKey}}
+
+	_, err = collection.UpdateOne(*ctx, filter, change)
+	return err
+}
+
+// returnEscrowOverflow gives the overflowing items back to the sender, leaving in escrow only the
+// ones that fit
+func returnEscrowOverflow(tradeId, username string, escrowed, fit, overflow items.Inventory) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	escrowItemsField := "escrow." + tradeId + ".items."
+	filter := bson.M{"username": username}
+	change := stacksChange(overflow, 0)
+	unset := bson.M{}
+	for name, stack := range overflow {
+		filter[escrowItemsField+name+".quantity"] = escrowed[name].Quantity
+		if _, ok := fit[name]; ok {
+			change["$inc"].(bson.M)[escrowItemsField+name+".quantity"] = -stack.Quantity
+		} else {
+			unset[escrowItemsField+name] = nil
+		}
+	}
+
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+
+	res, err := collection.UpdateOne(*ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return errorItemsChanged
+	}
+
+	log.Warnf("Gave %d items of trade %s back to %s, as they did not fit in the recipient's stacks",
+		overflow.Total(), tradeId, username)
+	return nil
+}
+
+// releaseTradeEscrow gives the assets in escrow back to their owner
+func releaseTradeEscrow(tradeId, username string) error {
+	ctx := dbClient.Ctx
+	collection := dbClient.Collection
+
+	trainer, err := GetTrainerByUsername(username)
+	if err != nil {
+		return wrapReleaseTradeError(err, username)
+	}
+
+	escrow, ok := trainer.Escrow[tradeId]
+	if !ok {
+		return nil
+	}
+
+	filter := bson.M{"username": username, "escrow." + tradeId: bson.M{"$exists": true}}
+	change := assetsChange(escrow)
+	change["$unset"] = bson.M{"escrow." + tradeId: nil}
+
+	_, err = collection.UpdateOne(*ctx, filter, change)
+	return wrapReleaseTradeError(err, username)
+}
+
+func assetsChange(escrow utils.TradeEscrow) bson.M {
+	change := stacksChange(escrow.Items, escrow.Coins)
+
+	assets := bson.M{}
+	for pokemonId, pokemon := range escrow.Pokemons {
+		assets["pokemons."+pokemonId] = pokemon
+	}
+
+	if len(assets) > 0 {
+		if set, ok := change["$set"].(bson.M); ok {
+			for key, value := range assets {
+				set[key] = value
+			}
+		} else {
+			change["$set"] = assets
+		}
+	}
+
+	return change
+}
+
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func receivedTradeKey(tradeId, sender string) string {
+	return tradeId + "/" + sender
+}
@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/experience"
 	"github.com/NOVAPokemon/utils/items"
 	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/species"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,7 +17,7 @@ import (
 var trainerMockup = utils.Trainer{
 	Username: "trainer1",
 	Pokemons: map[string]pokemons.Pokemon{},
-	Items:    map[string]items.Item{},
+	Items:    items.Inventory{},
 	Stats: utils.TrainerStats{
 		Level: 0,
 		Coins: 0,
@@ -190,59 +192,186 @@ func TestAppendAndRemoveItem(t *testing.T) {
 		Name: "Soup",
 	}
 
-	toAppend2 := items.Item{
-		Name: "Soup",
-	}
-
-	// add item, verify that it is in trainer
-	itemsAdded, err := AddItemToTrainer(userName, toAppend)
+	// add two items of the same kind, verify that they are stacked
+	itemsAdded, err := AddItemsToTrainer(userName, []items.Item{toAppend, toAppend})
 
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
 
-	assert.True(t, len(itemsAdded) == 1)
+	assert.Len(t, itemsAdded, 1)
+	assert.Equal(t, 2, itemsAdded.Quantity(toAppend.Name))
 
-	// add another item, verify that trainer has both itemsAdded
-	itemsAdded, err = AddItemToTrainer(userName, toAppend2)
+	// delete one item, verify that the stack has the remaining item
+	itemsAdded, err = RemoveItemFromTrainer(userName, toAppend.Name)
 
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
 
-	assert.True(t, len(itemsAdded) == 2)
+	assert.Equal(t, 1, itemsAdded.Quantity(toAppend.Name))
 
-	toRemove := ""
-	for _, v := range itemsAdded {
-		toRemove = v.Id
-	}
+	// removing more items than the stack has must not change it
+	_, err = RemoveItemsFromTrainer(userName, []string{toAppend.Name, toAppend.Name})
+	assert.Error(t, err)
 
-	// delete one item, verify that trainer has  the remaining Item
-	itemsAdded, err = RemoveItemFromTrainer(userName, toRemove)
+	// remove remaining item, assure the stack is gone
+	itemsAdded, err = RemoveItemFromTrainer(userName, toAppend.Name)
 
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
 
-	assert.True(t, len(itemsAdded) == 1)
+	assert.Empty(t, itemsAdded)
+
+	trainer, _ := GetTrainerByUsername(userName)
+	assert.NotContains(t, trainer.Items, toAppend.Name)
+	_ = DeleteTrainer(userName)
+
+}
+
+func TestStackCapIsEnforced(t *testing.T) {
+	userName, _ := AddTrainer(trainerMockup)
+
+	masterBalls := make([]items.Item, items.MaxStackFor(items.MasterBallName))
+	for i := range masterBalls {
+		masterBalls[i] = items.MasterBallItem
+	}
+
+	_, err := AddItemsToTrainer(userName, masterBalls)
+	assert.NoError(t, err)
 
-	toRemove = ""
-	for _, v := range itemsAdded {
-		toRemove = v.Id
+	_, err = AddItemToTrainer(userName, items.MasterBallItem)
+	assert.Error(t, err)
+
+	_ = DeleteTrainer(userName)
+}
+
+func TestExecuteTrade(t *testing.T) {
+	trainer1 := trainerMockup
+	trainer1.Stats = utils.TrainerStats{Coins: 50}
+	trainer2 := utils.Trainer{
+		Username: "trainer2",
+		Pokemons: map[string]pokemons.Pokemon{},
+		Items:    items.Inventory{},
 	}
 
-	// Remove remaining item, assure there are no itemsAdded remaining
-	itemsAdded, err = RemoveItemFromTrainer(userName, toRemove)
+	_, _ = AddTrainer(trainer1)
+	_, _ = AddTrainer(trainer2)
+
+	_, _ = AddItemToTrainer(trainer1.Username, items.HealItem)
+	pokemon := pokemons.Pokemon{Id: primitive.NewObjectID().Hex()}
+	_, _ = AddPokemonToTrainer(trainer2.Username, pokemon)
+
+	itemId := items.HealItem.Name
 
+	offers := [2]utils.TradeOffer{
+		{Username: trainer1.Username, ItemIds: []string{itemId}, Coins: 20},
+		{Username: trainer2.Username, PokemonIds: []string{pokemon.Id}},
+	}
+
+	err := ExecuteTrade(primitive.NewObjectID().Hex(), offers)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
 
-	assert.True(t, len(itemsAdded) == 0)
-	_ = DeleteTrainer(userName)
+	updated1, _ := GetTrainerByUsername(trainer1.Username)
+	updated2, _ := GetTrainerByUsername(trainer2.Username)
+
+	assert.Contains(t, updated1.Pokemons, pokemon.Id)
+	assert.NotContains(t, updated1.Items, itemId)
+	assert.Equal(t, 30, updated1.Stats.Coins)
+	assert.Contains(t, updated2.Items, itemId)
+	assert.NotContains(t, updated2.Pokemons, pokemon.Id)
+	assert.Equal(t, 20, updated2.Stats.Coins)
+	assert.Empty(t, updated1.Escrow)
+	assert.Empty(t, updated2.Escrow)
+
+	// the pokemon is no longer trainer2's, so the trade must fail and give trainer1's assets back
+	err = ExecuteTrade(primitive.NewObjectID().Hex(), [2]utils.TradeOffer{
+		{Username: trainer1.Username, Coins: 10},
+		{Username: trainer2.Username, PokemonIds: []string{pokemon.Id}},
+	})
+	assert.Error(t, err)
+
+	updated1, _ = GetTrainerByUsername(trainer1.Username)
+	assert.Equal(t, 30, updated1.Stats.Coins)
+	assert.Empty(t, updated1.Escrow)
+
+	_ = DeleteTrainer(trainer1.Username)
+	_ = DeleteTrainer(trainer2.Username)
+}
+
+func TestEvolveTrainerPokemon(t *testing.T) {
+	_, _ = AddTrainer(trainerMockup)
+	defer func() { _ = DeleteTrainer(trainerMockup.Username) }()
+
+	pokemon := pokemons.Pokemon{Id: primitive.NewObjectID().Hex(), Species: "pikachu", Level: 5, HP: 10, MaxHP: 10,
+		Damage: 10}
+	_, _ = AddPokemonToTrainer(trainerMockup.Username, pokemon)
+
+	_, err := EvolveTrainerPokemon(trainerMockup.Username, pokemon.Id, species.ThunderStoneName)
+	assert.Error(t, err)
+
+	stone, _ := items.DefaultCatalogue.NewItem(species.ThunderStoneName)
+	_, _ = AddItemToTrainer(trainerMockup.Username, stone)
+
+	evolved, err := EvolveTrainerPokemon(trainerMockup.Username, pokemon.Id, species.ThunderStoneName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "raichu", evolved.Species)
+
+	trainer, _ := GetTrainerByUsername(trainerMockup.Username)
+	assert.Equal(t, "raichu", trainer.Pokemons[pokemon.Id].Species)
+	assert.Equal(t, 0, trainer.Items.Quantity(species.ThunderStoneName))
+}
+
+func TestUpdateTrainerStatsGivesLevelRewards(t *testing.T) {
+	_, _ = AddTrainer(trainerMockup)
+	defer func() { _ = DeleteTrainer(trainerMockup.Username) }()
+
+	previous := experience.DefaultProgression
+	defer func() { experience.DefaultProgression = previous }()
+	experience.DefaultProgression = &experience.Progression{
+		Curve:      experience.QuadraticCurve{Factor: 1, Max: 10},
+		EveryLevel: experience.LevelReward{Coins: 10},
+		Rewards:    map[int]experience.LevelReward{2: {Items: []string{items.PokeBallName}}},
+	}
+
+	stats, err := UpdateTrainerStats(trainerMockup.Username, utils.TrainerStats{XP: 4, Coins: 5})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, utils.TrainerStats{XP: 4, Level: 2, Coins: 25}, *stats)
+
+	// updating again with the same experience gives no rewards
+	stats, _ = UpdateTrainerStats(trainerMockup.Username, *stats)
+	assert.Equal(t, 25, stats.Coins)
+
+	trainer, _ := GetTrainerByUsername(trainerMockup.Username)
+	assert.Equal(t, 1, trainer.Items.Quantity(items.PokeBallName))
+}
+
+func TestAddExperienceToTrainerPokemon(t *testing.T) {
+	_, _ = AddTrainer(trainerMockup)
+	defer func() { _ = DeleteTrainer(trainerMockup.Username) }()
+
+	pokemon := pokemons.Pokemon{Id: primitive.NewObjectID().Hex(), Species: "charmander", Level: 1, XP: 1, HP: 10,
+		MaxHP: 10, Damage: 5}
+	_, _ = AddPokemonToTrainer(trainerMockup.Username, pokemon)
+
+	grown, err := AddExperienceToTrainerPokemon(trainerMockup.Username, pokemon.Id, 3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, grown.Level)
+	assert.Equal(t, 20, grown.MaxHP)
 
+	trainer, _ := GetTrainerByUsername(trainerMockup.Username)
+	assert.Equal(t, *grown, trainer.Pokemons[pokemon.Id])
 }
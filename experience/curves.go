@@ -0,0 +1,178 @@
+package experience
+
+import (
+	"math"
+)
+
+// Curve types
+const (
+	QuadraticCurveType   = "QUADRATIC"
+	ExponentialCurveType = "EXPONENTIAL"
+	TableCurveType       = "TABLE"
+)
+
+const DefaultMaxLevel = 100
+
+type (
+	// Curve maps experience to levels. Levels never go over the curve's max level.
+	Curve interface {
+		Level(xp float64) int
+		MinXP(level int) float64
+		MaxLevel() int
+	}
+
+	// QuadraticCurve needs Factor * level^2 experience for each level
+	QuadraticCurve struct {
+		Factor float64
+		Max    int
+	}
+
+	// ExponentialCurve needs Base experience for level 1 and Growth times more for each level after it
+	ExponentialCurve struct {
+		Base   float64
+		Growth float64
+		Max    int
+	}
+
+	// TableCurve needs Thresholds[i] experience for level i+1, so its max level is the number of thresholds
+	TableCurve struct {
+		Thresholds []float64
+	}
+
+	CurveConfig struct {
+		Type       string    `json:"type"`
+		Factor     float64   `json:"factor,omitempty"`
+		Base       float64   `json:"base,omitempty"`
+		Growth     float64   `json:"growth,omitempty"`
+		Thresholds []float64 `json:"thresholds,omitempty"`
+		MaxLevel   int       `json:"max_level,omitempty"`
+	}
+)
+
+// NewCurve creates the curve described by the config. Curves without a max level get DefaultMaxLevel,
+// except for table curves, whose max level is set by their thresholds and cannot be given.
+func NewCurve(config CurveConfig) (Curve, error) {
+	maxLevel := config.MaxLevel
+	if maxLevel == 0 {
+		maxLevel = DefaultMaxLevel
+	}
+
+	if maxLevel < 0 {
+		return nil, ErrorInvalidCurve
+	}
+
+	switch config.Type {
+	case QuadraticCurveType:
+		if config.Factor <= 0 {
+			return nil, ErrorInvalidCurve
+		}
+		return QuadraticCurve{Factor: config.Factor, Max: maxLevel}, nil
+	case ExponentialCurveType:
+		if config.Base <= 0 || config.Growth <= 1 {
+			return nil, ErrorInvalidCurve
+		}
+		return ExponentialCurve{Base: config.Base, Growth: config.Growth, Max: maxLevel}, nil
+	case TableCurveType:
+		if len(config.Thresholds) == 0 || config.MaxLevel != 0 {
+			return nil, ErrorInvalidCurve
+		}
+		for i := 1; i < len(config.Thresholds); i++ {
+			if config.Thresholds[i] <= config.Thresholds[i-1] {
+				return nil, ErrorInvalidCurve
+			}
+		}
+		return TableCurve{Thresholds: config.Thresholds}, nil
+	default:
+		return nil, ErrorUnknownCurveType
+	}
+}
+
+func (c QuadraticCurve) Level(xp float64) int {
+	if xp <= 0 {
+		return 0
+	}
+
+	return capLevel(int(math.Sqrt(xp/c.Factor)), c.Max)
+}
+
+func (c QuadraticCurve) MinXP(level int) float64 {
+	level = capLevel(level, c.Max)
+	return c.Factor * float64(level) * float64(level)
+}
+
+func (c QuadraticCurve) MaxLevel() int {
+	return c.Max
+}
+
+func (c ExponentialCurve) Level(xp float64) int {
+	if xp < c.Base {
+		return 0
+	}
+
+	level := int(math.Log(xp/c.Base)/math.Log(c.Growth)) + 1
+	// rounding errors may leave the level one off at the thresholds
+	if c.MinXP(level+1) <= xp && level < c.Max {
+		level++
+	} else if c.MinXP(level) > xp {
+		level--
+	}
+
+	return capLevel(level, c.Max)
+}
+
+func (c ExponentialCurve) MinXP(level int) float64 {
+	level = capLevel(level, c.Max)
+	if level == 0 {
+		return 0
+	}
+
+	return c.Base * math.Pow(c.Growth, float64(level-1))
+}
+
+func (c ExponentialCurve) MaxLevel() int {
+	return c.Max
+}
+
+func (c TableCurve) Level(xp float64) int {
+	level := 0
+	for level < len(c.Thresholds) && xp >= c.Thresholds[level] {
+		level++
+	}
+
+	return level
+}
+
+func (c TableCurve) MinXP(level int) float64 {
+	level = capLevel(level, len(c.Thresholds))
+	if level == 0 {
+		return 0
+	}
+
+	return c.Thresholds[level-1]
+}
+
+func (c TableCurve) MaxLevel() int {
+	return len(c.Thresholds)
+}
+
+// LevelUps returns the levels reached when going from xp to xp+gain, in order
+func LevelUps(curve Curve, xp, gain float64) []int {
+	var levels []int
+	for level := curve.Level(xp) + 1; level <= curve.Level(xp+gain); level++ {
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+func capLevel(level, maxLevel int) int {
+	if level > maxLevel {
+		return maxLevel
+	}
+
+	if level < 0 {
+		return 0
+	}
+
+	return level
+}
@@ -0,0 +1,19 @@
+package experience
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const errorLoadProgressionFormat = "error loading progression %s"
+
+var (
+	ErrorInvalidCurve     = errors.New("invalid experience curve")
+	ErrorUnknownCurveType = errors.New("unknown experience curve type")
+	ErrorInvalidReward    = errors.New("invalid level reward")
+)
+
+func wrapLoadProgressionError(err error, filename string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorLoadProgressionFormat, filename))
+}
@@ -1,8 +1,7 @@
 package experience
 
 import (
-	"math"
-	"math/rand"
+	"github.com/NOVAPokemon/utils/random"
 )
 
 const (
@@ -11,38 +10,35 @@ const (
 	MaxBonusExperience     = 50
 )
 
-func GetTrainerExperienceGainFromBattle(winner bool) float64 {
+// GetTrainerExperienceGainFromBattle draws the winner's bonus from rng, or from the global source when it is nil
+func GetTrainerExperienceGainFromBattle(rng random.Source, winner bool) float64 {
 	xp := MinExperiencePerBattle
 	if winner {
-		xp += rand.Intn(MaxBonusExperience)
+		xp += random.OrGlobal(rng).Intn(MaxBonusExperience)
 	}
 	return float64(xp)
 }
 
-func GetPokemonExperienceGainFromBattle(winner bool) float64 {
+func GetPokemonExperienceGainFromBattle(rng random.Source, winner bool) float64 {
 	xp := MinExperiencePerBattle
 	if winner {
-		xp += rand.Intn(MaxBonusExperience)
+		xp += random.OrGlobal(rng).Intn(MaxBonusExperience)
 	}
 	return float64(xp)
 }
 
-func GetPokemonExperienceGainFromRaid(winner bool) float64 {
+func GetPokemonExperienceGainFromRaid(rng random.Source, winner bool) float64 {
 	xp := MinExperiencePerRaid
 	if winner {
-		xp += rand.Intn(MaxBonusExperience)
+		xp += random.OrGlobal(rng).Intn(MaxBonusExperience)
 	}
 	return float64(xp)
 }
 
 func CalculateLevel(xp float64) int {
-	return int(math.Sqrt(xp))
+	return DefaultProgression.Curve.Level(xp)
 }
 
-// int(math.Floor(25+math.Sqrt(625+100*xp)) / 50)
 func GetMinXpForLevel(level float64) float64 {
-	if level == 0 {
-		return 0
-	}
-	return level * level
+	return DefaultProgression.Curve.MinXP(int(level))
 }
@@ -0,0 +1,60 @@
+package experience
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurvesAgreeOnLevelsAndMinXP(t *testing.T) {
+	exponential, err := NewCurve(CurveConfig{Type: ExponentialCurveType, Base: 100, Growth: 1.5, MaxLevel: 40})
+	assert.NoError(t, err)
+	table, err := NewCurve(CurveConfig{Type: TableCurveType, Thresholds: []float64{10, 30, 70}})
+	assert.NoError(t, err)
+
+	curves := []Curve{QuadraticCurve{Factor: 2, Max: 50}, exponential, table}
+	for _, curve := range curves {
+		for level := 0; level <= curve.MaxLevel(); level++ {
+			assert.Equal(t, level, curve.Level(curve.MinXP(level)))
+		}
+		assert.Equal(t, curve.MaxLevel(), curve.Level(curve.MinXP(curve.MaxLevel())*10))
+	}
+}
+
+func TestNewCurveRejectsInvalidConfigs(t *testing.T) {
+	_, err := NewCurve(CurveConfig{Type: "CUBIC"})
+	assert.Equal(t, ErrorUnknownCurveType, err)
+
+	_, err = NewCurve(CurveConfig{Type: TableCurveType, Thresholds: []float64{10, 5}})
+	assert.Equal(t, ErrorInvalidCurve, err)
+
+	_, err = NewCurve(CurveConfig{Type: TableCurveType})
+	assert.Equal(t, ErrorInvalidCurve, err)
+
+	_, err = NewCurve(CurveConfig{Type: TableCurveType, Thresholds: []float64{10, 30}, MaxLevel: 50})
+	assert.Equal(t, ErrorInvalidCurve, err)
+}
+
+func TestRewardsForLevelUps(t *testing.T) {
+	progression, err := NewProgression(ProgressionConfig{
+		Curve:      CurveConfig{Type: QuadraticCurveType, Factor: 1, MaxLevel: 10},
+		EveryLevel: LevelReward{Coins: 5},
+		Rewards:    map[int]LevelReward{3: {Items: []string{"poke-ball"}}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	levels, reward := progression.RewardsFor(1, 15)
+	assert.Equal(t, []int{2, 3, 4}, levels)
+	assert.Equal(t, LevelReward{Coins: 15, Items: []string{"poke-ball"}}, reward)
+
+	levels, _ = progression.RewardsFor(90, 1000)
+	assert.Equal(t, []int{10}, levels)
+}
+
+func TestDefaultProgressionGivesNoRewards(t *testing.T) {
+	levels, reward := DefaultProgression.RewardsFor(0, DefaultProgression.Curve.MinXP(60))
+	assert.Len(t, levels, 60)
+	assert.Equal(t, LevelReward{}, reward)
+}
@@ -0,0 +1,92 @@
+package experience
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+type (
+	// LevelReward is given to a trainer when reaching a level. Items are item names.
+	LevelReward struct {
+		Coins int      `json:"coins,omitempty"`
+		Items []string `json:"items,omitempty"`
+	}
+
+	// Progression is how trainers level up. EveryLevel is given on each level up on top of the
+	// reward of the level itself.
+	Progression struct {
+		Curve      Curve
+		EveryLevel LevelReward
+		Rewards    map[int]LevelReward
+	}
+
+	ProgressionConfig struct {
+		Curve      CurveConfig         `json:"curve"`
+		EveryLevel LevelReward         `json:"every_level"`
+		Rewards    map[int]LevelReward `json:"rewards"`
+	}
+)
+
+// DefaultProgression is used by CalculateLevel, GetMinXpForLevel and the trainer database. It gives
+// no rewards, so services that reward level ups replace it on startup with one loaded from their
+// config.
+var DefaultProgression = &Progression{
+	Curve: QuadraticCurve{Factor: 1, Max: DefaultMaxLevel},
+}
+
+func LoadProgression(filename string) (*Progression, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, wrapLoadProgressionError(err, filename)
+	}
+
+	var config ProgressionConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, wrapLoadProgressionError(err, filename)
+	}
+
+	progression, err := NewProgression(config)
+	if err != nil {
+		return nil, wrapLoadProgressionError(err, filename)
+	}
+
+	return progression, nil
+}
+
+func NewProgression(config ProgressionConfig) (*Progression, error) {
+	curve, err := NewCurve(config.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	for level, reward := range config.Rewards {
+		if level <= 0 || level > curve.MaxLevel() || reward.Coins < 0 {
+			return nil, ErrorInvalidReward
+		}
+	}
+
+	if config.EveryLevel.Coins < 0 {
+		return nil, ErrorInvalidReward
+	}
+
+	return &Progression{
+		Curve:      curve,
+		EveryLevel: config.EveryLevel,
+		Rewards:    config.Rewards,
+	}, nil
+}
+
+// RewardsFor adds up the rewards of the levels reached when going from xp to xp+gain
+func (p *Progression) RewardsFor(xp, gain float64) ([]int, LevelReward) {
+	levels := LevelUps(p.Curve, xp, gain)
+
+	total := LevelReward{}
+	for _, level := range levels {
+		for _, reward := range []LevelReward{p.EveryLevel, p.Rewards[level]} {
+			total.Coins += reward.Coins
+			total.Items = append(total.Items, reward.Items...)
+		}
+	}
+
+	return levels, total
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const geoJSONPointType = "Point"
+
+type (
+	// GeoJSONPoint is how locations are stored in MongoDB, so they can be queried with a 2dsphere
+	// index. Coordinates are the longitude and the latitude, in degrees.
+	GeoJSONPoint struct {
+		Type        string    `json:"type" bson:"type"`
+		Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+	}
+
+	// storedLocation reads GeoJSON points as well as the documents locations were stored as before,
+	// with the latitude and longitude in radians
+	storedLocation struct {
+		GeoJSONPoint `bson:",inline"`
+		Lat          float64 `bson:"lat,omitempty"`
+		Lng          float64 `bson:"lng,omitempty"`
+	}
+
+	gymDocument struct {
+		Name        string            `bson:"name,omitempty"`
+		Location    storedLocation    `bson:"location"`
+		RaidForming bool              `bson:"raidforming"`
+		RaidBoss    *pokemons.Pokemon `bson:"raidboss"`
+	}
+
+	wildPokemonDocument struct {
+		Pokemon  pokemons.Pokemon `bson:"pokemon"`
+		Location storedLocation   `bson:"location"`
+		Server   string           `bson:"server"`
+	}
+)
+
+func NewGeoJSONPoint(location s2.LatLng) GeoJSONPoint {
+	return GeoJSONPoint{
+		Type:        geoJSONPointType,
+		Coordinates: []float64{location.Lng.Degrees(), location.Lat.Degrees()},
+	}
+}
+
+func (point GeoJSONPoint) LatLng() s2.LatLng {
+	if len(point.Coordinates) < 2 {
+		return s2.LatLng{}
+	}
+
+	return s2.LatLngFromDegrees(point.Coordinates[1], point.Coordinates[0])
+}
+
+func (location storedLocation) LatLng() s2.LatLng {
+	if location.Type == "" {
+		return s2.LatLng{Lat: s1.Angle(location.Lat), Lng: s1.Angle(location.Lng)}
+	}
+
+	return location.GeoJSONPoint.LatLng()
+}
+
+// MarshalBSON stores the gym's location as a GeoJSON point
+func (gym Gym) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(gymDocument{
+		Name:        gym.Name,
+		Location:    storedLocation{GeoJSONPoint: NewGeoJSONPoint(gym.Location)},
+		RaidForming: gym.RaidForming,
+		RaidBoss:    gym.RaidBoss,
+	})
+}
+
+// UnmarshalBSON reads gyms stored with either kind of location
+func (gym *Gym) UnmarshalBSON(data []byte) error {
+	var document gymDocument
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	*gym = Gym{
+		Name:        document.Name,
+		Location:    document.Location.LatLng(),
+		RaidForming: document.RaidForming,
+		RaidBoss:    document.RaidBoss,
+	}
+
+	return nil
+}
+
+// MarshalBSON stores the pokemon's location as a GeoJSON point
+func (wildPokemon WildPokemonWithServer) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(wildPokemonDocument{
+		Pokemon:  wildPokemon.Pokemon,
+		Location: storedLocation{GeoJSONPoint: NewGeoJSONPoint(wildPokemon.Location)},
+		Server:   wildPokemon.Server,
+	})
+}
+
+func (wildPokemon *WildPokemonWithServer) UnmarshalBSON(data []byte) error {
+	var document wildPokemonDocument
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	*wildPokemon = WildPokemonWithServer{
+		Pokemon:  document.Pokemon,
+		Location: document.Location.LatLng(),
+		Server:   document.Server,
+	}
+
+	return nil
+}
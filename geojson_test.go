@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var lisbon = s2.LatLngFromDegrees(38.7223, -9.1393)
+
+func TestGeoJSONPoint(t *testing.T) {
+	point := NewGeoJSONPoint(lisbon)
+	assert.Equal(t, "Point", point.Type)
+	assert.InDeltaSlice(t, []float64{-9.1393, 38.7223}, point.Coordinates, 1e-9)
+	assert.InDelta(t, lisbon.Lat.Degrees(), point.LatLng().Lat.Degrees(), 1e-9)
+	assert.InDelta(t, lisbon.Lng.Degrees(), point.LatLng().Lng.Degrees(), 1e-9)
+}
+
+func TestGymBSON(t *testing.T) {
+	gymWithServer := GymWithServer{
+		Gym:        Gym{Name: "gym", Location: lisbon, RaidBoss: &pokemons.Pokemon{Id: "boss", Species: "mewtwo"}},
+		ServerName: "location-0",
+	}
+
+	data, err := bson.Marshal(gymWithServer)
+	assert.NoError(t, err)
+
+	var raw bson.M
+	assert.NoError(t, bson.Unmarshal(data, &raw))
+	location := raw["gym"].(bson.M)["location"].(bson.M)
+	assert.Equal(t, "Point", location["type"])
+
+	var decoded GymWithServer
+	assert.NoError(t, bson.Unmarshal(data, &decoded))
+	assert.Equal(t, gymWithServer.Gym.Name, decoded.Gym.Name)
+	assert.Equal(t, gymWithServer.ServerName, decoded.ServerName)
+	assert.Equal(t, gymWithServer.Gym.RaidBoss.Id, decoded.Gym.RaidBoss.Id)
+	assert.InDelta(t, lisbon.Lat.Degrees(), decoded.Gym.Location.Lat.Degrees(), 1e-9)
+	assert.InDelta(t, lisbon.Lng.Degrees(), decoded.Gym.Location.Lng.Degrees(), 1e-9)
+}
+
+func TestWildPokemonBSON(t *testing.T) {
+	wildPokemon := WildPokemonWithServer{
+		Pokemon:  pokemons.Pokemon{Id: "wild", Species: "pidgey"},
+		Location: lisbon,
+		Server:   "location-0",
+	}
+
+	data, err := bson.Marshal(wildPokemon)
+	assert.NoError(t, err)
+
+	var decoded WildPokemonWithServer
+	assert.NoError(t, bson.Unmarshal(data, &decoded))
+	assert.Equal(t, wildPokemon.Pokemon.Id, decoded.Pokemon.Id)
+	assert.Equal(t, wildPokemon.Server, decoded.Server)
+	assert.InDelta(t, lisbon.Lat.Degrees(), decoded.Location.Lat.Degrees(), 1e-9)
+}
+
+func TestLegacyLocationBSON(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"gym":        bson.M{"name": "gym", "location": bson.M{"lat": lisbon.Lat.Radians(), "lng": lisbon.Lng.Radians()}},
+		"servername": "location-0",
+	})
+	assert.NoError(t, err)
+
+	var decoded GymWithServer
+	assert.NoError(t, bson.Unmarshal(data, &decoded))
+	assert.Equal(t, "gym", decoded.Gym.Name)
+	assert.InDelta(t, lisbon.Lat.Degrees(), decoded.Gym.Location.Lat.Degrees(), 1e-9)
+	assert.InDelta(t, lisbon.Lng.Degrees(), decoded.Gym.Location.Lng.Degrees(), 1e-9)
+
+	// gyms are written back as GeoJSON
+	data, err = bson.Marshal(decoded.Gym)
+	assert.NoError(t, err)
+
+	var raw bson.M
+	assert.NoError(t, bson.Unmarshal(data, &raw))
+	assert.Equal(t, bson.M{"type": "Point", "coordinates": bson.A{lisbon.Lng.Degrees(), lisbon.Lat.Degrees()}},
+		raw["location"])
+}
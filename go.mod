@@ -14,4 +14,5 @@ require (
 	github.com/stretchr/testify v1.4.0
 	github.com/ungerik/go-dry v0.0.0-20210209114055-a3e162a9e62e
 	go.mongodb.org/mongo-driver v1.3.1
+	gopkg.in/yaml.v2 v2.2.5
 )
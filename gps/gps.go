@@ -3,17 +3,92 @@ package gps
 import (
 	"math"
 
+	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 )
 
-// Earth radius in meters
-const earthRadius = 6378000
+// EarthRadius is the mean radius of the earth in meters
+const EarthRadius = 6371008.8
 
-// Add distance in meters to location. Both dLat and dLong are assumed to be in meters.
+// Add distance in meters to location. Both dLat and dLong are assumed to be in meters, towards
+// north and east respectively.
 func CalcLocationPlusDistanceTraveled(location s2.LatLng, dLat, dLong float64) s2.LatLng {
-	newLat := location.Lat.Degrees() + (dLat/earthRadius)*(180.0/math.Pi)
-	newLong := location.Lng.Degrees() +
-		(dLong/earthRadius)*(180.0/math.Pi)/math.Cos(location.Lat.Degrees()*math.Pi/180)
+	bearing := s1.Angle(math.Atan2(dLong, dLat))
+	return Destination(location, bearing, math.Hypot(dLat, dLong))
+}
+
+// AngleToMeters converts an angle at the center of the earth to the distance along its surface
+func AngleToMeters(angle s1.Angle) float64 {
+	return angle.Radians() * EarthRadius
+}
+
+// MetersToAngle converts a distance along the surface of the earth to an angle at its center
+func MetersToAngle(meters float64) s1.Angle {
+	return s1.Angle(meters / EarthRadius)
+}
+
+// Distance is the great circle distance between both locations in meters, using the haversine formula
+func Distance(from, to s2.LatLng) float64 {
+	lat1, lat2 := from.Lat.Radians(), to.Lat.Radians()
+	dLat := lat2 - lat1
+	dLng := (to.Lng - from.Lng).Radians()
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return AngleToMeters(s1.Angle(2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))))
+}
+
+// InitialBearing is the direction to take from one location to reach the other along a great
+// circle, clockwise from north, between 0 and 360 degrees
+func InitialBearing(from, to s2.LatLng) s1.Angle {
+	lat1, lat2 := from.Lat.Radians(), to.Lat.Radians()
+	dLng := (to.Lng - from.Lng).Radians()
+
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+
+	bearing := math.Atan2(y, x)
+	if bearing < 0 {
+		bearing += 2 * math.Pi
+	}
+
+	return s1.Angle(bearing)
+}
+
+// Destination is the location reached by travelling the distance in meters along a great circle
+// starting with the given bearing
+func Destination(from s2.LatLng, bearing s1.Angle, distance float64) s2.LatLng {
+	lat1, lng1 := from.Lat.Radians(), from.Lng.Radians()
+	angular := MetersToAngle(distance).Radians()
+	theta := bearing.Radians()
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(angular)*math.Cos(lat1),
+		math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+
+	return s2.LatLng{Lat: s1.Angle(lat2), Lng: s1.Angle(lng2)}.Normalized()
+}
+
+// Interpolate returns the location at the given fraction of the great circle between both
+// locations, where 0 is the first and 1 the second
+func Interpolate(from, to s2.LatLng, fraction float64) s2.LatLng {
+	return s2.LatLngFromPoint(s2.Interpolate(fraction, s2.PointFromLatLng(from), s2.PointFromLatLng(to)))
+}
+
+// PolygonContains tells if the location is inside the polygon with the given vertices. Edges are
+// great circle segments and the polygon is taken to be the smaller of the two regions they bound,
+// so vertices can be given in either order.
+func PolygonContains(vertices []s2.LatLng, location s2.LatLng) bool {
+	if len(vertices) < 3 {
+		return false
+	}
+
+	points := make([]s2.Point, len(vertices))
+	for i, vertex := range vertices {
+		points[i] = s2.PointFromLatLng(vertex)
+	}
+
+	loop := s2.LoopFromPoints(points)
+	loop.Normalize()
 
-	return s2.LatLngFromDegrees(newLat, newLong)
+	return loop.ContainsPoint(s2.PointFromLatLng(location))
 }
@@ -0,0 +1,75 @@
+package gps
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/assert"
+)
+
+const metersMargin = 100.
+
+var (
+	landsEnd    = s2.LatLngFromDegrees(50.066389, -5.714722)
+	johnOGroats = s2.LatLngFromDegrees(58.643889, -3.070000)
+)
+
+func TestDistance(t *testing.T) {
+	assert.InDelta(t, 968900, Distance(landsEnd, johnOGroats), metersMargin)
+	assert.InDelta(t, 968900, Distance(johnOGroats, landsEnd), metersMargin)
+	assert.Equal(t, 0., Distance(landsEnd, landsEnd))
+
+	// a quarter of the equator
+	assert.InDelta(t, 10007557, Distance(s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 90)), 1)
+}
+
+func TestInitialBearing(t *testing.T) {
+	assert.InDelta(t, 9.1198, InitialBearing(landsEnd, johnOGroats).Degrees(), 1e-3)
+	assert.InDelta(t, 270, InitialBearing(s2.LatLngFromDegrees(0, 10), s2.LatLngFromDegrees(0, 0)).Degrees(), 1e-9)
+}
+
+func TestDestination(t *testing.T) {
+	start := s2.LatLngFromDegrees(53.320556, -1.729722)
+	bearing := 96.021667 * s1.Degree
+
+	destination := Destination(start, bearing, 124800)
+	assert.InDelta(t, 53.188333, destination.Lat.Degrees(), 1e-3)
+	assert.InDelta(t, 0.133333, destination.Lng.Degrees(), 1e-3)
+
+	back := Destination(johnOGroats, InitialBearing(johnOGroats, landsEnd), Distance(johnOGroats, landsEnd))
+	assert.InDelta(t, 0, Distance(back, landsEnd), 1e-3)
+}
+
+func TestInterpolate(t *testing.T) {
+	midpoint := Interpolate(landsEnd, johnOGroats, .5)
+	assert.InDelta(t, 54.362222, midpoint.Lat.Degrees(), 1e-3)
+	assert.InDelta(t, -4.530556, midpoint.Lng.Degrees(), 1e-3)
+
+	assert.InDelta(t, 0, Distance(Interpolate(landsEnd, johnOGroats, 0), landsEnd), 1e-3)
+	assert.InDelta(t, 0, Distance(Interpolate(landsEnd, johnOGroats, 1), johnOGroats), 1e-3)
+}
+
+func TestPolygonContains(t *testing.T) {
+	square := []s2.LatLng{
+		s2.LatLngFromDegrees(38, -10),
+		s2.LatLngFromDegrees(38, -8),
+		s2.LatLngFromDegrees(40, -8),
+		s2.LatLngFromDegrees(40, -10),
+	}
+
+	assert.True(t, PolygonContains(square, s2.LatLngFromDegrees(39, -9)))
+	assert.False(t, PolygonContains(square, s2.LatLngFromDegrees(41, -9)))
+
+	reversed := []s2.LatLng{square[3], square[2], square[1], square[0]}
+	assert.True(t, PolygonContains(reversed, s2.LatLngFromDegrees(39, -9)))
+}
+
+func TestCalcLocationPlusDistanceTraveled(t *testing.T) {
+	start := s2.LatLngFromDegrees(38.7, -9.1)
+
+	moved := CalcLocationPlusDistanceTraveled(start, 300, 400)
+	assert.InDelta(t, 500, Distance(start, moved), 1e-3)
+	assert.Greater(t, moved.Lat.Degrees(), start.Lat.Degrees())
+	assert.Greater(t, moved.Lng.Degrees(), start.Lng.Degrees())
+}
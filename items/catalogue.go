@@ -0,0 +1,195 @@
+package items
+
+import (
+	"sort"
+
+	"github.com/NOVAPokemon/utils/catalogues"
+	"github.com/NOVAPokemon/utils/species"
+)
+
+// Rarities
+const (
+	Common    = "COMMON"
+	Uncommon  = "UNCOMMON"
+	Rare      = "RARE"
+	Legendary = "LEGENDARY"
+)
+
+// names the catalogue in the errors of the catalogue files
+const catalogueKind = "item"
+
+const (
+	SuperPotionName = "super-potion"
+	FullHealName    = "full-heal"
+	XAttackName     = "x-attack"
+	XDefenseName    = "x-defense"
+	GreatBallName   = "great-ball"
+)
+
+type (
+	CatalogueEntry struct {
+		Name      string            `json:"name" yaml:"name"`
+		Price     int               `json:"price" yaml:"price"`
+		Rarity    string            `json:"rarity" yaml:"rarity"`
+		Appliable bool              `json:"appliable" yaml:"appliable"`
+		MaxStack  int               `json:"max_stack,omitempty" yaml:"max_stack,omitempty"`
+		Effects   []EffectComponent `json:"effects" yaml:"effects"`
+	}
+
+	// Catalogue describes the items that exist in the game, indexed by name
+	Catalogue struct {
+		entries map[string]CatalogueEntry
+	}
+
+	catalogueFile struct {
+		Items []CatalogueEntry `json:"items" yaml:"items"`
+	}
+)
+
+// legacy ids of the items that existed before the catalogue, so stored items keep working
+var legacyIds = map[string]int{
+	HealName:       HealId,
+	ReviveName:     ReviveId,
+	PokeBallName:   PokeBallId,
+	MasterBallName: MasterBallId,
+}
+
+var DefaultCatalogue = mustNewCatalogue([]CatalogueEntry{
+	{Name: HealName, Price: 10, Rarity: Common, Appliable: true,
+		Effects: []EffectComponent{{Type: HealEffectType, Value: HealFactor}}},
+	{Name: SuperPotionName, Price: 25, Rarity: Uncommon, Appliable: true,
+		Effects: []EffectComponent{{Type: HealPercentEffectType, Value: 50}}},
+	{Name: ReviveName, Price: 50, Rarity: Rare, Appliable: true, MaxStack: 20,
+		Effects: []EffectComponent{{Type: ReviveEffectType, Value: 100}}},
+	{Name: FullHealName, Price: 20, Rarity: Uncommon, Appliable: true,
+		Effects: []EffectComponent{{Type: CureStatusEffectType}}},
+	{Name: XAttackName, Price: 30, Rarity: Uncommon, Appliable: true,
+		Effects: []EffectComponent{{Type: DamageBoostEffectType, Value: 50, Turns: 3}}},
+	{Name: XDefenseName, Price: 30, Rarity: Uncommon, Appliable: true,
+		Effects: []EffectComponent{{Type: DefenseBoostEffectType, Value: 50, Turns: 3}}},
+	{Name: PokeBallName, Price: 5, Rarity: Common,
+		Effects: []EffectComponent{{Type: CatchRateEffectType, Value: PokeBallValue}}},
+	{Name: GreatBallName, Price: 15, Rarity: Uncommon,
+		Effects: []EffectComponent{{Type: CatchRateEffectType, Value: 90}}},
+	{Name: MasterBallName, Price: 100, Rarity: Legendary, MaxStack: 5,
+		Effects: []EffectComponent{{Type: CatchRateEffectType, Value: MasterBallValue}}},
+	{Name: species.FireStoneName, Price: 60, Rarity: Rare, MaxStack: 10,
+		Effects: []EffectComponent{{Type: EvolutionEffectType}}},
+	{Name: species.WaterStoneName, Price: 60, Rarity: Rare, MaxStack: 10,
+		Effects: []EffectComponent{{Type: EvolutionEffectType}}},
+	{Name: species.ThunderStoneName, Price: 60, Rarity: Rare, MaxStack: 10,
+		Effects: []EffectComponent{{Type: EvolutionEffectType}}},
+	{Name: species.LeafStoneName, Price: 60, Rarity: Rare, MaxStack: 10,
+		Effects: []EffectComponent{{Type: EvolutionEffectType}}},
+})
+
+// LoadCatalogue reads a catalogue from a JSON or YAML file, chosen by the file extension
+func LoadCatalogue(filename string) (*Catalogue, error) {
+	var file catalogueFile
+	var catalogue *Catalogue
+
+	err := catalogues.Load(catalogueKind, filename, &file, func() (err error) {
+		catalogue, err = NewCatalogue(file.Items)
+		return err
+	})
+
+	return catalogue, err
+}
+
+// ParseCatalogue parses a catalogue in the given format, either ".json" or ".yaml"
+func ParseCatalogue(data []byte, format string) (*Catalogue, error) {
+	var file catalogueFile
+	var catalogue *Catalogue
+
+	err := catalogues.Parse(catalogueKind, data, format, &file, func() (err error) {
+		catalogue, err = NewCatalogue(file.Items)
+		return err
+	})
+
+	return catalogue, err
+}
+
+func NewCatalogue(entries []CatalogueEntry) (*Catalogue, error) {
+	catalogue := &Catalogue{entries: make(map[string]CatalogueEntry, len(entries))}
+
+	for _, entry := range entries {
+		if entry.Name == "" || entry.Price < 0 || entry.MaxStack < 0 || len(entry.Effects) == 0 {
+			return nil, ErrorInvalidCatalogueEntry
+		}
+
+		for _, component := range entry.Effects {
+			_, handled := effectHandlers[component.Type]
+			if !handled && (entry.Appliable || !consumedEffects[component.Type]) {
+				return nil, ErrorInvalidEffect
+			}
+		}
+
+		catalogue.entries[entry.Name] = entry
+	}
+
+	return catalogue, nil
+}
+
+func mustNewCatalogue(entries []CatalogueEntry) *Catalogue {
+	catalogue, err := NewCatalogue(entries)
+	if err != nil {
+		panic(err)
+	}
+
+	return catalogue
+}
+
+func (c *Catalogue) Entry(name string) (CatalogueEntry, bool) {
+	entry, ok := c.entries[name]
+	return entry, ok
+}
+
+// NewItem creates an item with the catalogue's effect. Ids are given when the item is stored.
+func (c *Catalogue) NewItem(name string) (Item, error) {
+	entry, ok := c.entries[name]
+	if !ok {
+		return Item{}, ErrorItemNotInCatalogue
+	}
+
+	effect := Effect{
+		Appliable:  entry.Appliable,
+		Id:         legacyIds[name],
+		Value:      NoValue,
+		Components: append([]EffectComponent{}, entry.Effects...),
+	}
+
+	for _, component := range entry.Effects {
+		if component.Type == CatchRateEffectType {
+			effect.Value = component.Value
+		}
+	}
+
+	return Item{Name: name, Effect: effect}, nil
+}
+
+// StoreItems lists the catalogue's items with their prices, sorted by name
+func (c *Catalogue) StoreItems() []StoreItem {
+	storeItems := make([]StoreItem, 0, len(c.entries))
+	for _, entry := range c.entries {
+		storeItems = append(storeItems, StoreItem{Name: entry.Name, Price: entry.Price})
+	}
+
+	sort.Slice(storeItems, func(i, j int) bool {
+		return storeItems[i].Name < storeItems[j].Name
+	})
+
+	return storeItems
+}
+
+// ItemsWithRarity returns the names of the items of the given rarity, sorted
+func (c *Catalogue) ItemsWithRarity(rarity string) []string {
+	var names []string
+	for name, entry := range c.entries {
+		if entry.Rarity == rarity {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,68 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlCatalogue = `
+items:
+  - name: mega-potion
+    price: 40
+    rarity: RARE
+    appliable: true
+    effects:
+      - type: HEAL_PERCENT
+        value: 50
+      - type: DAMAGE_BOOST
+        value: 20
+        turns: 2
+`
+
+func TestParseCatalogueAndApplyComposedEffect(t *testing.T) {
+	catalogue, err := ParseCatalogue([]byte(yamlCatalogue), ".yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	item, err := catalogue.NewItem("mega-potion")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pokemon := &pokemons.Pokemon{HP: 10, MaxHP: 100}
+	result, err := item.ApplyWithResult(pokemon)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, pokemon.HP)
+	assert.Equal(t, &Boost{Percent: 20, Turns: 2}, result.DamageBoost)
+	assert.Equal(t, []StoreItem{{Name: "mega-potion", Price: 40}}, catalogue.StoreItems())
+}
+
+func TestParseCatalogueRejectsUnknownEffects(t *testing.T) {
+	_, err := ParseCatalogue([]byte(`{"items":[{"name":"x","effects":[{"type":"FLY"}]}]}`), "json")
+	assert.Error(t, err)
+}
+
+func TestParseCatalogueRejectsAppliableConsumedEffects(t *testing.T) {
+	_, err := ParseCatalogue([]byte(`{"items":[{"name":"x","effects":[{"type":"CATCH_RATE","value":50}]}]}`), "json")
+	assert.NoError(t, err)
+
+	_, err = ParseCatalogue(
+		[]byte(`{"items":[{"name":"x","appliable":true,"effects":[{"type":"CATCH_RATE","value":50}]}]}`), "json")
+	assert.Equal(t, ErrorInvalidEffect, errors.Cause(err))
+}
+
+func TestLegacyItemsKeepTheirEffects(t *testing.T) {
+	pokemon := &pokemons.Pokemon{HP: 0, MaxHP: 100}
+	result, err := ReviveItem.ApplyWithResult(pokemon)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, pokemon.HP)
+	assert.True(t, result.CuredStatus)
+
+	assert.Equal(t, PokeBallValue, GetEffectForItem(PokeBallName).Value)
+	assert.Equal(t, 90, GetEffectForItem(GreatBallName).Value)
+	assert.Equal(t, ErrorNotAppliable, PokeBallItem.Apply(pokemon))
+}
@@ -19,11 +19,50 @@ const (
 	HealFactor = 100
 )
 
-type Effect struct {
-	Appliable bool
-	Id        int
-	Value     int
-}
+// Effect types. Values are absolute for HealEffectType and percentages for the others.
+const (
+	HealEffectType         = "HEAL"
+	HealPercentEffectType  = "HEAL_PERCENT"
+	ReviveEffectType       = "REVIVE"
+	DamageBoostEffectType  = "DAMAGE_BOOST"
+	DefenseBoostEffectType = "DEFENSE_BOOST"
+	CureStatusEffectType   = "CURE_STATUS"
+	CatchRateEffectType    = "CATCH_RATE"
+	EvolutionEffectType    = "EVOLUTION"
+)
+
+type (
+	Effect struct {
+		Appliable  bool
+		Id         int
+		Value      int
+		Components []EffectComponent `json:"components,omitempty" bson:"components,omitempty"`
+	}
+
+	// EffectComponent is one of the effects an item has. Turns is only used by boosts.
+	EffectComponent struct {
+		Type  string `json:"type" yaml:"type"`
+		Value int    `json:"value" yaml:"value"`
+		Turns int    `json:"turns,omitempty" yaml:"turns,omitempty"`
+	}
+
+	// Boost increases a stat by Percent for the next Turns moves of the pokemon
+	Boost struct {
+		Percent int
+		Turns   int
+	}
+
+	// EffectResult holds what applying an item did besides changing the pokemon, which the
+	// caller is responsible for, such as curing conditions or boosting the next moves
+	EffectResult struct {
+		CuredStatus  bool
+		DamageBoost  *Boost
+		DefenseBoost *Boost
+	}
+
+	// EffectHandler applies an effect component to the pokemon
+	EffectHandler func(pokemon *pokemons.Pokemon, component EffectComponent, result *EffectResult) error
+)
 
 var (
 	HealEffect       = Effect{Appliable: true, Id: HealId, Value: NoValue}
@@ -34,24 +73,75 @@ var (
 	None = Effect{Appliable: false, Id: NoId, Value: NoValue}
 )
 
-func (item *Item) Apply(pokemon *pokemons.Pokemon) error {
-	if !item.Effect.Appliable {
-		return ErrorNotAppliable
+var effectHandlers = map[string]EffectHandler{
+	HealEffectType:         healHandler,
+	HealPercentEffectType:  healPercentHandler,
+	ReviveEffectType:       reviveHandler,
+	DamageBoostEffectType:  damageBoostHandler,
+	DefenseBoostEffectType: defenseBoostHandler,
+	CureStatusEffectType:   cureStatusHandler,
+}
+
+// consumedEffects are read by the operations that use the items, such as catching or evolving a
+// pokemon, so they have no handler and items with them are not appliable
+var consumedEffects = map[string]bool{
+	CatchRateEffectType: true,
+	EvolutionEffectType: true,
+}
+
+// RegisterEffect adds or replaces the handler of an effect type. It should only be called on startup.
+func RegisterEffect(effectType string, handler EffectHandler) {
+	effectHandlers[effectType] = handler
+}
+
+// GetComponents returns the components of the effect. Effects stored before components existed
+// are described by their id.
+func (effect Effect) GetComponents() []EffectComponent {
+	if len(effect.Components) > 0 {
+		return effect.Components
 	}
 
-	switch item.Effect.Id {
+	switch effect.Id {
 	case HealId:
-		pokemon.HP += pokemon.HP + HealFactor
-		if pokemon.HP > pokemon.MaxHP {
-			pokemon.HP = pokemon.MaxHP
-		}
+		return []EffectComponent{{Type: HealEffectType, Value: HealFactor}}
 	case ReviveId:
-		pokemon.HP = pokemon.MaxHP
+		return []EffectComponent{{Type: ReviveEffectType, Value: 100}}
+	case PokeBallId, MasterBallId:
+		return []EffectComponent{{Type: CatchRateEffectType, Value: effect.Value}}
 	default:
-		return ErrorInvalidId
+		return nil
 	}
+}
 
-	return nil
+func (item *Item) Apply(pokemon *pokemons.Pokemon) error {
+	_, err := item.ApplyWithResult(pokemon)
+	return err
+}
+
+// ApplyWithResult applies every component of the item's effect through the effect registry
+func (item *Item) ApplyWithResult(pokemon *pokemons.Pokemon) (*EffectResult, error) {
+	if !item.Effect.Appliable {
+		return nil, ErrorNotAppliable
+	}
+
+	components := item.Effect.GetComponents()
+	if len(components) == 0 {
+		return nil, ErrorInvalidId
+	}
+
+	result := &EffectResult{}
+	for _, component := range components {
+		handler, ok := effectHandlers[component.Type]
+		if !ok {
+			return nil, ErrorInvalidEffect
+		}
+
+		if err := handler(pokemon, component, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
 func GetEffectForItem(itemName string) Effect {
@@ -65,6 +155,65 @@ func GetEffectForItem(itemName string) Effect {
 	case MasterBallName:
 		return MasterBallEffect
 	default:
+		if item, err := DefaultCatalogue.NewItem(itemName); err == nil {
+			return item.Effect
+		}
 		return None
 	}
 }
+
+func healHandler(pokemon *pokemons.Pokemon, component EffectComponent, _ *EffectResult) error {
+	pokemon.HP += component.Value
+	if pokemon.HP > pokemon.MaxHP {
+		pokemon.HP = pokemon.MaxHP
+	}
+
+	return nil
+}
+
+func healPercentHandler(pokemon *pokemons.Pokemon, component EffectComponent, result *EffectResult) error {
+	return healHandler(pokemon, EffectComponent{Value: percentOf(pokemon.MaxHP, component.Value)}, result)
+}
+
+func reviveHandler(pokemon *pokemons.Pokemon, component EffectComponent, result *EffectResult) error {
+	hp := percentOf(pokemon.MaxHP, component.Value)
+	if hp > pokemon.HP {
+		pokemon.HP = hp
+	}
+
+	result.CuredStatus = true
+	return nil
+}
+
+func damageBoostHandler(_ *pokemons.Pokemon, component EffectComponent, result *EffectResult) error {
+	result.DamageBoost = &Boost{Percent: component.Value, Turns: component.Turns}
+	return nil
+}
+
+func defenseBoostHandler(_ *pokemons.Pokemon, component EffectComponent, result *EffectResult) error {
+	result.DefenseBoost = &Boost{Percent: component.Value, Turns: component.Turns}
+	return nil
+}
+
+func cureStatusHandler(_ *pokemons.Pokemon, _ EffectComponent, result *EffectResult) error {
+	result.CuredStatus = true
+	return nil
+}
+
+// Factor is the multiplier the boost applies to a stat
+func (boost *Boost) Factor() float64 {
+	if boost == nil || boost.Turns <= 0 {
+		return 1
+	}
+
+	return 1 + float64(boost.Percent)/100
+}
+
+func percentOf(value, percent int) int {
+	result := value * percent / 100
+	if result < 1 && percent > 0 {
+		result = 1
+	}
+
+	return result
+}
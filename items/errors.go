@@ -1,8 +1,18 @@
 package items
 
-import "github.com/pkg/errors"
+import (
+	"github.com/NOVAPokemon/utils/catalogues"
+	"github.com/pkg/errors"
+)
 
 var (
-	ErrorNotAppliable = errors.New("item not appliable")
-	ErrorInvalidId    = errors.New("invalid item id")
+	ErrorNotAppliable          = errors.New("item not appliable")
+	ErrorInvalidId             = errors.New("invalid item id")
+	ErrorInvalidEffect         = errors.New("invalid item effect")
+	ErrorItemNotInCatalogue    = errors.New("item not in catalogue")
+	ErrorInvalidCatalogueEntry = errors.New("invalid catalogue entry")
+	ErrorUnknownCatalogueType  = catalogues.ErrorUnknownType
+	ErrorInvalidQuantity       = errors.New("invalid item quantity")
+	ErrorStackFull             = errors.New("item stack is full")
+	ErrorNotEnoughItems        = errors.New("not enough items")
 )
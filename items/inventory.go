@@ -0,0 +1,164 @@
+package items
+
+import (
+	"sort"
+)
+
+const DefaultMaxStack = 99
+
+type (
+	// ItemStack is a number of identical items. Its fields are stored with the same names an
+	// item has, so documents written before stacks existed can still be decoded.
+	ItemStack struct {
+		Name     string
+		Effect   Effect
+		Quantity int
+	}
+
+	// Inventory holds the stacks of items of a trainer, indexed by item name. Item ids in battles
+	// and trades are stack names.
+	Inventory map[string]ItemStack
+)
+
+// MaxStackFor returns how many items of the given name fit in one stack
+func MaxStackFor(name string) int {
+	if entry, ok := DefaultCatalogue.Entry(name); ok && entry.MaxStack > 0 {
+		return entry.MaxStack
+	}
+
+	return DefaultMaxStack
+}
+
+// NewInventory groups the given items in stacks
+func NewInventory(itemsToAdd ...Item) (Inventory, error) {
+	inventory := Inventory{}
+	for _, item := range itemsToAdd {
+		if err := inventory.Add(item, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return inventory, nil
+}
+
+// InventoryFromItems converts the items of a trainer stored one per entry into stacks, ignoring
+// the stack caps so no item is lost
+func InventoryFromItems(legacyItems map[string]Item) Inventory {
+	inventory := Inventory{}
+	for _, item := range legacyItems {
+		stack := inventory[item.Name]
+		stack.Name = item.Name
+		stack.Effect = item.Effect
+		stack.Quantity++
+		inventory[item.Name] = stack
+	}
+
+	return inventory
+}
+
+func (inventory Inventory) Add(item Item, quantity int) error {
+	if quantity <= 0 {
+		return ErrorInvalidQuantity
+	}
+
+	stack := inventory[item.Name]
+	if stack.Quantity+quantity > MaxStackFor(item.Name) {
+		return ErrorStackFull
+	}
+
+	stack.Name = item.Name
+	stack.Effect = item.Effect
+	stack.Quantity += quantity
+	inventory[item.Name] = stack
+
+	return nil
+}
+
+// AddUncapped adds items without checking the stack cap. It is meant for counting items, such as
+// the ones used in a battle, and not for trainers' inventories.
+func (inventory Inventory) AddUncapped(item Item, quantity int) {
+	stack := inventory[item.Name]
+	stack.Name = item.Name
+	stack.Effect = item.Effect
+	stack.Quantity += quantity
+	inventory[item.Name] = stack
+}
+
+// Take removes one item from the stack with the given name and returns it
+func (inventory Inventory) Take(name string) (Item, error) {
+	item, ok := inventory.Item(name)
+	if !ok {
+		return Item{}, ErrorNotEnoughItems
+	}
+
+	return item, inventory.Remove(name, 1)
+}
+
+func (inventory Inventory) Remove(name string, quantity int) error {
+	if quantity <= 0 {
+		return ErrorInvalidQuantity
+	}
+
+	stack, ok := inventory[name]
+	if !ok || stack.Quantity < quantity {
+		return ErrorNotEnoughItems
+	}
+
+	stack.Quantity -= quantity
+	if stack.Quantity == 0 {
+		delete(inventory, name)
+	} else {
+		inventory[name] = stack
+	}
+
+	return nil
+}
+
+func (inventory Inventory) Quantity(name string) int {
+	return inventory[name].Quantity
+}
+
+// Item returns one item of the stack, identified by the stack name
+func (inventory Inventory) Item(name string) (Item, bool) {
+	stack, ok := inventory[name]
+	if !ok || stack.Quantity <= 0 {
+		return Item{}, false
+	}
+
+	return stack.Item(), true
+}
+
+func (stack ItemStack) Item() Item {
+	return Item{Id: stack.Name, Name: stack.Name, Effect: stack.Effect}
+}
+
+// Names returns the names of the non empty stacks, sorted
+func (inventory Inventory) Names() []string {
+	names := make([]string, 0, len(inventory))
+	for name, stack := range inventory {
+		if stack.Quantity > 0 {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (inventory Inventory) Total() int {
+	total := 0
+	for _, stack := range inventory {
+		total += stack.Quantity
+	}
+
+	return total
+}
+
+func (inventory Inventory) Clone() Inventory {
+	cloned := make(Inventory, len(inventory))
+	for name, stack := range inventory {
+		cloned[name] = stack
+	}
+
+	return cloned
+}
@@ -49,7 +49,7 @@ func (storeItem StoreItem) ToItem() Item {
 
 func (item Item) IsPokeBall() bool {
 	switch item.Name {
-	case PokeBallName:
+	case PokeBallName, GreatBallName:
 		fallthrough
 	case MasterBallName:
 		return true
@@ -0,0 +1,28 @@
+package movement
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorNewModelFormat       = "error creating movement model %s"
+	errorLoadTrajectoryFormat = "error loading trajectory %s"
+)
+
+var (
+	ErrorUnknownModel      = errors.New("unknown movement model")
+	ErrorInvalidParameters = errors.New("invalid movement parameters")
+
+	ErrorInvalidTrajectory     = errors.New("invalid trajectory")
+	ErrorUnknownTrajectoryType = errors.New("unknown trajectory file type")
+)
+
+func wrapNewModelError(err error, model string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorNewModelFormat, model))
+}
+
+func wrapLoadTrajectoryError(err error, filename string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorLoadTrajectoryFormat, filename))
+}
@@ -0,0 +1,153 @@
+package movement
+
+import (
+	"math"
+	"time"
+
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+type (
+	// leg is a straight walk to the target at a constant speed, in meters per second, followed by
+	// a pause
+	leg struct {
+		target s2.LatLng
+		speed  float64
+		pause  time.Duration
+	}
+
+	legPicker interface {
+		nextLeg(from s2.LatLng) leg
+	}
+
+	// trip moves along the legs chosen by the picker, carrying the time left over after finishing
+	// a leg onto the next one
+	trip struct {
+		picker  legPicker
+		current *leg
+		pausing time.Duration
+	}
+
+	// randomWaypoint walks to random points within the bounds, at a random speed, pausing at each
+	randomWaypoint struct {
+		bounds
+		rng      random.Source
+		maxSpeed float64
+		pause    time.Duration
+	}
+
+	// randomWalk moves a random distance each update, turning at most maxTurn from its heading. It
+	// heads back to the start when it would leave the bounds. The heading is a bearing, measured
+	// clockwise from north.
+	randomWalk struct {
+		bounds
+		rng      random.Source
+		maxSpeed float64
+		maxTurn  s1.Angle
+		heading  s1.Angle
+	}
+
+	// commute goes back and forth between places, starting with the first
+	commute struct {
+		places [2]s2.LatLng
+		next   int
+		speed  float64
+		pause  time.Duration
+	}
+
+	// levyFlight flies in random directions with lengths drawn from a power law, so many short
+	// flights are mixed with a few long ones
+	levyFlight struct {
+		bounds
+		rng       random.Source
+		speed     float64
+		pause     time.Duration
+		exponent  float64
+		minFlight float64
+		maxFlight float64
+	}
+)
+
+func (t *trip) Move(location s2.LatLng, elapsed time.Duration) s2.LatLng {
+	remaining := elapsed.Seconds()
+
+	for i := 0; remaining > 0 && i < maxLegsPerMove; i++ {
+		if t.pausing > 0 {
+			if t.pausing.Seconds() >= remaining {
+				t.pausing -= time.Duration(remaining * float64(time.Second))
+				return location
+			}
+
+			remaining -= t.pausing.Seconds()
+			t.pausing = 0
+		}
+
+		if t.current == nil {
+			next := t.picker.nextLeg(location)
+			t.current = &next
+		}
+
+		if t.current.speed <= 0 {
+			return location
+		}
+
+		distance := gps.Distance(location, t.current.target)
+		if step := t.current.speed * remaining; step < distance {
+			return gps.Interpolate(location, t.current.target, step/distance)
+		}
+
+		remaining -= distance / t.current.speed
+		location = t.current.target
+		t.pausing = t.current.pause
+		t.current = nil
+	}
+
+	return location
+}
+
+func (w *randomWaypoint) nextLeg(_ s2.LatLng) leg {
+	return leg{
+		target: w.randomPoint(w.rng),
+		speed:  w.maxSpeed * (minSpeedFraction + (1-minSpeedFraction)*w.rng.Float64()),
+		pause:  w.pause,
+	}
+}
+
+func (w *randomWalk) Move(location s2.LatLng, elapsed time.Duration) s2.LatLng {
+	distance := w.rng.Float64() * w.maxSpeed * elapsed.Seconds()
+	w.heading += s1.Angle(2*w.rng.Float64()-1) * w.maxTurn
+
+	destination := gps.Destination(location, w.heading, distance)
+	if !w.contains(destination) {
+		w.heading = gps.InitialBearing(location, w.start)
+		destination = w.clamp(gps.Destination(location, w.heading, distance))
+	}
+
+	return destination
+}
+
+func (c *commute) nextLeg(_ s2.LatLng) leg {
+	target := c.places[c.next]
+	c.next = 1 - c.next
+
+	return leg{
+		target: target,
+		speed:  c.speed,
+		pause:  c.pause,
+	}
+}
+
+func (l *levyFlight) nextLeg(from s2.LatLng) leg {
+	// inverse transform sampling of a pareto distribution, 1-u keeps it away from 0
+	length := l.minFlight * math.Pow(1-l.rng.Float64(), -1/l.exponent)
+	length = math.Min(length, l.maxFlight)
+
+	return leg{
+		target: l.clamp(gps.Destination(from, randomBearing(l.rng), length)),
+		speed:  l.speed,
+		pause:  l.pause,
+	}
+}
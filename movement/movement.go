@@ -0,0 +1,190 @@
+package movement
+
+import (
+	"math"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// Movement models
+const (
+	RandomWaypoint = "random_waypoint"
+	RandomWalk     = "random_walk"
+	Commute        = "commute"
+	LevyFlight     = "levy_flight"
+	// TrajectoryPlayback follows a recorded trajectory
+	TrajectoryPlayback = "trajectory"
+)
+
+const (
+	// waypoint travellers pick a speed between this fraction of the max speed and the max speed,
+	// so they don't spend most of their time crawling towards far away waypoints
+	minSpeedFraction = .1
+
+	defaultLevyExponent = 1.5
+	maxLevyExponent     = 2.
+	defaultMinFlight    = 10.
+	// flights of unbounded travellers are cut at this length, in meters
+	maxUnboundedFlight = 50000.
+
+	// guards against spinning on legs that take no time, e.g. commuting to work from work
+	maxLegsPerMove = 100
+)
+
+// MovementModel moves a simulated trainer. Models keep state between moves, like the waypoint
+// they are heading to, so each trainer needs its own.
+type MovementModel interface {
+	// Move returns where the trainer is after moving for the given time from the location
+	Move(location s2.LatLng, elapsed time.Duration) s2.LatLng
+}
+
+// New creates the model chosen in the parameters for a trainer starting at the given location,
+// playing back the trajectory when one is given without a model. Models never move faster than
+// MaxMovingSpeed nor get farther than MaxDistanceFromStart from the start, except for playbacks,
+// which follow the recording. A nil source means the global one.
+func New(params utils.LocationParameters, start s2.LatLng, rng random.Source) (MovementModel, error) {
+	name := params.Movement.Model
+	if name == "" && params.Movement.Trajectory != "" {
+		name = TrajectoryPlayback
+	} else if name == "" {
+		name = RandomWalk
+	}
+
+	model, err := newModel(name, params, start, random.OrGlobal(rng))
+	if err != nil {
+		return nil, wrapNewModelError(err, name)
+	}
+
+	return model, nil
+}
+
+func newModel(name string, params utils.LocationParameters, start s2.LatLng,
+	rng random.Source) (MovementModel, error) {
+	movement := params.Movement
+	if params.MaxMovingSpeed < 0 || params.MaxDistanceFromStart < 0 || movement.PauseTime < 0 {
+		return nil, errors.Wrap(ErrorInvalidParameters, "negative speed, distance or pause")
+	}
+
+	b := bounds{start: start, radius: float64(params.MaxDistanceFromStart)}
+	pause := time.Duration(movement.PauseTime) * time.Second
+
+	switch name {
+	case RandomWaypoint:
+		if !b.bounded() {
+			return nil, errors.Wrap(ErrorInvalidParameters, "waypoints need a max distance from start")
+		}
+
+		return &trip{picker: &randomWaypoint{bounds: b, rng: rng, maxSpeed: params.MaxMovingSpeed,
+			pause: pause}}, nil
+	case RandomWalk:
+		maxTurn := movement.MaxTurnAngle
+		if maxTurn < 0 || maxTurn > 180 {
+			return nil, errors.Wrap(ErrorInvalidParameters, "max turn angle must be between 0 and 180")
+		} else if maxTurn == 0 {
+			maxTurn = 180
+		}
+
+		return &randomWalk{
+			bounds:   b,
+			rng:      rng,
+			maxSpeed: params.MaxMovingSpeed,
+			maxTurn:  s1.Angle(maxTurn) * s1.Degree,
+			heading:  randomBearing(rng),
+		}, nil
+	case Commute:
+		home := start
+		if movement.Home != nil {
+			home = s2.LatLngFromDegrees(movement.Home.Lat, movement.Home.Lng)
+		}
+
+		if movement.Work == nil {
+			return nil, errors.Wrap(ErrorInvalidParameters, "commuters need a work location")
+		}
+
+		work := s2.LatLngFromDegrees(movement.Work.Lat, movement.Work.Lng)
+		if !b.contains(home) || !b.contains(work) {
+			return nil, errors.Wrap(ErrorInvalidParameters, "home and work must be within the max distance from start")
+		}
+
+		return &trip{picker: &commute{places: [2]s2.LatLng{work, home}, speed: params.MaxMovingSpeed,
+			pause: pause}}, nil
+	case LevyFlight:
+		exponent := movement.LevyExponent
+		if exponent == 0 {
+			exponent = defaultLevyExponent
+		} else if exponent < 0 || exponent > maxLevyExponent {
+			return nil, errors.Wrap(ErrorInvalidParameters, "levy exponent must be between 0 and 2")
+		}
+
+		minFlight := movement.MinFlight
+		if minFlight == 0 {
+			minFlight = defaultMinFlight
+		} else if minFlight < 0 {
+			return nil, errors.Wrap(ErrorInvalidParameters, "negative min flight")
+		}
+
+		maxFlight := maxUnboundedFlight
+		if b.bounded() {
+			maxFlight = 2 * b.radius
+		}
+
+		return &trip{picker: &levyFlight{bounds: b, rng: rng, speed: params.MaxMovingSpeed, pause: pause,
+			exponent: exponent, minFlight: minFlight, maxFlight: maxFlight}}, nil
+	case TrajectoryPlayback:
+		if movement.TimeScale < 0 {
+			return nil, errors.Wrap(ErrorInvalidParameters, "negative time scale")
+		}
+
+		trajectory, err := LoadTrajectory(movement.Trajectory)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPlayback(trajectory, movement.Loop, movement.TimeScale), nil
+	default:
+		return nil, ErrorUnknownModel
+	}
+}
+
+// bounds is the disc around the start trainers must stay in, unbounded when the radius is 0
+type bounds struct {
+	start  s2.LatLng
+	radius float64
+}
+
+func (b bounds) bounded() bool {
+	return b.radius > 0
+}
+
+func (b bounds) contains(location s2.LatLng) bool {
+	return !b.bounded() || gps.Distance(b.start, location) <= b.radius
+}
+
+// clamp pulls locations outside the bounds back to their edge, towards the start
+func (b bounds) clamp(location s2.LatLng) s2.LatLng {
+	if !b.bounded() {
+		return location
+	}
+
+	distance := gps.Distance(b.start, location)
+	if distance <= b.radius {
+		return location
+	}
+
+	return gps.Interpolate(b.start, location, b.radius/distance)
+}
+
+// randomPoint is uniformly distributed over the bounds, which must be bounded
+func (b bounds) randomPoint(rng random.Source) s2.LatLng {
+	return gps.Destination(b.start, randomBearing(rng), b.radius*math.Sqrt(rng.Float64()))
+}
+
+func randomBearing(rng random.Source) s1.Angle {
+	return s1.Angle(rng.Float64() * 2 * math.Pi)
+}
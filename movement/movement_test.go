@@ -0,0 +1,138 @@
+package movement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	updateInterval = 10 * time.Second
+	updates        = 1000
+	metersMargin   = 1e-3
+)
+
+var start = s2.LatLngFromDegrees(38.7, -9.1)
+
+func paramsFor(model string) utils.LocationParameters {
+	return utils.LocationParameters{
+		MaxMovingSpeed:       5,
+		MaxDistanceFromStart: 2000,
+		Movement: utils.MovementParameters{
+			Model:     model,
+			PauseTime: 15,
+			Work:      &utils.Coord{Lat: 38.71, Lng: -9.1},
+		},
+	}
+}
+
+func TestModelsRespectSpeedAndDistance(t *testing.T) {
+	for _, model := range []string{RandomWaypoint, RandomWalk, Commute, LevyFlight} {
+		params := paramsFor(model)
+
+		movementModel, err := New(params, start, random.New(1))
+		if !assert.NoError(t, err, model) {
+			continue
+		}
+
+		location, moved := start, 0.
+		for i := 0; i < updates; i++ {
+			next := movementModel.Move(location, updateInterval)
+
+			step := gps.Distance(location, next)
+			assert.LessOrEqual(t, step, params.MaxMovingSpeed*updateInterval.Seconds()+metersMargin, model)
+			assert.LessOrEqual(t, gps.Distance(start, next), float64(params.MaxDistanceFromStart)+metersMargin,
+				model)
+
+			location, moved = next, moved+step
+		}
+
+		assert.Greater(t, moved, 0., model)
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	movementModel, err := New(utils.LocationParameters{MaxMovingSpeed: 1}, start, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &randomWalk{}, movementModel)
+}
+
+func TestRandomWalkTurnAngle(t *testing.T) {
+	params := paramsFor(RandomWalk)
+	params.MaxDistanceFromStart = 0
+	params.Movement.MaxTurnAngle = 10
+
+	movementModel, err := New(params, start, random.New(1))
+	assert.NoError(t, err)
+
+	location := start
+	previous := movementModel.(*randomWalk).heading
+	for i := 0; i < updates; i++ {
+		location = movementModel.Move(location, updateInterval)
+
+		heading := movementModel.(*randomWalk).heading
+		assert.InDelta(t, previous.Degrees(), heading.Degrees(), 10+1e-9)
+		previous = heading
+	}
+}
+
+func TestCommuteAlternates(t *testing.T) {
+	params := paramsFor(Commute)
+	work := s2.LatLngFromDegrees(params.Movement.Work.Lat, params.Movement.Work.Lng)
+	commuteTime := time.Duration(gps.Distance(start, work)/params.MaxMovingSpeed) * time.Second
+
+	movementModel, err := New(params, start, nil)
+	assert.NoError(t, err)
+
+	atWork := movementModel.Move(start, commuteTime+time.Second)
+	assert.InDelta(t, 0, gps.Distance(atWork, work), metersMargin)
+
+	// still pausing at work
+	assert.Equal(t, atWork, movementModel.Move(atWork, 10*time.Second))
+
+	atHome := movementModel.Move(atWork, commuteTime+10*time.Second)
+	assert.InDelta(t, 0, gps.Distance(atHome, start), metersMargin)
+}
+
+func TestSameSeedSamePath(t *testing.T) {
+	for _, model := range []string{RandomWaypoint, RandomWalk, LevyFlight} {
+		first, err := New(paramsFor(model), start, random.New(7))
+		assert.NoError(t, err)
+		second, err := New(paramsFor(model), start, random.New(7))
+		assert.NoError(t, err)
+
+		firstLocation, secondLocation := start, start
+		for i := 0; i < updates; i++ {
+			firstLocation = first.Move(firstLocation, updateInterval)
+			secondLocation = second.Move(secondLocation, updateInterval)
+		}
+
+		assert.Equal(t, firstLocation, secondLocation, model)
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	_, err := New(paramsFor("teleport"), start, nil)
+	assert.Equal(t, ErrorUnknownModel, errors.Cause(err))
+
+	params := paramsFor(RandomWaypoint)
+	params.MaxDistanceFromStart = 0
+	_, err = New(params, start, nil)
+	assert.Equal(t, ErrorInvalidParameters, errors.Cause(err))
+
+	params = paramsFor(Commute)
+	params.Movement.Work = &utils.Coord{Lat: 40, Lng: -9.1}
+	_, err = New(params, start, nil)
+	assert.Equal(t, ErrorInvalidParameters, errors.Cause(err))
+
+	params = paramsFor(LevyFlight)
+	params.Movement.LevyExponent = 3
+	_, err = New(params, start, nil)
+	assert.Equal(t, ErrorInvalidParameters, errors.Cause(err))
+}
@@ -0,0 +1,261 @@
+package movement
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+const geoJSONLineString = "LineString"
+
+type (
+	// TrackPoint is a recorded location and when the trainer was there
+	TrackPoint struct {
+		Location s2.LatLng
+		Time     time.Time
+	}
+
+	// Trajectory is a recorded path with its points in chronological order
+	Trajectory struct {
+		Points []TrackPoint
+	}
+
+	// Playback follows a trajectory, ignoring the location it is given. TimeScale speeds it up,
+	// when above 1, or slows it down. When looping it starts over after the last point,
+	// otherwise it stays there.
+	Playback struct {
+		trajectory *Trajectory
+		loop       bool
+		timeScale  float64
+		offset     time.Duration
+	}
+
+	gpxFile struct {
+		Tracks []struct {
+			Segments []struct {
+				Points []gpxPoint `xml:"trkpt"`
+			} `xml:"trkseg"`
+		} `xml:"trk"`
+		Routes []struct {
+			Points []gpxPoint `xml:"rtept"`
+		} `xml:"rte"`
+	}
+
+	gpxPoint struct {
+		Lat  float64   `xml:"lat,attr"`
+		Lon  float64   `xml:"lon,attr"`
+		Time time.Time `xml:"time"`
+	}
+
+	geoJSONObject struct {
+		Type       string           `json:"type"`
+		Geometry   *geoJSONObject   `json:"geometry"`
+		Features   []*geoJSONObject `json:"features"`
+		Properties struct {
+			CoordTimes []time.Time `json:"coordTimes"`
+			Times      []time.Time `json:"times"`
+		} `json:"properties"`
+		Coordinates [][]float64 `json:"coordinates"`
+	}
+)
+
+// LoadTrajectory reads a trajectory from a GPX or GeoJSON file, chosen by the file extension
+func LoadTrajectory(filename string) (*Trajectory, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, wrapLoadTrajectoryError(err, filename)
+	}
+
+	var trajectory *Trajectory
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		trajectory, err = ParseGPX(data)
+	case ".geojson", ".json":
+		trajectory, err = ParseGeoJSON(data)
+	default:
+		err = ErrorUnknownTrajectoryType
+	}
+
+	if err != nil {
+		return nil, wrapLoadTrajectoryError(err, filename)
+	}
+
+	return trajectory, nil
+}
+
+// ParseGPX reads the points of every track, or of every route when there are no tracks. Every
+// point needs a time.
+func ParseGPX(data []byte) (*Trajectory, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	var points []gpxPoint
+	for _, track := range file.Tracks {
+		for _, segment := range track.Segments {
+			points = append(points, segment.Points...)
+		}
+	}
+
+	if len(points) == 0 {
+		for _, route := range file.Routes {
+			points = append(points, route.Points...)
+		}
+	}
+
+	trajectory := &Trajectory{Points: make([]TrackPoint, len(points))}
+	for i, point := range points {
+		trajectory.Points[i] = TrackPoint{
+			Location: s2.LatLngFromDegrees(point.Lat, point.Lon),
+			Time:     point.Time,
+		}
+	}
+
+	return trajectory, trajectory.validate()
+}
+
+// ParseGeoJSON reads the first LineString, either on its own or as a feature. Times come from the
+// coordTimes or times properties of the feature or, when those are missing, from a fourth
+// coordinate with the unix time in seconds.
+func ParseGeoJSON(data []byte) (*Trajectory, error) {
+	var object geoJSONObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	feature, lineString := findLineString(&object)
+	if lineString == nil {
+		return nil, errors.Wrap(ErrorInvalidTrajectory, "missing line string")
+	}
+
+	var times []time.Time
+	if feature != nil {
+		times = feature.Properties.CoordTimes
+		if len(times) == 0 {
+			times = feature.Properties.Times
+		}
+	}
+
+	if len(times) > 0 && len(times) != len(lineString.Coordinates) {
+		return nil, errors.Wrap(ErrorInvalidTrajectory, "times don't match coordinates")
+	}
+
+	trajectory := &Trajectory{Points: make([]TrackPoint, len(lineString.Coordinates))}
+	for i, coordinates := range lineString.Coordinates {
+		if len(coordinates) < 2 {
+			return nil, errors.Wrap(ErrorInvalidTrajectory, "coordinates need a longitude and latitude")
+		}
+
+		point := TrackPoint{Location: s2.LatLngFromDegrees(coordinates[1], coordinates[0])}
+		if len(times) > 0 {
+			point.Time = times[i]
+		} else if len(coordinates) >= 4 {
+			seconds := coordinates[3]
+			point.Time = time.Unix(0, int64(seconds*float64(time.Second)))
+		}
+
+		trajectory.Points[i] = point
+	}
+
+	return trajectory, trajectory.validate()
+}
+
+func findLineString(object *geoJSONObject) (feature, lineString *geoJSONObject) {
+	switch {
+	case object.Type == geoJSONLineString:
+		return nil, object
+	case object.Geometry != nil && object.Geometry.Type == geoJSONLineString:
+		return object, object.Geometry
+	}
+
+	for _, child := range object.Features {
+		if feature, lineString = findLineString(child); lineString != nil {
+			return feature, lineString
+		}
+	}
+
+	return nil, nil
+}
+
+func (t *Trajectory) validate() error {
+	if len(t.Points) == 0 {
+		return errors.Wrap(ErrorInvalidTrajectory, "no points")
+	}
+
+	for i, point := range t.Points {
+		if point.Time.IsZero() {
+			return errors.Wrap(ErrorInvalidTrajectory, "points need a time")
+		}
+
+		if i > 0 && point.Time.Before(t.Points[i-1].Time) {
+			return errors.Wrap(ErrorInvalidTrajectory, "points out of order")
+		}
+	}
+
+	return nil
+}
+
+// Duration is the time between the first and the last point
+func (t *Trajectory) Duration() time.Duration {
+	return t.Points[len(t.Points)-1].Time.Sub(t.Points[0].Time)
+}
+
+// At interpolates where the trainer was the given time after the first point, staying at the
+// ends of the trajectory outside of it
+func (t *Trajectory) At(offset time.Duration) s2.LatLng {
+	at := t.Points[0].Time.Add(offset)
+
+	// index of the first point after the time
+	next := sort.Search(len(t.Points), func(i int) bool {
+		return t.Points[i].Time.After(at)
+	})
+
+	switch next {
+	case 0:
+		return t.Points[0].Location
+	case len(t.Points):
+		return t.Points[len(t.Points)-1].Location
+	}
+
+	from, to := t.Points[next-1], t.Points[next]
+	fraction := float64(at.Sub(from.Time)) / float64(to.Time.Sub(from.Time))
+
+	return gps.Interpolate(from.Location, to.Location, fraction)
+}
+
+// NewPlayback plays the trajectory from its first point. A time scale of 0 plays it in real time.
+func NewPlayback(trajectory *Trajectory, loop bool, timeScale float64) *Playback {
+	if timeScale == 0 {
+		timeScale = 1
+	}
+
+	return &Playback{
+		trajectory: trajectory,
+		loop:       loop,
+		timeScale:  timeScale,
+	}
+}
+
+// Start is the first point of the trajectory, where trainers following it should start
+func (p *Playback) Start() s2.LatLng {
+	return p.trajectory.Points[0].Location
+}
+
+func (p *Playback) Move(_ s2.LatLng, elapsed time.Duration) s2.LatLng {
+	p.offset += time.Duration(float64(elapsed) * p.timeScale)
+
+	if duration := p.trajectory.Duration(); p.loop && duration > 0 {
+		p.offset %= duration
+	}
+
+	return p.trajectory.At(p.offset)
+}
@@ -0,0 +1,130 @@
+package movement
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/gps"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	gpxTrajectory = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <trkseg>
+      <trkpt lat="38.70" lon="-9.10"><time>2020-06-01T10:00:00Z</time></trkpt>
+      <trkpt lat="38.71" lon="-9.10"><time>2020-06-01T10:01:40Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="38.71" lon="-9.11"><time>2020-06-01T10:03:20Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	geoJSONTrajectory = `{
+  "type": "FeatureCollection",
+  "features": [{
+    "type": "Feature",
+    "properties": {
+      "coordTimes": ["2020-06-01T10:00:00Z", "2020-06-01T10:01:40Z", "2020-06-01T10:03:20Z"]
+    },
+    "geometry": {
+      "type": "LineString",
+      "coordinates": [[-9.10, 38.70], [-9.10, 38.71], [-9.11, 38.71]]
+    }
+  }]
+}`
+
+	geoJSONUnixTimes = `{
+  "type": "LineString",
+  "coordinates": [[-9.10, 38.70, 0, 1590998400], [-9.10, 38.71, 0, 1590998500], [-9.11, 38.71, 0, 1590998600]]
+}`
+)
+
+var trajectoryPoints = []s2.LatLng{
+	s2.LatLngFromDegrees(38.70, -9.10),
+	s2.LatLngFromDegrees(38.71, -9.10),
+	s2.LatLngFromDegrees(38.71, -9.11),
+}
+
+func assertTrajectoryPoints(t *testing.T, trajectory *Trajectory) {
+	if !assert.Len(t, trajectory.Points, len(trajectoryPoints)) {
+		return
+	}
+
+	for i, point := range trajectory.Points {
+		assert.InDelta(t, 0, gps.Distance(trajectoryPoints[i], point.Location), metersMargin)
+	}
+
+	assert.Equal(t, 200*time.Second, trajectory.Duration())
+}
+
+func TestParseTrajectories(t *testing.T) {
+	trajectory, err := ParseGPX([]byte(gpxTrajectory))
+	assert.NoError(t, err)
+	assertTrajectoryPoints(t, trajectory)
+
+	trajectory, err = ParseGeoJSON([]byte(geoJSONTrajectory))
+	assert.NoError(t, err)
+	assertTrajectoryPoints(t, trajectory)
+
+	trajectory, err = ParseGeoJSON([]byte(geoJSONUnixTimes))
+	assert.NoError(t, err)
+	assertTrajectoryPoints(t, trajectory)
+
+	_, err = ParseGeoJSON([]byte(`{"type": "LineString", "coordinates": [[-9.10, 38.70]]}`))
+	assert.Equal(t, ErrorInvalidTrajectory, errors.Cause(err))
+}
+
+func TestTrajectoryAt(t *testing.T) {
+	trajectory, err := ParseGPX([]byte(gpxTrajectory))
+	assert.NoError(t, err)
+
+	halfway := gps.Interpolate(trajectoryPoints[0], trajectoryPoints[1], .5)
+	assert.InDelta(t, 0, gps.Distance(halfway, trajectory.At(50*time.Second)), metersMargin)
+
+	assert.Equal(t, trajectory.Points[0].Location, trajectory.At(-time.Second))
+	assert.Equal(t, trajectory.Points[2].Location, trajectory.At(time.Hour))
+}
+
+func TestPlayback(t *testing.T) {
+	trajectory, err := ParseGPX([]byte(gpxTrajectory))
+	assert.NoError(t, err)
+
+	playback := NewPlayback(trajectory, false, 2)
+	assert.Equal(t, trajectory.Points[0].Location, playback.Start())
+	assert.InDelta(t, 0, gps.Distance(trajectoryPoints[1], playback.Move(s2.LatLng{}, 50*time.Second)),
+		metersMargin)
+	assert.InDelta(t, 0, gps.Distance(trajectoryPoints[2], playback.Move(s2.LatLng{}, time.Hour)), metersMargin)
+
+	looping := NewPlayback(trajectory, true, 0)
+	assert.InDelta(t, 0, gps.Distance(trajectoryPoints[1], looping.Move(s2.LatLng{}, 300*time.Second)),
+		metersMargin)
+}
+
+func TestNewPlaybackFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trajectory")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "walk.gpx")
+	assert.NoError(t, ioutil.WriteFile(filename, []byte(gpxTrajectory), 0644))
+
+	params := utils.LocationParameters{Movement: utils.MovementParameters{Trajectory: filename}}
+	model, err := New(params, start, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &Playback{}, model)
+
+	params.Movement.Trajectory = filepath.Join(dir, "walk.kml")
+	_, err = New(params, start, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package pokemons
+
+import (
+	"github.com/NOVAPokemon/utils/species"
+)
+
+// Evolve turns the pokemon into the species it evolves into at its level with the given item,
+// which is empty for level based evolutions. Stats grow with the change in base stats and the
+// pokemon keeps the same fraction of its HP.
+func (pokemon *Pokemon) Evolve(catalogue *species.Catalogue, itemName string) (species.Evolution, error) {
+	evolution, err := catalogue.EvolutionFor(pokemon.Species, pokemon.Level, itemName)
+	if err != nil {
+		return species.Evolution{}, err
+	}
+
+	from, _ := catalogue.Get(pokemon.Species)
+	into, _ := catalogue.Get(evolution.Into)
+
+	maxHP := evolveStat(pokemon.MaxHP, from.BaseStats.HP, into.BaseStats.HP)
+	if pokemon.MaxHP > 0 {
+		pokemon.HP = pokemon.HP * maxHP / pokemon.MaxHP
+	}
+	pokemon.MaxHP = maxHP
+
+	pokemon.Damage = evolveStat(pokemon.Damage, from.BaseStats.Damage, into.BaseStats.Damage)
+	pokemon.Speed = evolveStat(pokemon.Speed, from.BaseStats.Speed, into.BaseStats.Speed)
+	pokemon.Defense = evolveStat(pokemon.Defense, from.BaseStats.Defense, into.BaseStats.Defense)
+	pokemon.Species = evolution.Into
+
+	return evolution, nil
+}
+
+func evolveStat(stat, fromBase, intoBase int) int {
+	if stat <= 0 {
+		return stat
+	}
+
+	return stat * intoBase / fromBase
+}
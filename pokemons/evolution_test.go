@@ -0,0 +1,30 @@
+package pokemons
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/species"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvolveScalesStats(t *testing.T) {
+	pokemon := &Pokemon{Species: "charmander", Level: 16, HP: 40, MaxHP: 80, Damage: 95, Speed: 105, Defense: 80}
+
+	evolution, err := pokemon.Evolve(species.DefaultCatalogue, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "charmeleon", evolution.Into)
+	assert.Equal(t, "charmeleon", pokemon.Species)
+	assert.Equal(t, 100, pokemon.MaxHP)
+	assert.Equal(t, 50, pokemon.HP)
+	assert.Equal(t, 115, pokemon.Damage)
+	assert.Equal(t, 120, pokemon.Speed)
+	assert.Equal(t, 100, pokemon.Defense)
+}
+
+func TestEvolveRequiresLevel(t *testing.T) {
+	pokemon := &Pokemon{Species: "charmander", Level: 15, MaxHP: 80}
+
+	_, err := pokemon.Evolve(species.DefaultCatalogue, "")
+	assert.Equal(t, species.ErrorCannotEvolve, err)
+	assert.Equal(t, "charmander", pokemon.Species)
+}
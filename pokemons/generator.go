@@ -3,50 +3,90 @@ package pokemons
 import (
 	"math/rand"
 
-	"github.com/NOVAPokemon/utils/experience"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/NOVAPokemon/utils/species"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetOneWildPokemon(maxLevel float64, stdHPDeviation float64, maxHP float64, stdDamageDeviation float64,
-	maxDamage float64, species string) *Pokemon {
-
-	var level, hp, damage int
-	level = rand.Intn(int(maxLevel-1)) + 1
-	randNormal := rand.NormFloat64()*
-		(stdHPDeviation*(float64(level)/maxLevel)) +
-		(maxHP * (float64(level) / maxLevel))
-	hp = int(randNormal)
+const (
+	MaxSpeed            = 100
+	StdSpeedDeviation   = 10
+	MaxDefense          = 100
+	StdDefenseDeviation = 10
+)
 
-	// safeguards
-	if hp < 1 {
-		hp = 1
+// GetOneWildPokemon generates a pokemon of the given species, scaling the maximum stats by its base
+// stats. Species missing from the default catalogue get average base stats. A nil rng uses the
+// global source.
+func GetOneWildPokemon(rng random.Source, maxLevel float64, stdHPDeviation float64, maxHP float64, stdDamageDeviation float64,
+	maxDamage float64, speciesName string) *Pokemon {
+	wildSpecies, ok := species.DefaultCatalogue.Get(speciesName)
+	if !ok {
+		wildSpecies = species.Species{Name: speciesName, BaseStats: species.AverageStats}
 	}
 
-	randNormal = rand.NormFloat64()*
-		(stdDamageDeviation*(float64(level)/maxLevel)) +
-		(maxDamage * (float64(level) / maxLevel))
+	return GenerateWildPokemon(rng, wildSpecies, maxLevel, stdHPDeviation, maxHP, stdDamageDeviation, maxDamage)
+}
+
+func GenerateWildPokemon(rng random.Source, wildSpecies species.Species, maxLevel float64, stdHPDeviation float64,
+	maxHP float64, stdDamageDeviation float64, maxDamage float64) *Pokemon {
+	return GenerateWildPokemonFromSeed(random.OrGlobal(rng).Int63(), wildSpecies, maxLevel, stdHPDeviation, maxHP,
+		stdDamageDeviation, maxDamage)
+}
 
-	damage = int(randNormal)
+// GenerateWildPokemonFromSeed derives everything about the pokemon, id included, from the seed,
+// so the same seed always generates the same pokemon
+func GenerateWildPokemonFromSeed(seed int64, wildSpecies species.Species, maxLevel float64, stdHPDeviation float64,
+	maxHP float64, stdDamageDeviation float64, maxDamage float64) *Pokemon {
+	rng := rand.New(rand.NewSource(seed))
 
-	// safeguards
-	if damage < 1 {
-		damage = 1
-	}
+	var level, hp, damage, speed, defense int
+	level = rng.Intn(int(maxLevel-1)) + 1
+
+	ivs := randomIVs(rng)
+	nature := randomNature(rng)
+	shiny := rng.Float64() < ShinyChance
+
+	baseStats := wildSpecies.BaseStats
+	hp = individualStat(generateStat(rng, level, maxLevel, stdHPDeviation, species.Scale(maxHP, baseStats.HP)),
+		ivs.HP, nature, HPStat)
+	damage = individualStat(generateStat(rng, level, maxLevel, stdDamageDeviation,
+		species.Scale(maxDamage, baseStats.Damage)), ivs.Damage, nature, DamageStat)
+	speed = individualStat(generateStat(rng, level, maxLevel, StdSpeedDeviation,
+		species.Scale(MaxSpeed, baseStats.Speed)), ivs.Speed, nature, SpeedStat)
+	defense = individualStat(generateStat(rng, level, maxLevel, StdDefenseDeviation,
+		species.Scale(MaxDefense, baseStats.Defense)), ivs.Defense, nature, DefenseStat)
 
 	wildPokemon := &Pokemon{
-		Id:      primitive.NewObjectID().Hex(),
-		Species: species,
+		Id:      seededObjectID(rng).Hex(),
+		Species: wildSpecies.Name,
 		Level:   level,
-		XP:      experience.GetMinXpForLevel(float64(level)),
+		XP:      curveFor(&wildSpecies).MinXP(level),
 		HP:      hp,
 		MaxHP:   hp,
 		Damage:  damage,
+		Speed:   speed,
+		Defense: defense,
+		IVs:     &ivs,
+		Nature:  nature,
+		Shiny:   shiny,
 	}
 	return wildPokemon
 }
 
-func GenerateRaidBoss(maxLevel float64, stdHPDeviation float64, maxHP float64, stdDamageDeviation float64,
-	maxDamage float64, species string) *Pokemon {
-	generated := GetOneWildPokemon(maxLevel*2, stdHPDeviation, maxHP*10, stdDamageDeviation, maxDamage/5, species)
+func generateStat(rng *rand.Rand, level int, maxLevel float64, stdDeviation float64, maxValue float64) float64 {
+	levelRatio := float64(level) / maxLevel
+	return rng.NormFloat64()*(stdDeviation*levelRatio) + maxValue*levelRatio
+}
+
+func seededObjectID(rng *rand.Rand) primitive.ObjectID {
+	var id primitive.ObjectID
+	_, _ = rng.Read(id[:])
+	return id
+}
+
+func GenerateRaidBoss(rng random.Source, maxLevel float64, stdHPDeviation float64, maxHP float64,
+	stdDamageDeviation float64, maxDamage float64, species string) *Pokemon {
+	generated := GetOneWildPokemon(rng, maxLevel*2, stdHPDeviation, maxHP*10, stdDamageDeviation, maxDamage/5, species)
 	return generated
 }
@@ -0,0 +1,80 @@
+package pokemons
+
+import (
+	"math"
+
+	"github.com/NOVAPokemon/utils/experience"
+	"github.com/NOVAPokemon/utils/species"
+)
+
+// MaxLevel is the highest level pokemons reach by gaining experience
+const MaxLevel = experience.DefaultMaxLevel
+
+var growthCurves = map[string]experience.Curve{
+	species.FastGrowth:   experience.QuadraticCurve{Factor: .8, Max: MaxLevel},
+	species.MediumGrowth: experience.QuadraticCurve{Factor: 1, Max: MaxLevel},
+	species.SlowGrowth:   experience.QuadraticCurve{Factor: 1.25, Max: MaxLevel},
+}
+
+// ExperienceCurve returns the curve of the species' growth rate. Species missing from the default
+// catalogue grow at the medium rate.
+func ExperienceCurve(speciesName string) experience.Curve {
+	s, _ := species.DefaultCatalogue.Get(speciesName)
+	return curveFor(&s)
+}
+
+func curveFor(s *species.Species) experience.Curve {
+	if curve, ok := growthCurves[s.Growth()]; ok {
+		return curve
+	}
+
+	return growthCurves[species.MediumGrowth]
+}
+
+// GainExperience returns the pokemon after gaining xp and the levels it reached. Stats grow in
+// proportion to the level, the same way the generator creates them, so each pokemon keeps what
+// its species, individual values and nature give it. The pokemon keeps the same fraction of its HP.
+func GainExperience(pokemon Pokemon, xp float64) (Pokemon, []int) {
+	if xp <= 0 {
+		return pokemon, nil
+	}
+
+	curve := ExperienceCurve(pokemon.Species)
+	pokemon.XP += xp
+
+	newLevel := curve.Level(pokemon.XP)
+	if newLevel <= pokemon.Level {
+		return pokemon, nil
+	}
+
+	levels := make([]int, 0, newLevel-pokemon.Level)
+	for level := pokemon.Level + 1; level <= newLevel; level++ {
+		levels = append(levels, level)
+	}
+
+	oldLevel := pokemon.Level
+	if oldLevel < 1 {
+		oldLevel = 1
+	}
+
+	maxHP := growStat(pokemon.MaxHP, oldLevel, newLevel)
+	if pokemon.MaxHP > 0 {
+		pokemon.HP = int(math.Round(float64(pokemon.HP) * float64(maxHP) / float64(pokemon.MaxHP)))
+	}
+	pokemon.MaxHP = maxHP
+
+	pokemon.Damage = growStat(pokemon.Damage, oldLevel, newLevel)
+	pokemon.Speed = growStat(pokemon.Speed, oldLevel, newLevel)
+	pokemon.Defense = growStat(pokemon.Defense, oldLevel, newLevel)
+	pokemon.Level = newLevel
+
+	return pokemon, levels
+}
+
+func growStat(stat, oldLevel, newLevel int) int {
+	if stat <= 0 {
+		return stat
+	}
+
+	return int(math.Round(float64(stat) * float64(newLevel) / float64(oldLevel)))
+}
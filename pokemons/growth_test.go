@@ -0,0 +1,44 @@
+package pokemons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGainExperienceGrowsStatsOnLevelUp(t *testing.T) {
+	pokemon := Pokemon{Species: "charmander", Level: 2, XP: 4, HP: 5, MaxHP: 10, Damage: 4, Speed: 6, Defense: 2}
+
+	grown, levels := GainExperience(pokemon, 12)
+	assert.Equal(t, []int{3, 4}, levels)
+	assert.Equal(t, 4, grown.Level)
+	assert.Equal(t, 16., grown.XP)
+	assert.Equal(t, 20, grown.MaxHP)
+	assert.Equal(t, 10, grown.HP)
+	assert.Equal(t, 8, grown.Damage)
+	assert.Equal(t, 12, grown.Speed)
+	assert.Equal(t, 4, grown.Defense)
+
+	// the original pokemon is left untouched
+	assert.Equal(t, 2, pokemon.Level)
+}
+
+func TestGrowthRateChangesRequiredExperience(t *testing.T) {
+	assert.Less(t, ExperienceCurve("pidgey").MinXP(10), ExperienceCurve("charmander").MinXP(10))
+	assert.Greater(t, ExperienceCurve("mewtwo").MinXP(10), ExperienceCurve("charmander").MinXP(10))
+
+	pokemon := Pokemon{Species: "mewtwo", Level: 2, XP: 5, MaxHP: 10}
+	grown, levels := GainExperience(pokemon, 5)
+	assert.Empty(t, levels)
+	assert.Equal(t, 10., grown.XP)
+	assert.Equal(t, 10, grown.MaxHP)
+}
+
+func TestGainExperienceStopsAtMaxLevel(t *testing.T) {
+	pokemon := Pokemon{Species: "charmander", Level: MaxLevel, XP: 10000, MaxHP: 100}
+
+	grown, levels := GainExperience(pokemon, 1e6)
+	assert.Empty(t, levels)
+	assert.Equal(t, MaxLevel, grown.Level)
+	assert.Equal(t, 100, grown.MaxHP)
+}
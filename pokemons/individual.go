@@ -0,0 +1,137 @@
+package pokemons
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+)
+
+const (
+	// MaxIV is the highest individual value a pokemon can have in a stat
+	MaxIV = 31
+	// MaxIVBonus is the fraction a stat grows with a perfect individual value
+	MaxIVBonus = .1
+	// NatureFactor is the fraction a nature increases and decreases its stats by
+	NatureFactor = .1
+	// ShinyChance is the probability of a wild pokemon being shiny
+	ShinyChance = 1. / 512
+)
+
+// Stats a nature can modify
+const (
+	HPStat      = "HP"
+	DamageStat  = "DAMAGE"
+	SpeedStat   = "SPEED"
+	DefenseStat = "DEFENSE"
+)
+
+// Natures
+const (
+	Hardy   = "HARDY"
+	Docile  = "DOCILE"
+	Lonely  = "LONELY"
+	Brave   = "BRAVE"
+	Bold    = "BOLD"
+	Relaxed = "RELAXED"
+	Timid   = "TIMID"
+	Hasty   = "HASTY"
+	Modest  = "MODEST"
+	Careful = "CAREFUL"
+)
+
+type (
+	// IVs are the hidden individual values of a pokemon, from 0 to MaxIV, that make two pokemons
+	// of the same species and level have different stats
+	IVs struct {
+		HP      int `json:"hp" bson:"hp"`
+		Damage  int `json:"damage" bson:"damage"`
+		Speed   int `json:"speed" bson:"speed"`
+		Defense int `json:"defense" bson:"defense"`
+	}
+
+	// NatureModifier tells which stat a nature increases and which one it decreases. Neutral
+	// natures leave both empty.
+	NatureModifier struct {
+		Increased string
+		Decreased string
+	}
+)
+
+var natureModifiers = map[string]NatureModifier{
+	Hardy:   {},
+	Docile:  {},
+	Lonely:  {Increased: DamageStat, Decreased: DefenseStat},
+	Brave:   {Increased: DamageStat, Decreased: SpeedStat},
+	Bold:    {Increased: DefenseStat, Decreased: DamageStat},
+	Relaxed: {Increased: DefenseStat, Decreased: SpeedStat},
+	Timid:   {Increased: SpeedStat, Decreased: DamageStat},
+	Hasty:   {Increased: SpeedStat, Decreased: DefenseStat},
+	Modest:  {Increased: HPStat, Decreased: DamageStat},
+	Careful: {Increased: HPStat, Decreased: SpeedStat},
+}
+
+// natures sorted, so picking one from a seed always gives the same nature
+var natures = []string{Bold, Brave, Careful, Docile, Hardy, Hasty, Lonely, Modest, Relaxed, Timid}
+
+// SpawnSeed derives the seed of a spawn from where and when it happened, so every replica
+// generates the same pokemon for it
+func SpawnSeed(cellId uint64, timestamp int64, index int) int64 {
+	buf := make([]byte, 24)
+	binary.BigEndian.PutUint64(buf, cellId)
+	binary.BigEndian.PutUint64(buf[8:], uint64(timestamp))
+	binary.BigEndian.PutUint64(buf[16:], uint64(index))
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(buf)
+	return int64(hash.Sum64())
+}
+
+func GetNatureModifier(nature string) (NatureModifier, bool) {
+	modifier, ok := natureModifiers[nature]
+	return modifier, ok
+}
+
+// Factor is the multiplier the nature applies to the given stat
+func (modifier NatureModifier) Factor(stat string) float64 {
+	switch {
+	case stat == "" || modifier.Increased == modifier.Decreased:
+		return 1
+	case stat == modifier.Increased:
+		return 1 + NatureFactor
+	case stat == modifier.Decreased:
+		return 1 - NatureFactor
+	default:
+		return 1
+	}
+}
+
+// IVFactor is the multiplier an individual value applies to its stat
+func IVFactor(iv int) float64 {
+	return 1 + MaxIVBonus*float64(iv)/MaxIV
+}
+
+func randomIVs(rng *rand.Rand) IVs {
+	return IVs{
+		HP:      rng.Intn(MaxIV + 1),
+		Damage:  rng.Intn(MaxIV + 1),
+		Speed:   rng.Intn(MaxIV + 1),
+		Defense: rng.Intn(MaxIV + 1),
+	}
+}
+
+func randomNature(rng *rand.Rand) string {
+	return natures[rng.Intn(len(natures))]
+}
+
+// individualStat applies the individual value and the nature to a generated stat
+func individualStat(stat float64, iv int, nature string, statName string) int {
+	modifier := natureModifiers[nature]
+	value := int(stat * IVFactor(iv) * modifier.Factor(statName))
+
+	// safeguards
+	if value < 1 {
+		value = 1
+	}
+
+	return value
+}
@@ -0,0 +1,44 @@
+package pokemons
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/NOVAPokemon/utils/species"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameSeedGeneratesSamePokemon(t *testing.T) {
+	mewtwo, _ := species.DefaultCatalogue.Get("mewtwo")
+	seed := SpawnSeed(42, 1000, 3)
+
+	first := GenerateWildPokemonFromSeed(seed, mewtwo, 100, 10, 100, 10, 100)
+	second := GenerateWildPokemonFromSeed(seed, mewtwo, 100, 10, 100, 10, 100)
+	assert.Equal(t, first, second)
+
+	other := GenerateWildPokemonFromSeed(SpawnSeed(42, 1000, 4), mewtwo, 100, 10, 100, 10, 100)
+	assert.NotEqual(t, first.Id, other.Id)
+}
+
+func TestWildPokemonUseSpeciesBaseStatsAndIndividualValues(t *testing.T) {
+	pokemon := GetOneWildPokemon(random.New(1), 100, 0, 100, 0, 100, "mewtwo")
+	levelRatio := float64(pokemon.Level) / 100
+	modifier, ok := GetNatureModifier(pokemon.Nature)
+
+	assert.True(t, ok)
+	assert.Equal(t, "mewtwo", pokemon.Species)
+	assert.Equal(t, individualStat(150*levelRatio, pokemon.IVs.HP, pokemon.Nature, HPStat), pokemon.MaxHP)
+	assert.Equal(t, individualStat(160*levelRatio, pokemon.IVs.Damage, pokemon.Nature, DamageStat), pokemon.Damage)
+	assert.InDelta(t, 1, modifier.Factor(SpeedStat), NatureFactor)
+}
+
+func TestNatureFactors(t *testing.T) {
+	lonely, _ := GetNatureModifier(Lonely)
+	hardy, _ := GetNatureModifier(Hardy)
+
+	assert.Equal(t, 1+NatureFactor, lonely.Factor(DamageStat))
+	assert.Equal(t, 1-NatureFactor, lonely.Factor(DefenseStat))
+	assert.Equal(t, 1., lonely.Factor(SpeedStat))
+	assert.Equal(t, 1., hardy.Factor(DamageStat))
+	assert.Equal(t, 1+MaxIVBonus, IVFactor(MaxIV))
+}
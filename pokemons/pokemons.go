@@ -8,4 +8,9 @@ type Pokemon struct {
 	HP      int
 	MaxHP   int
 	Damage  int
+	Speed   int
+	Defense int
+	IVs     *IVs   `json:",omitempty" bson:",omitempty"`
+	Nature  string `json:",omitempty" bson:",omitempty"`
+	Shiny   bool   `json:",omitempty" bson:",omitempty"`
 }
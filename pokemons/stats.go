@@ -0,0 +1,46 @@
+package pokemons
+
+import (
+	"time"
+)
+
+const (
+	// ReferenceSpeed is the speed of a pokemon that waits exactly the base cooldown between moves
+	ReferenceSpeed = 50
+	// ReferenceDefense is the defense that halves the damage taken
+	ReferenceDefense = 100
+
+	minCooldownFactor = .5
+	maxCooldownFactor = 2
+)
+
+// Cooldown scales the base cooldown by the pokemon's speed, so faster pokemons move more often.
+// Pokemons without a speed stat use the base cooldown.
+func (pokemon *Pokemon) Cooldown(base time.Duration) time.Duration {
+	if pokemon == nil || pokemon.Speed <= 0 {
+		return base
+	}
+
+	factor := float64(ReferenceSpeed) / float64(pokemon.Speed)
+	if factor < minCooldownFactor {
+		factor = minCooldownFactor
+	} else if factor > maxCooldownFactor {
+		factor = maxCooldownFactor
+	}
+
+	return time.Duration(float64(base) * factor)
+}
+
+// MitigateDamage reduces the damage of an attack according to the pokemon's defense.
+// Attacks always deal at least one point of damage.
+func (pokemon *Pokemon) MitigateDamage(damage int) int {
+	if pokemon.Defense > 0 {
+		damage = damage * ReferenceDefense / (ReferenceDefense + pokemon.Defense)
+	}
+
+	if damage < 1 {
+		damage = 1
+	}
+
+	return damage
+}
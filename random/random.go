@@ -0,0 +1,114 @@
+package random
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SeedEnvVar holds the seed of a run that should be replayed
+const SeedEnvVar = "RANDOM_SEED"
+
+type (
+	// Source is the subset of *rand.Rand used by the game packages, so runs can be reproduced
+	// by passing sources created from a known seed
+	Source interface {
+		Float64() float64
+		Intn(n int) int
+		Int63() int64
+		NormFloat64() float64
+	}
+
+	// lockedSource is a seeded source safe for concurrent use
+	lockedSource struct {
+		lock sync.Mutex
+		rng  *rand.Rand
+	}
+
+	globalSource struct{}
+)
+
+// Global uses the global math/rand source
+var Global Source = globalSource{}
+
+// New creates a source from the given seed that is safe for concurrent use
+func New(seed int64) Source {
+	return &lockedSource{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Derive creates a new source seeded from the given one, or from the global source when it is
+// nil, so components get their own sequence while staying reproducible
+func Derive(rng Source) Source {
+	return New(OrGlobal(rng).Int63())
+}
+
+// OrGlobal returns the given source, or the global one when it is nil
+func OrGlobal(rng Source) Source {
+	if rng == nil {
+		return Global
+	}
+
+	return rng
+}
+
+// SeedGlobal seeds the global source with the seed in SeedEnvVar, or with the current time when
+// it is missing, and logs the seed so the run can be replayed
+func SeedGlobal(component string) int64 {
+	seed := time.Now().UnixNano()
+	if seedString, exists := os.LookupEnv(SeedEnvVar); exists {
+		parsed, err := strconv.ParseInt(seedString, 10, 64)
+		if err != nil {
+			log.Warnf("invalid %s %s, using %d", SeedEnvVar, seedString, seed)
+		} else {
+			seed = parsed
+		}
+	}
+
+	rand.Seed(seed)
+	log.Infof("%s random seed: %d", component, seed)
+	return seed
+}
+
+func (s *lockedSource) Float64() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.Float64()
+}
+
+func (s *lockedSource) Intn(n int) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.Intn(n)
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.Int63()
+}
+
+func (s *lockedSource) NormFloat64() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.NormFloat64()
+}
+
+func (globalSource) Float64() float64 {
+	return rand.Float64()
+}
+
+func (globalSource) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (globalSource) Int63() int64 {
+	return rand.Int63()
+}
+
+func (globalSource) NormFloat64() float64 {
+	return rand.NormFloat64()
+}
@@ -0,0 +1,27 @@
+package random
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameSeedGivesSameSequence(t *testing.T) {
+	first, second := New(7), New(7)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.Int63(), second.Int63())
+	}
+
+	assert.Equal(t, Derive(New(3)).Intn(1000), Derive(New(3)).Intn(1000))
+}
+
+func TestSeedGlobalUsesEnvSeed(t *testing.T) {
+	_ = os.Setenv(SeedEnvVar, "1234")
+	defer func() { _ = os.Unsetenv(SeedEnvVar) }()
+
+	assert.Equal(t, int64(1234), SeedGlobal("test"))
+	expected := rand.New(rand.NewSource(1234)).Int63()
+	assert.Equal(t, expected, Global.Int63())
+}
@@ -0,0 +1,19 @@
+package rebalancer
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	errorRebalance = "error rebalancing cells"
+)
+
+var (
+	ErrorInvalidConfig     = errors.New("invalid rebalancer config")
+	ErrorInvalidCapacity   = errors.New("missing or invalid server capacity")
+	ErrorInvalidAssignment = errors.New("invalid cell assignment")
+)
+
+func wrapRebalanceError(err error) error {
+	return errors.Wrap(err, errorRebalance)
+}
@@ -0,0 +1,419 @@
+package rebalancer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+type (
+	// CellLoad is what happens inside a cell, which can be at any level
+	CellLoad struct {
+		Trainers     int `json:"trainers"`
+		WildPokemons int `json:"wild_pokemons"`
+	}
+
+	// Config weighs trainers and wild pokemons into a single load, comparable to the servers'
+	// capacities. Servers are filled up to their capacity minus Headroom, a fraction of it.
+	// MaxMoves limits the cells moved by a plan, unlimited when 0.
+	Config struct {
+		TrainerWeight     float64 `json:"trainer_weight"`
+		WildPokemonWeight float64 `json:"wild_pokemon_weight"`
+		Headroom          float64 `json:"headroom"`
+		MaxMoves          int     `json:"max_moves"`
+	}
+
+	// Move hands a cell over to another server
+	Move struct {
+		Cell s2.CellID
+		From string
+		To   string
+		Load float64
+	}
+
+	// Plan is a new assignment of cells to servers, rolled out by storing it with
+	// location.ReplaceServerConfigs and then asking every location server to reload its config.
+	// Previous is the assignment it was computed from, which rollouts check has not changed in the
+	// meantime. Overloaded lists the servers
+	// still above their target load, because no move could help them.
+	Plan struct {
+		Previous   map[string]utils.LocationServerCells
+		Assignment map[string]utils.LocationServerCells
+		Moves      []Move
+		LoadBefore map[string]float64
+		LoadAfter  map[string]float64
+		Capacities map[string]float64
+		Overloaded []string
+	}
+
+	assignedCell struct {
+		id         s2.CellID
+		owner      string
+		load       float64
+		moved      bool
+		neighbours []int
+	}
+
+	rebalancer struct {
+		config     Config
+		servers    []string
+		cells      []assignedCell
+		byId       map[s2.CellID]int
+		owned      map[string]map[int]struct{}
+		load       map[string]float64
+		capacities map[string]float64
+		stuck      map[string]bool
+	}
+)
+
+// DefaultConfig counts each trainer as ten wild pokemons and leaves a tenth of each server free
+var DefaultConfig = Config{
+	TrainerWeight:     10,
+	WildPokemonWeight: 1,
+	Headroom:          .1,
+}
+
+// Rebalance moves cells away from servers above their target load, one at a time, to the
+// neighbouring server with the most room. It moves as few cells as it can, picking the busiest
+// cells first, and only moves cells at the edge of a server's area that keep both servers'
+// areas contiguous. Loads of cells outside the assignment are ignored.
+func Rebalance(current map[string]utils.LocationServerCells, loads map[s2.CellID]CellLoad,
+	capacities map[string]float64, config Config) (*Plan, error) {
+	if config.TrainerWeight < 0 || config.WildPokemonWeight < 0 || config.Headroom < 0 ||
+		config.Headroom >= 1 || config.MaxMoves < 0 {
+		return nil, wrapRebalanceError(ErrorInvalidConfig)
+	}
+
+	r, err := newRebalancer(current, capacities, config)
+	if err != nil {
+		return nil, wrapRebalanceError(err)
+	}
+
+	r.addLoads(loads)
+
+	plan := &Plan{
+		Previous:   current,
+		LoadBefore: copyLoads(r.load),
+		Capacities: capacities,
+	}
+
+	for config.MaxMoves == 0 || len(plan.Moves) < config.MaxMoves {
+		server, ok := r.mostOverloaded()
+		if !ok {
+			break
+		}
+
+		move, ok := r.bestMove(server)
+		if !ok {
+			r.stuck[server] = true
+			continue
+		}
+
+		r.apply(move)
+		plan.Moves = append(plan.Moves, move)
+	}
+
+	plan.Assignment = r.assignment()
+	plan.LoadAfter = copyLoads(r.load)
+	for _, server := range r.servers {
+		if r.overload(server) > 0 {
+			plan.Overloaded = append(plan.Overloaded, server)
+		}
+	}
+
+	return plan, nil
+}
+
+func newRebalancer(current map[string]utils.LocationServerCells, capacities map[string]float64,
+	config Config) (*rebalancer, error) {
+	r := &rebalancer{
+		config:     config,
+		byId:       map[s2.CellID]int{},
+		owned:      map[string]map[int]struct{}{},
+		load:       map[string]float64{},
+		capacities: capacities,
+		stuck:      map[string]bool{},
+		servers:    sortedServers(current),
+	}
+
+	for _, server := range r.servers {
+		capacity, ok := capacities[server]
+		if !ok || capacity <= 0 {
+			return nil, errors.Wrap(ErrorInvalidCapacity, server)
+		}
+
+		r.owned[server] = map[int]struct{}{}
+		r.load[server] = 0
+
+		for _, token := range current[server].CellIdsStrings {
+			cellId := s2.CellIDFromToken(token)
+			if !cellId.IsValid() {
+				return nil, errors.Wrap(ErrorInvalidAssignment, fmt.Sprintf("invalid cell %s", token))
+			}
+
+			if owner, ok := r.ownerOf(cellId); ok {
+				return nil, errors.Wrap(ErrorInvalidAssignment,
+					fmt.Sprintf("cell %s of %s overlaps cells of %s", token, server, owner))
+			}
+
+			r.byId[cellId] = len(r.cells)
+			r.owned[server][len(r.cells)] = struct{}{}
+			r.cells = append(r.cells, assignedCell{id: cellId, owner: server})
+		}
+	}
+
+	// the assignment has no overlaps, so at most one cell contains each neighbour
+	for i := range r.cells {
+		for _, neighbour := range r.cells[i].id.EdgeNeighbors() {
+			j, ok := r.containing(neighbour)
+			if !ok || j == i {
+				continue
+			}
+
+			r.cells[i].neighbours = appendUnique(r.cells[i].neighbours, j)
+			r.cells[j].neighbours = appendUnique(r.cells[j].neighbours, i)
+		}
+	}
+
+	return r, nil
+}
+
+// ownerOf finds the server of a cell overlapping the given one, either containing it or inside it
+func (r *rebalancer) ownerOf(cellId s2.CellID) (string, bool) {
+	if i, ok := r.containing(cellId); ok {
+		return r.cells[i].owner, true
+	}
+
+	for i := range r.cells {
+		if cellId.Contains(r.cells[i].id) {
+			return r.cells[i].owner, true
+		}
+	}
+
+	return "", false
+}
+
+// containing returns the assigned cell that is or contains the given one
+func (r *rebalancer) containing(cellId s2.CellID) (int, bool) {
+	for level := cellId.Level(); level >= 0; level-- {
+		if i, ok := r.byId[cellId.Parent(level)]; ok {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (r *rebalancer) addLoads(loads map[s2.CellID]CellLoad) {
+	for cellId, cellLoad := range loads {
+		i, ok := r.containing(cellId)
+		if !ok {
+			continue
+		}
+
+		load := float64(cellLoad.Trainers)*r.config.TrainerWeight +
+			float64(cellLoad.WildPokemons)*r.config.WildPokemonWeight
+		r.cells[i].load += load
+		r.load[r.cells[i].owner] += load
+	}
+}
+
+func (r *rebalancer) target(server string) float64 {
+	return r.capacities[server] * (1 - r.config.Headroom)
+}
+
+func (r *rebalancer) overload(server string) float64 {
+	return r.load[server] - r.target(server)
+}
+
+func (r *rebalancer) utilization(server string) float64 {
+	return r.load[server] / r.capacities[server]
+}
+
+func (r *rebalancer) mostOverloaded() (string, bool) {
+	var (
+		worst string
+		found bool
+	)
+
+	for _, server := range r.servers {
+		if r.stuck[server] || r.overload(server) <= 0 {
+			continue
+		}
+
+		if !found || r.utilization(server) > r.utilization(worst) {
+			worst, found = server, true
+		}
+	}
+
+	return worst, found
+}
+
+// bestMove finds the busiest cell of the server that a neighbouring server has room for, preferring
+// the neighbour with the lowest utilization
+func (r *rebalancer) bestMove(server string) (Move, bool) {
+	var candidates []Move
+	for i := range r.owned[server] {
+		cell := &r.cells[i]
+		if cell.moved || cell.load <= 0 {
+			continue
+		}
+
+		for _, j := range cell.neighbours {
+			to := r.cells[j].owner
+			if to == server || r.load[to]+cell.load > r.target(to) {
+				continue
+			}
+
+			candidates = append(candidates, Move{Cell: cell.id, From: server, To: to, Load: cell.load})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Load != b.Load {
+			return a.Load > b.Load
+		}
+
+		if r.utilization(a.To) != r.utilization(b.To) {
+			return r.utilization(a.To) < r.utilization(b.To)
+		}
+
+		if a.Cell != b.Cell {
+			return a.Cell < b.Cell
+		}
+
+		return a.To < b.To
+	})
+
+	components := r.components(server, -1)
+	for _, candidate := range candidates {
+		i := r.byId[candidate.Cell]
+		if len(r.owned[server]) > 1 && r.components(server, i) <= components {
+			return candidate, true
+		}
+	}
+
+	return Move{}, false
+}
+
+// components counts the contiguous areas of the server's cells, leaving out the excluded cell
+func (r *rebalancer) components(server string, excluded int) int {
+	visited := map[int]bool{excluded: true}
+	count := 0
+
+	for i := range r.owned[server] {
+		if visited[i] {
+			continue
+		}
+
+		count++
+		visited[i] = true
+		queue := []int{i}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, j := range r.cells[current].neighbours {
+				if _, owned := r.owned[server][j]; owned && !visited[j] {
+					visited[j] = true
+					queue = append(queue, j)
+				}
+			}
+		}
+	}
+
+	return count
+}
+
+func (r *rebalancer) apply(move Move) {
+	i := r.byId[move.Cell]
+
+	delete(r.owned[move.From], i)
+	r.owned[move.To][i] = struct{}{}
+	r.load[move.From] -= move.Load
+	r.load[move.To] += move.Load
+
+	r.cells[i].owner = move.To
+	r.cells[i].moved = true
+
+	// the receiving server might now be able to shed some other cell
+	delete(r.stuck, move.To)
+}
+
+func (r *rebalancer) assignment() map[string]utils.LocationServerCells {
+	assignment := make(map[string]utils.LocationServerCells, len(r.owned))
+	for server, owned := range r.owned {
+		tokens := make([]string, 0, len(owned))
+		for i := range owned {
+			tokens = append(tokens, r.cells[i].id.ToToken())
+		}
+		sort.Strings(tokens)
+
+		assignment[server] = utils.LocationServerCells{
+			CellIdsStrings: tokens,
+			ServerName:     server,
+		}
+	}
+
+	return assignment
+}
+
+// Diff describes the plan without applying it, listing each server's load before and after and
+// the cells it gives away and receives
+func (plan *Plan) Diff() string {
+	var builder strings.Builder
+
+	for _, server := range sortedServers(plan.Assignment) {
+		_, _ = fmt.Fprintf(&builder, "%s: load %.1f -> %.1f of %.1f\n", server, plan.LoadBefore[server],
+			plan.LoadAfter[server], plan.Capacities[server])
+
+		for _, move := range plan.Moves {
+			switch server {
+			case move.From:
+				_, _ = fmt.Fprintf(&builder, "  - %s (%.1f) to %s\n", move.Cell.ToToken(), move.Load, move.To)
+			case move.To:
+				_, _ = fmt.Fprintf(&builder, "  + %s (%.1f) from %s\n", move.Cell.ToToken(), move.Load, move.From)
+			}
+		}
+	}
+
+	if len(plan.Overloaded) > 0 {
+		_, _ = fmt.Fprintf(&builder, "still overloaded: %s\n", strings.Join(plan.Overloaded, ", "))
+	}
+
+	return builder.String()
+}
+
+func appendUnique(indexes []int, index int) []int {
+	for _, existing := range indexes {
+		if existing == index {
+			return indexes
+		}
+	}
+
+	return append(indexes, index)
+}
+
+func copyLoads(loads map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(loads))
+	for server, load := range loads {
+		copied[server] = load
+	}
+
+	return copied
+}
+
+func sortedServers(assignment map[string]utils.LocationServerCells) []string {
+	servers := make([]string, 0, len(assignment))
+	for server := range assignment {
+		servers = append(servers, server)
+	}
+
+	sort.Strings(servers)
+	return servers
+}
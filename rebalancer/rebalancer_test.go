@@ -0,0 +1,134 @@
+package rebalancer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	serverA = "location-a"
+	serverB = "location-b"
+)
+
+var testConfig = Config{TrainerWeight: 1}
+
+// row returns n cells, each to the right of the previous one
+func row(n int) []s2.CellID {
+	cells := []s2.CellID{s2.CellIDFromLatLng(s2.LatLngFromDegrees(38.7, -9.1)).Parent(10)}
+	for len(cells) < n {
+		cells = append(cells, cells[len(cells)-1].EdgeNeighbors()[1])
+	}
+
+	return cells
+}
+
+func assignmentOf(cellsPerServer map[string][]s2.CellID) map[string]utils.LocationServerCells {
+	assignment := map[string]utils.LocationServerCells{}
+	for server, cells := range cellsPerServer {
+		tokens := make([]string, len(cells))
+		for i, cell := range cells {
+			tokens[i] = cell.ToToken()
+		}
+
+		assignment[server] = utils.LocationServerCells{CellIdsStrings: tokens, ServerName: server}
+	}
+
+	return assignment
+}
+
+func loadsOf(cells []s2.CellID, trainers ...int) map[s2.CellID]CellLoad {
+	loads := map[s2.CellID]CellLoad{}
+	for i, cell := range cells {
+		// loads can be reported for cells smaller than the assigned ones
+		loads[cell.ChildBegin()] = CellLoad{Trainers: trainers[i]}
+	}
+
+	return loads
+}
+
+func TestRebalanceMovesBoundaryCell(t *testing.T) {
+	cells := row(6)
+	current := assignmentOf(map[string][]s2.CellID{serverA: cells[:3], serverB: cells[3:]})
+	capacities := map[string]float64{serverA: 100, serverB: 100}
+
+	plan, err := Rebalance(current, loadsOf(cells, 60, 40, 30, 10, 10, 10), capacities, testConfig)
+	assert.NoError(t, err)
+
+	if assert.Len(t, plan.Moves, 1) {
+		assert.Equal(t, Move{Cell: cells[2], From: serverA, To: serverB, Load: 30}, plan.Moves[0])
+	}
+
+	assert.Equal(t, 130., plan.LoadBefore[serverA])
+	assert.Equal(t, 100., plan.LoadAfter[serverA])
+	assert.Equal(t, 60., plan.LoadAfter[serverB])
+	assert.Empty(t, plan.Overloaded)
+	assert.Contains(t, plan.Assignment[serverB].CellIdsStrings, cells[2].ToToken())
+	assert.NotContains(t, plan.Assignment[serverA].CellIdsStrings, cells[2].ToToken())
+	assert.Equal(t, current, plan.Previous)
+}
+
+func TestRebalanceKeepsContiguity(t *testing.T) {
+	cells := row(3)
+	above := cells[1].EdgeNeighbors()[2]
+
+	current := assignmentOf(map[string][]s2.CellID{serverA: cells, serverB: {above}})
+	capacities := map[string]float64{serverA: 100, serverB: 1000}
+
+	plan, err := Rebalance(current, loadsOf(cells, 10, 100, 10), capacities, testConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Moves)
+	assert.Equal(t, []string{serverA}, plan.Overloaded)
+}
+
+func TestRebalanceMaxMoves(t *testing.T) {
+	cells := row(6)
+	current := assignmentOf(map[string][]s2.CellID{serverA: cells[:4], serverB: cells[4:]})
+	capacities := map[string]float64{serverA: 10, serverB: 100}
+
+	config := testConfig
+	config.MaxMoves = 1
+
+	plan, err := Rebalance(current, loadsOf(cells, 10, 10, 10, 10, 0, 0), capacities, config)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Moves, 1)
+	assert.Equal(t, []string{serverA}, plan.Overloaded)
+
+	config.MaxMoves = 0
+	plan, err = Rebalance(current, loadsOf(cells, 10, 10, 10, 10, 0, 0), capacities, config)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Moves, 3)
+	assert.Empty(t, plan.Overloaded)
+}
+
+func TestRebalanceDiff(t *testing.T) {
+	cells := row(6)
+	current := assignmentOf(map[string][]s2.CellID{serverA: cells[:3], serverB: cells[3:]})
+	capacities := map[string]float64{serverA: 100, serverB: 100}
+
+	plan, err := Rebalance(current, loadsOf(cells, 60, 40, 30, 10, 10, 10), capacities, testConfig)
+	assert.NoError(t, err)
+
+	diff := plan.Diff()
+	assert.True(t, strings.Contains(diff, "- "+cells[2].ToToken()))
+	assert.True(t, strings.Contains(diff, "+ "+cells[2].ToToken()))
+}
+
+func TestRebalanceInvalidInput(t *testing.T) {
+	cells := row(2)
+
+	current := assignmentOf(map[string][]s2.CellID{serverA: cells[:1], serverB: cells[1:]})
+	_, err := Rebalance(current, nil, map[string]float64{serverA: 100}, testConfig)
+	assert.Equal(t, ErrorInvalidCapacity, errors.Cause(err))
+
+	overlapping := assignmentOf(map[string][]s2.CellID{serverA: cells[:1], serverB: {cells[0].ChildBegin()}})
+	_, err = Rebalance(overlapping, nil, map[string]float64{serverA: 100, serverB: 100}, testConfig)
+	assert.Equal(t, ErrorInvalidAssignment, errors.Cause(err))
+
+	_, err = Rebalance(current, nil, map[string]float64{serverA: 100, serverB: 100}, Config{Headroom: 1})
+	assert.Equal(t, ErrorInvalidConfig, errors.Cause(err))
+}
@@ -5,11 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"os"
-	"time"
 
+	"github.com/NOVAPokemon/utils/random"
 	"github.com/NOVAPokemon/utils/websockets"
 	"github.com/NOVAPokemon/utils/websockets/comms_manager"
 	"github.com/golang/geo/s2"
@@ -64,7 +63,7 @@ type (
 )
 
 func StartServer(serviceName, host string, port int, routes Routes, manager websockets.CommunicationManager) {
-	rand.Seed(time.Now().UnixNano())
+	random.SeedGlobal(serviceName)
 	addr := fmt.Sprintf("%s:%d", host, port)
 
 	r := NewRouter(routes)
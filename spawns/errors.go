@@ -0,0 +1,30 @@
+package spawns
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errorLoadSpawnTablesFormat = "error loading spawn tables %s"
+	errorNewGeneratorFormat    = "error creating generator with spawn table %s"
+	errorGenerateFormat        = "error generating wild pokemons for server %s"
+)
+
+var (
+	ErrorInvalidSpawnTable = errors.New("invalid spawn table")
+	ErrorInvalidCell       = errors.New("invalid cell")
+)
+
+func wrapLoadSpawnTablesError(err error, filename string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorLoadSpawnTablesFormat, filename))
+}
+
+func wrapNewGeneratorError(err error, tableName string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorNewGeneratorFormat, tableName))
+}
+
+func wrapGenerateError(err error, serverName string) error {
+	return errors.Wrap(err, fmt.Sprintf(errorGenerateFormat, serverName))
+}
@@ -0,0 +1,154 @@
+package spawns
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/species"
+	"github.com/golang/geo/s2"
+)
+
+// tries to place a pokemon inside its cell before falling back to the cell center
+const maxPlacementTries = 10
+
+// Generator produces the wild pokemons of a location server's cells from the spawn tables.
+// Everything is derived from the cell and the spawn time, so every replica of a server
+// generates the same pokemons.
+type Generator struct {
+	tables    []SpawnTable
+	regions   *utils.RegionsToAreas
+	catalogue *species.Catalogue
+}
+
+// NewGenerator checks the tables against the catalogue, the default one when nil. Tables are
+// matched in order, so more specific ones should come first.
+func NewGenerator(tables []SpawnTable, regions *utils.RegionsToAreas, catalogue *species.Catalogue) (*Generator,
+	error) {
+	if catalogue == nil {
+		catalogue = species.DefaultCatalogue
+	}
+
+	for i := range tables {
+		if err := tables[i].validate(catalogue); err != nil {
+			return nil, wrapNewGeneratorError(err, tables[i].Name)
+		}
+	}
+
+	return &Generator{
+		tables:    tables,
+		regions:   regions,
+		catalogue: catalogue,
+	}, nil
+}
+
+// TableFor returns the first table that covers the cell
+func (g *Generator) TableFor(cellId s2.CellID) (*SpawnTable, bool) {
+	cellRegions := regionsOf(cellId, g.regions)
+	for i := range g.tables {
+		if g.tables[i].covers(cellId, cellRegions) {
+			return &g.tables[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Generate fills each of the server's cells up to the density cap of its table, taking into
+// account the pokemons each cell already has. The hour of spawnTime in each cell's solar time
+// selects the time modifiers.
+func (g *Generator) Generate(serverCells utils.LocationServerCells, existing map[s2.CellID]int,
+	spawnTime time.Time) ([]utils.WildPokemonWithServer, error) {
+	var wildPokemons []utils.WildPokemonWithServer
+
+	for _, token := range serverCells.CellIdsStrings {
+		cellId := s2.CellIDFromToken(token)
+		if !cellId.IsValid() {
+			return nil, wrapGenerateError(ErrorInvalidCell, serverCells.ServerName)
+		}
+
+		table, ok := g.TableFor(cellId)
+		if !ok {
+			continue
+		}
+
+		for i := existing[cellId]; i < table.MaxPerCell; i++ {
+			seed := pokemons.SpawnSeed(uint64(cellId), spawnTime.Unix(), i)
+
+			pokemon, ok := g.spawn(table, seed, solarHour(cellId, spawnTime))
+			if !ok {
+				break
+			}
+
+			wildPokemons = append(wildPokemons, utils.WildPokemonWithServer{
+				Pokemon:  *pokemon,
+				Location: placeInCell(cellId, seed),
+				Server:   serverCells.ServerName,
+			})
+		}
+	}
+
+	return wildPokemons, nil
+}
+
+func (g *Generator) spawn(table *SpawnTable, seed int64, hour int) (*pokemons.Pokemon, bool) {
+	rng := rand.New(rand.NewSource(seed))
+
+	entry, ok := pickEntry(table.Entries, table.weights(g.catalogue, hour), rng)
+	if !ok {
+		return nil, false
+	}
+
+	wildSpecies, _ := g.catalogue.Get(entry.Species)
+	stats := table.Stats
+	return pokemons.GenerateWildPokemonFromSeed(rng.Int63(), wildSpecies, stats.MaxLevel, stats.StdHPDeviation,
+		stats.MaxHP, stats.StdDamageDeviation, stats.MaxDamage), true
+}
+
+func pickEntry(entries []SpawnEntry, weights []float64, rng *rand.Rand) (SpawnEntry, bool) {
+	total := 0.
+	for _, weight := range weights {
+		total += weight
+	}
+
+	if total <= 0 {
+		return SpawnEntry{}, false
+	}
+
+	target := rng.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target < 0 {
+			return entries[i], true
+		}
+	}
+
+	return entries[len(entries)-1], true
+}
+
+// placeInCell picks a location inside the cell from the seed
+// solarHour is the hour of the mean solar time at the center of the cell, which follows the
+// daylight of the cell wherever the server runs
+func solarHour(cellId s2.CellID, t time.Time) int {
+	offset := s2.LatLngFromPoint(cellId.Point()).Lng.Degrees() / degreesPerHour
+	return t.UTC().Add(time.Duration(offset * float64(time.Hour))).Hour()
+}
+
+func placeInCell(cellId s2.CellID, seed int64) s2.LatLng {
+	rng := rand.New(rand.NewSource(^seed))
+	cell := s2.CellFromCellID(cellId)
+	bound := cell.RectBound()
+
+	for i := 0; i < maxPlacementTries; i++ {
+		lat := bound.Lo().Lat.Degrees() + rng.Float64()*(bound.Hi().Lat.Degrees()-bound.Lo().Lat.Degrees())
+		lng := bound.Lo().Lng.Degrees() + rng.Float64()*(bound.Hi().Lng.Degrees()-bound.Lo().Lng.Degrees())
+
+		location := s2.LatLngFromDegrees(lat, lng)
+		if cell.ContainsPoint(s2.PointFromLatLng(location)) {
+			return location
+		}
+	}
+
+	return cellId.LatLng()
+}
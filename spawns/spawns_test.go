@@ -0,0 +1,86 @@
+package spawns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	lisbonCell = s2.CellIDFromLatLng(s2.LatLngFromDegrees(38.72, -9.14)).Parent(10)
+	portoCell  = s2.CellIDFromLatLng(s2.LatLngFromDegrees(41.15, -8.61)).Parent(10)
+
+	testStats = StatParams{MaxLevel: 50, StdHPDeviation: 5, MaxHP: 100, StdDamageDeviation: 5, MaxDamage: 50}
+
+	testRegions = &utils.RegionsToAreas{Regions: map[string][]utils.Area{
+		"north": {{TopLeft: utils.Coord{Lat: 42, Lng: -9}, BotRight: utils.Coord{Lat: 41, Lng: -8}}},
+	}}
+
+	testTables = []SpawnTable{
+		{Name: "lisbon", Cells: []string{lisbonCell.Parent(6).ToToken()}, MaxPerCell: 3, Stats: testStats,
+			Entries: []SpawnEntry{{Species: "magikarp", Weight: 1}}},
+		{Name: "north", Regions: []string{"north"}, MaxPerCell: 2, Stats: testStats,
+			Entries: []SpawnEntry{{Species: "pidgey", Weight: 1}, {Species: "dratini", Weight: 1}},
+			TimeModifiers: []TimeModifier{{FromHour: 4, ToHour: 22, Types: []string{"DRAGON"}, Factor: 0},
+				{FromHour: 22, ToHour: 4, Species: []string{"pidgey"}, Factor: 0}}},
+	}
+)
+
+func TestGenerateUsesTheTableOfEachCell(t *testing.T) {
+	generator, err := NewGenerator(testTables, testRegions, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	serverCells := utils.LocationServerCells{
+		CellIdsStrings: []string{lisbonCell.ToToken(), portoCell.ToToken()},
+		ServerName:     "location-0",
+	}
+	noon := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	wildPokemons, err := generator.Generate(serverCells, map[s2.CellID]int{lisbonCell: 1}, noon)
+	assert.NoError(t, err)
+	assert.Len(t, wildPokemons, 4)
+
+	for _, wildPokemon := range wildPokemons {
+		assert.Equal(t, "location-0", wildPokemon.Server)
+		if wildPokemon.Pokemon.Species == "magikarp" {
+			assert.True(t, s2.CellFromCellID(lisbonCell).ContainsPoint(s2.PointFromLatLng(wildPokemon.Location)))
+		} else {
+			assert.Equal(t, "pidgey", wildPokemon.Pokemon.Species)
+		}
+	}
+
+	again, _ := generator.Generate(serverCells, map[s2.CellID]int{lisbonCell: 1}, noon)
+	assert.Equal(t, wildPokemons, again)
+}
+
+func TestTimeModifiersChangeWhatSpawns(t *testing.T) {
+	generator, _ := NewGenerator(testTables, testRegions, nil)
+	serverCells := utils.LocationServerCells{CellIdsStrings: []string{portoCell.ToToken()}}
+
+	wildPokemons, err := generator.Generate(serverCells, nil, time.Date(2020, 6, 1, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, wildPokemons, 2)
+	for _, wildPokemon := range wildPokemons {
+		assert.Equal(t, "dratini", wildPokemon.Pokemon.Species)
+	}
+}
+
+func TestSolarHourDependsOnTheCellAndNotTheTimeZone(t *testing.T) {
+	midnight := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	eastCell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(0, 91)).Parent(10)
+
+	assert.Equal(t, 6, solarHour(eastCell, midnight))
+	assert.Equal(t, 6, solarHour(eastCell, midnight.In(time.FixedZone("UTC-5", -5*60*60))))
+	assert.Equal(t, 23, solarHour(portoCell, midnight))
+}
+
+func TestNewGeneratorRejectsUnknownSpecies(t *testing.T) {
+	_, err := NewGenerator([]SpawnTable{{Name: "bad", Stats: testStats,
+		Entries: []SpawnEntry{{Species: "missingno", Weight: 1}}}}, nil, nil)
+	assert.Error(t, err)
+}
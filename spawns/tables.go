@@ -0,0 +1,189 @@
+package spawns
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/species"
+	"github.com/golang/geo/s2"
+)
+
+const (
+	hoursPerDay = 24
+	// degrees of longitude the sun goes through in an hour
+	degreesPerHour = 360. / hoursPerDay
+)
+
+type (
+	// SpawnEntry is a species that can spawn and how often, relative to the other entries
+	SpawnEntry struct {
+		Species string `json:"species"`
+		Weight  int    `json:"weight"`
+	}
+
+	// TimeModifier multiplies the weight of the matching species between FromHour and ToHour,
+	// wrapping around midnight when FromHour is greater than ToHour. Hours are in the mean solar
+	// time of the cell, taken from the longitude of its center, so they do not depend on the time
+	// zone of the server. Species match by name or by any of their types.
+	TimeModifier struct {
+		FromHour int      `json:"from_hour"`
+		ToHour   int      `json:"to_hour"`
+		Species  []string `json:"species,omitempty"`
+		Types    []string `json:"types,omitempty"`
+		Factor   float64  `json:"factor"`
+	}
+
+	// StatParams are the parameters given to the pokemon generator
+	StatParams struct {
+		MaxLevel           float64 `json:"max_level"`
+		StdHPDeviation     float64 `json:"std_hp_deviation"`
+		MaxHP              float64 `json:"max_hp"`
+		StdDamageDeviation float64 `json:"std_damage_deviation"`
+		MaxDamage          float64 `json:"max_damage"`
+	}
+
+	// SpawnTable says what spawns in the cells it covers. Cells are tokens of S2 cells and cover
+	// all their descendants, while regions are the ones in RegionsToAreas. A table with neither
+	// covers every cell. MaxPerCell caps the wild pokemons of each server cell.
+	SpawnTable struct {
+		Name          string         `json:"name"`
+		Cells         []string       `json:"cells,omitempty"`
+		Regions       []string       `json:"regions,omitempty"`
+		Entries       []SpawnEntry   `json:"entries"`
+		TimeModifiers []TimeModifier `json:"time_modifiers,omitempty"`
+		MaxPerCell    int            `json:"max_per_cell"`
+		Stats         StatParams     `json:"stats"`
+	}
+
+	spawnTablesFile struct {
+		Tables []SpawnTable `json:"tables"`
+	}
+)
+
+// LoadSpawnTables reads the spawn tables from a JSON file, in the order they are matched
+func LoadSpawnTables(filename string) ([]SpawnTable, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, wrapLoadSpawnTablesError(err, filename)
+	}
+
+	var file spawnTablesFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, wrapLoadSpawnTablesError(err, filename)
+	}
+
+	return file.Tables, nil
+}
+
+func (table *SpawnTable) validate(catalogue *species.Catalogue) error {
+	if len(table.Entries) == 0 || table.MaxPerCell < 0 || table.Stats.MaxLevel < 2 {
+		return ErrorInvalidSpawnTable
+	}
+
+	for _, entry := range table.Entries {
+		if _, ok := catalogue.Get(entry.Species); !ok || entry.Weight < 0 {
+			return ErrorInvalidSpawnTable
+		}
+	}
+
+	for _, modifier := range table.TimeModifiers {
+		if modifier.FromHour < 0 || modifier.FromHour >= hoursPerDay || modifier.ToHour < 0 ||
+			modifier.ToHour >= hoursPerDay || modifier.Factor < 0 {
+			return ErrorInvalidSpawnTable
+		}
+	}
+
+	for _, token := range table.Cells {
+		if !s2.CellIDFromToken(token).IsValid() {
+			return ErrorInvalidSpawnTable
+		}
+	}
+
+	return nil
+}
+
+// covers tells if the table applies to the cell, given the regions the cell is in
+func (table *SpawnTable) covers(cellId s2.CellID, cellRegions map[string]bool) bool {
+	if len(table.Cells) == 0 && len(table.Regions) == 0 {
+		return true
+	}
+
+	for _, token := range table.Cells {
+		if s2.CellIDFromToken(token).Contains(cellId) {
+			return true
+		}
+	}
+
+	for _, region := range table.Regions {
+		if cellRegions[region] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// weights returns the weight of each entry at the given hour
+func (table *SpawnTable) weights(catalogue *species.Catalogue, hour int) []float64 {
+	weights := make([]float64, len(table.Entries))
+	for i, entry := range table.Entries {
+		weights[i] = float64(entry.Weight)
+
+		s, _ := catalogue.Get(entry.Species)
+		for _, modifier := range table.TimeModifiers {
+			if modifier.activeAt(hour) && modifier.matches(&s) {
+				weights[i] *= modifier.Factor
+			}
+		}
+	}
+
+	return weights
+}
+
+func (modifier *TimeModifier) activeAt(hour int) bool {
+	if modifier.FromHour <= modifier.ToHour {
+		return hour >= modifier.FromHour && hour < modifier.ToHour
+	}
+
+	return hour >= modifier.FromHour || hour < modifier.ToHour
+}
+
+func (modifier *TimeModifier) matches(s *species.Species) bool {
+	for _, name := range modifier.Species {
+		if name == s.Name {
+			return true
+		}
+	}
+
+	for _, speciesType := range modifier.Types {
+		if s.HasType(speciesType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// regionsOf returns the regions whose areas contain the center of the cell
+func regionsOf(cellId s2.CellID, regions *utils.RegionsToAreas) map[string]bool {
+	cellRegions := map[string]bool{}
+	if regions == nil {
+		return cellRegions
+	}
+
+	center := cellId.LatLng()
+	lat, lng := center.Lat.Degrees(), center.Lng.Degrees()
+
+	for region, areas := range regions.Regions {
+		for _, area := range areas {
+			if lat <= area.TopLeft.Lat && lat >= area.BotRight.Lat &&
+				lng >= area.TopLeft.Lng && lng <= area.BotRight.Lng {
+				cellRegions[region] = true
+				break
+			}
+		}
+	}
+
+	return cellRegions
+}
@@ -0,0 +1,265 @@
+package species
+
+import (
+	"sort"
+
+	"github.com/NOVAPokemon/utils/catalogues"
+)
+
+// names the catalogue in the errors of the catalogue files
+const catalogueKind = "species"
+
+// Evolution items
+const (
+	FireStoneName    = "fire-stone"
+	WaterStoneName   = "water-stone"
+	ThunderStoneName = "thunder-stone"
+	LeafStoneName    = "leaf-stone"
+)
+
+type (
+	// Catalogue holds the species that exist in the game, indexed by name
+	Catalogue struct {
+		species map[string]Species
+	}
+
+	catalogueFile struct {
+		Species []Species `json:"species" yaml:"species"`
+	}
+)
+
+var DefaultCatalogue = mustNewCatalogue([]Species{
+	{Name: "bulbasaur", Types: []string{Grass, Poison}, Rarity: Uncommon, CatchDifficulty: .4,
+		BaseStats:  BaseStats{HP: 90, Damage: 85, Speed: 90, Defense: 95},
+		Evolutions: []Evolution{{Into: "ivysaur", MinLevel: 16}}},
+	{Name: "ivysaur", Types: []string{Grass, Poison}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats:  BaseStats{HP: 110, Damage: 105, Speed: 105, Defense: 110},
+		Evolutions: []Evolution{{Into: "venusaur", MinLevel: 32}}},
+	{Name: "venusaur", Types: []string{Grass, Poison}, Rarity: Rare, CatchDifficulty: .8,
+		BaseStats: BaseStats{HP: 135, Damage: 130, Speed: 120, Defense: 135}},
+	{Name: "charmander", Types: []string{Fire}, Rarity: Uncommon, CatchDifficulty: .4,
+		BaseStats:  BaseStats{HP: 80, Damage: 95, Speed: 105, Defense: 80},
+		Evolutions: []Evolution{{Into: "charmeleon", MinLevel: 16}}},
+	{Name: "charmeleon", Types: []string{Fire}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats:  BaseStats{HP: 100, Damage: 115, Speed: 120, Defense: 100},
+		Evolutions: []Evolution{{Into: "charizard", MinLevel: 36}}},
+	{Name: "charizard", Types: []string{Fire, Flying}, Rarity: Rare, CatchDifficulty: .8,
+		BaseStats: BaseStats{HP: 130, Damage: 140, Speed: 135, Defense: 125}},
+	{Name: "squirtle", Types: []string{Water}, Rarity: Uncommon, CatchDifficulty: .4,
+		BaseStats:  BaseStats{HP: 90, Damage: 80, Speed: 85, Defense: 105},
+		Evolutions: []Evolution{{Into: "wartortle", MinLevel: 16}}},
+	{Name: "wartortle", Types: []string{Water}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats:  BaseStats{HP: 105, Damage: 100, Speed: 100, Defense: 125},
+		Evolutions: []Evolution{{Into: "blastoise", MinLevel: 36}}},
+	{Name: "blastoise", Types: []string{Water}, Rarity: Rare, CatchDifficulty: .8,
+		BaseStats: BaseStats{HP: 130, Damage: 125, Speed: 115, Defense: 150}},
+	{Name: "pidgey", Types: []string{Normal, Flying}, Rarity: Common, CatchDifficulty: .1,
+		BaseStats: BaseStats{HP: 75, Damage: 70, Speed: 100, Defense: 70}, GrowthRate: FastGrowth,
+		Evolutions: []Evolution{{Into: "pidgeotto", MinLevel: 18}}},
+	{Name: "pidgeotto", Types: []string{Normal, Flying}, Rarity: Uncommon, CatchDifficulty: .3,
+		BaseStats: BaseStats{HP: 100, Damage: 95, Speed: 120, Defense: 95}, GrowthRate: FastGrowth},
+	{Name: "pikachu", Types: []string{Electric}, Rarity: Uncommon, CatchDifficulty: .3,
+		BaseStats:  BaseStats{HP: 75, Damage: 95, Speed: 135, Defense: 75},
+		Evolutions: []Evolution{{Into: "raichu", Item: ThunderStoneName}}},
+	{Name: "raichu", Types: []string{Electric}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats: BaseStats{HP: 100, Damage: 120, Speed: 140, Defense: 95}},
+	{Name: "oddish", Types: []string{Grass, Poison}, Rarity: Common, CatchDifficulty: .15,
+		BaseStats: BaseStats{HP: 85, Damage: 80, Speed: 70, Defense: 90}, GrowthRate: FastGrowth,
+		Evolutions: []Evolution{{Into: "gloom", MinLevel: 21}}},
+	{Name: "gloom", Types: []string{Grass, Poison}, Rarity: Uncommon, CatchDifficulty: .35,
+		BaseStats: BaseStats{HP: 100, Damage: 100, Speed: 80, Defense: 105}, GrowthRate: FastGrowth,
+		Evolutions: []Evolution{{Into: "vileplume", Item: LeafStoneName}}},
+	{Name: "vileplume", Types: []string{Grass, Poison}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats: BaseStats{HP: 120, Damage: 125, Speed: 90, Defense: 125}, GrowthRate: FastGrowth},
+	{Name: "eevee", Types: []string{Normal}, Rarity: Uncommon, CatchDifficulty: .3,
+		BaseStats: BaseStats{HP: 95, Damage: 90, Speed: 100, Defense: 90},
+		Evolutions: []Evolution{
+			{Into: "vaporeon", Item: WaterStoneName},
+			{Into: "jolteon", Item: ThunderStoneName},
+			{Into: "flareon", Item: FireStoneName},
+		}},
+	{Name: "vaporeon", Types: []string{Water}, Rarity: Rare, CatchDifficulty: .65,
+		BaseStats: BaseStats{HP: 150, Damage: 110, Speed: 100, Defense: 110}},
+	{Name: "jolteon", Types: []string{Electric}, Rarity: Rare, CatchDifficulty: .65,
+		BaseStats: BaseStats{HP: 105, Damage: 110, Speed: 150, Defense: 100}},
+	{Name: "flareon", Types: []string{Fire}, Rarity: Rare, CatchDifficulty: .65,
+		BaseStats: BaseStats{HP: 105, Damage: 140, Speed: 100, Defense: 105}},
+	{Name: "magikarp", Types: []string{Water}, Rarity: Common, CatchDifficulty: .05,
+		BaseStats: BaseStats{HP: 60, Damage: 30, Speed: 110, Defense: 85}, GrowthRate: SlowGrowth,
+		Evolutions: []Evolution{{Into: "gyarados", MinLevel: 20}}},
+	{Name: "gyarados", Types: []string{Water, Flying}, Rarity: Rare, CatchDifficulty: .75,
+		BaseStats: BaseStats{HP: 140, Damage: 140, Speed: 105, Defense: 115}, GrowthRate: SlowGrowth},
+	{Name: "dratini", Types: []string{Dragon}, Rarity: Rare, CatchDifficulty: .6,
+		BaseStats: BaseStats{HP: 85, Damage: 95, Speed: 95, Defense: 85}, GrowthRate: SlowGrowth,
+		Evolutions: []Evolution{{Into: "dragonite", MinLevel: 55}}},
+	{Name: "dragonite", Types: []string{Dragon, Flying}, Rarity: Legendary, CatchDifficulty: .9,
+		BaseStats: BaseStats{HP: 140, Damage: 150, Speed: 120, Defense: 130}, GrowthRate: SlowGrowth},
+	{Name: "mewtwo", Types: []string{Psychic}, Rarity: Legendary, CatchDifficulty: .97,
+		BaseStats: BaseStats{HP: 150, Damage: 160, Speed: 150, Defense: 125}, GrowthRate: SlowGrowth},
+})
+
+// LoadCatalogue reads a catalogue from a JSON or YAML file, chosen by the file extension
+func LoadCatalogue(filename string) (*Catalogue, error) {
+	var file catalogueFile
+	var catalogue *Catalogue
+
+	err := catalogues.Load(catalogueKind, filename, &file, func() (err error) {
+		catalogue, err = NewCatalogue(file.Species)
+		return err
+	})
+
+	return catalogue, err
+}
+
+// ParseCatalogue parses a catalogue in the given format, either ".json" or ".yaml"
+func ParseCatalogue(data []byte, format string) (*Catalogue, error) {
+	var file catalogueFile
+	var catalogue *Catalogue
+
+	err := catalogues.Parse(catalogueKind, data, format, &file, func() (err error) {
+		catalogue, err = NewCatalogue(file.Species)
+		return err
+	})
+
+	return catalogue, err
+}
+
+// NewCatalogue validates the species and checks that every evolution leads to a species of the
+// catalogue and that no species evolves back into itself
+func NewCatalogue(speciesList []Species) (*Catalogue, error) {
+	catalogue := &Catalogue{species: make(map[string]Species, len(speciesList))}
+
+	for _, s := range speciesList {
+		if s.Name == "" || len(s.Types) == 0 || s.CatchDifficulty < 0 || s.CatchDifficulty > 1 ||
+			s.BaseStats.HP <= 0 || s.BaseStats.Damage <= 0 || s.BaseStats.Speed <= 0 || s.BaseStats.Defense <= 0 {
+			return nil, ErrorInvalidSpecies
+		}
+
+		if _, ok := catalogue.species[s.Name]; ok {
+			return nil, ErrorDuplicateSpecies
+		}
+
+		switch s.GrowthRate {
+		case "", FastGrowth, MediumGrowth, SlowGrowth:
+		default:
+			return nil, ErrorInvalidSpecies
+		}
+
+		catalogue.species[s.Name] = s
+	}
+
+	for _, s := range catalogue.species {
+		for _, evolution := range s.Evolutions {
+			if _, ok := catalogue.species[evolution.Into]; !ok || (evolution.MinLevel <= 0 && evolution.Item == "") {
+				return nil, ErrorInvalidEvolution
+			}
+		}
+
+		if catalogue.evolvesInto(s.Name, s.Name, map[string]bool{}) {
+			return nil, ErrorEvolutionCycle
+		}
+	}
+
+	return catalogue, nil
+}
+
+func mustNewCatalogue(speciesList []Species) *Catalogue {
+	catalogue, err := NewCatalogue(speciesList)
+	if err != nil {
+		panic(err)
+	}
+
+	return catalogue
+}
+
+func (c *Catalogue) Get(name string) (Species, bool) {
+	s, ok := c.species[name]
+	return s, ok
+}
+
+// Names returns the names of every species, sorted
+func (c *Catalogue) Names() []string {
+	names := make([]string, 0, len(c.species))
+	for name := range c.species {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// SpeciesWithRarity returns the names of the species of the given rarity tier, sorted
+func (c *Catalogue) SpeciesWithRarity(rarity string) []string {
+	var names []string
+	for name, s := range c.species {
+		if s.Rarity == rarity {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// EvolutionFor finds the evolution a pokemon of the species can go through at the given level
+// using the given item, which is empty for level based evolutions
+func (c *Catalogue) EvolutionFor(name string, level int, itemName string) (Evolution, error) {
+	s, ok := c.species[name]
+	if !ok {
+		return Evolution{}, ErrorSpeciesNotFound
+	}
+
+	for _, evolution := range s.Evolutions {
+		if evolution.Matches(level, itemName) {
+			return evolution, nil
+		}
+	}
+
+	return Evolution{}, ErrorCannotEvolve
+}
+
+// EvolutionChain returns the species the given one can become, in the order they are reached.
+// Branching evolutions are listed in the order they were declared.
+func (c *Catalogue) EvolutionChain(name string) []string {
+	var chain []string
+
+	s, ok := c.species[name]
+	if !ok {
+		return chain
+	}
+
+	for _, evolution := range s.Evolutions {
+		chain = append(chain, evolution.Into)
+		chain = append(chain, c.EvolutionChain(evolution.Into)...)
+	}
+
+	return chain
+}
+
+// BaseSpecies returns the first species of the evolution chain the given species belongs to
+func (c *Catalogue) BaseSpecies(name string) string {
+	for other, s := range c.species {
+		for _, evolution := range s.Evolutions {
+			if evolution.Into == name {
+				return c.BaseSpecies(other)
+			}
+		}
+	}
+
+	return name
+}
+
+func (c *Catalogue) evolvesInto(from, target string, visited map[string]bool) bool {
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+
+	for _, evolution := range c.species[from].Evolutions {
+		if evolution.Into == target || c.evolvesInto(evolution.Into, target, visited) {
+			return true
+		}
+	}
+
+	return false
+}
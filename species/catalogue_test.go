@@ -0,0 +1,73 @@
+package species
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlCatalogue = `
+species:
+  - name: seedling
+    types: [GRASS]
+    rarity: COMMON
+    catch_difficulty: 0.2
+    base_stats: {hp: 80, damage: 80, speed: 90, defense: 80}
+    evolutions:
+      - into: sprout
+        min_level: 10
+  - name: sprout
+    types: [GRASS]
+    rarity: UNCOMMON
+    catch_difficulty: 0.5
+    base_stats: {hp: 120, damage: 110, speed: 100, defense: 100}
+`
+
+func TestParseCatalogueAndEvolveByLevel(t *testing.T) {
+	catalogue, err := ParseCatalogue([]byte(yamlCatalogue), ".yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"seedling", "sprout"}, catalogue.Names())
+	assert.Equal(t, []string{"sprout"}, catalogue.SpeciesWithRarity(Uncommon))
+
+	_, err = catalogue.EvolutionFor("seedling", 9, "")
+	assert.Equal(t, ErrorCannotEvolve, err)
+
+	evolution, err := catalogue.EvolutionFor("seedling", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "sprout", evolution.Into)
+}
+
+func TestItemEvolutionsNeedTheItem(t *testing.T) {
+	_, err := DefaultCatalogue.EvolutionFor("eevee", 50, "")
+	assert.Equal(t, ErrorCannotEvolve, err)
+
+	evolution, err := DefaultCatalogue.EvolutionFor("eevee", 1, FireStoneName)
+	assert.NoError(t, err)
+	assert.Equal(t, "flareon", evolution.Into)
+
+	_, err = DefaultCatalogue.EvolutionFor("missingno", 1, "")
+	assert.Equal(t, ErrorSpeciesNotFound, err)
+}
+
+func TestEvolutionChains(t *testing.T) {
+	assert.Equal(t, []string{"charmeleon", "charizard"}, DefaultCatalogue.EvolutionChain("charmander"))
+	assert.Equal(t, "charmander", DefaultCatalogue.BaseSpecies("charizard"))
+}
+
+func TestNewCatalogueRejectsInvalidEvolutions(t *testing.T) {
+	stats := BaseStats{HP: 100, Damage: 100, Speed: 100, Defense: 100}
+
+	_, err := NewCatalogue([]Species{
+		{Name: "a", Types: []string{Normal}, BaseStats: stats, Evolutions: []Evolution{{Into: "b", MinLevel: 5}}},
+	})
+	assert.Equal(t, ErrorInvalidEvolution, err)
+
+	_, err = NewCatalogue([]Species{
+		{Name: "a", Types: []string{Normal}, BaseStats: stats, Evolutions: []Evolution{{Into: "b", MinLevel: 5}}},
+		{Name: "b", Types: []string{Normal}, BaseStats: stats, Evolutions: []Evolution{{Into: "a", MinLevel: 5}}},
+	})
+	assert.Equal(t, ErrorEvolutionCycle, err)
+}
@@ -0,0 +1,16 @@
+package species
+
+import (
+	"github.com/NOVAPokemon/utils/catalogues"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorSpeciesNotFound      = errors.New("species not found")
+	ErrorInvalidSpecies       = errors.New("invalid species")
+	ErrorDuplicateSpecies     = errors.New("duplicate species")
+	ErrorInvalidEvolution     = errors.New("invalid evolution")
+	ErrorEvolutionCycle       = errors.New("species evolves into itself")
+	ErrorCannotEvolve         = errors.New("pokemon cannot evolve")
+	ErrorUnknownCatalogueType = catalogues.ErrorUnknownType
+)
@@ -0,0 +1,101 @@
+package species
+
+// Rarity tiers
+const (
+	Common    = "COMMON"
+	Uncommon  = "UNCOMMON"
+	Rare      = "RARE"
+	Legendary = "LEGENDARY"
+)
+
+// Types
+const (
+	Normal   = "NORMAL"
+	Fire     = "FIRE"
+	Water    = "WATER"
+	Grass    = "GRASS"
+	Electric = "ELECTRIC"
+	Poison   = "POISON"
+	Flying   = "FLYING"
+	Psychic  = "PSYCHIC"
+	Dragon   = "DRAGON"
+)
+
+// Growth rates, which set how much experience pokemons of the species need to level up
+const (
+	FastGrowth   = "FAST"
+	MediumGrowth = "MEDIUM"
+	SlowGrowth   = "SLOW"
+)
+
+// AverageBaseStat is the base stat of an average species, which gets exactly the generator's stats
+const AverageBaseStat = 100
+
+type (
+	// BaseStats are relative to an average species, which has AverageBaseStat in every stat
+	BaseStats struct {
+		HP      int `json:"hp" yaml:"hp"`
+		Damage  int `json:"damage" yaml:"damage"`
+		Speed   int `json:"speed" yaml:"speed"`
+		Defense int `json:"defense" yaml:"defense"`
+	}
+
+	// Evolution happens once the pokemon reaches MinLevel, when it is set, and the trainer uses
+	// Item, when it is set
+	Evolution struct {
+		Into     string `json:"into" yaml:"into"`
+		MinLevel int    `json:"min_level,omitempty" yaml:"min_level,omitempty"`
+		Item     string `json:"item,omitempty" yaml:"item,omitempty"`
+	}
+
+	// Species describes a kind of pokemon. CatchDifficulty goes from 0, always caught, to 1,
+	// never caught without a master ball. Species without a growth rate grow at MediumGrowth.
+	Species struct {
+		Name            string      `json:"name" yaml:"name"`
+		Types           []string    `json:"types" yaml:"types"`
+		Rarity          string      `json:"rarity" yaml:"rarity"`
+		CatchDifficulty float64     `json:"catch_difficulty" yaml:"catch_difficulty"`
+		BaseStats       BaseStats   `json:"base_stats" yaml:"base_stats"`
+		Evolutions      []Evolution `json:"evolutions,omitempty" yaml:"evolutions,omitempty"`
+		GrowthRate      string      `json:"growth_rate,omitempty" yaml:"growth_rate,omitempty"`
+	}
+)
+
+var AverageStats = BaseStats{
+	HP:      AverageBaseStat,
+	Damage:  AverageBaseStat,
+	Speed:   AverageBaseStat,
+	Defense: AverageBaseStat,
+}
+
+func (s *Species) Growth() string {
+	if s.GrowthRate == "" {
+		return MediumGrowth
+	}
+
+	return s.GrowthRate
+}
+
+func (s *Species) HasType(speciesType string) bool {
+	for _, t := range s.Types {
+		if t == speciesType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches tells if the evolution can happen at the given level using the given item, which may be empty
+func (evolution Evolution) Matches(level int, itemName string) bool {
+	if evolution.MinLevel > 0 && level < evolution.MinLevel {
+		return false
+	}
+
+	return evolution.Item == itemName
+}
+
+// Scale multiplies a stat by the base stat, as a fraction of the average one
+func Scale(stat float64, baseStat int) float64 {
+	return stat * float64(baseStat) / AverageBaseStat
+}
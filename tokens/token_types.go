@@ -21,7 +21,7 @@ type TrainerStatsToken struct {
 }
 
 type ItemsToken struct {
-	Items     map[string]items.Item
+	Items     items.Inventory
 	ItemsHash string
 	jwt.StandardClaims
 }
@@ -185,7 +185,7 @@ func AddPokemonsTokens(pokemons map[string]pokemons.Pokemon, headers http.Header
 	}
 }
 
-func AddItemsToken(items map[string]items.Item, headers http.Header) {
+func AddItemsToken(items items.Inventory, headers http.Header) {
 	expirationTime := time.Now().Add(JWTDuration)
 	trainerItemsToken := &ItemsToken{
 		Items:          items,
@@ -21,14 +21,14 @@ var id3 = primitive.NewObjectID()
 
 var pokemonsTest = map[string]pokemons.Pokemon{
 	id1.Hex(): {Id: id1.Hex()},
-	id2.Hex(): {Id: id2.Hex()},
+	id2.Hex(): {Id: id2.Hex(), IVs: &pokemons.IVs{HP: 31, Speed: 4}, Nature: pokemons.Timid, Shiny: true},
 	id3.Hex(): {Id: id3.Hex()},
 }
 
-var itemsToTest = map[string]items.Item{
-	"item1": {},
-	"item2": {},
-	"item3": {},
+var itemsToTest = items.Inventory{
+	"item1": {Name: "item1", Quantity: 1},
+	"item2": {Name: "item2", Quantity: 3},
+	"item3": {Name: "item3", Quantity: 99},
 }
 
 func TestMain(m *testing.M) {
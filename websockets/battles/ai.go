@@ -0,0 +1,311 @@
+package battles
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/mitchellh/mapstructure"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EasyDifficulty   = "EASY"
+	MediumDifficulty = "MEDIUM"
+	HardDifficulty   = "HARD"
+
+	mediumDifficultyLevel = 10
+	hardDifficultyLevel   = 25
+
+	defaultDefendThreshold = .3
+)
+
+type (
+	// AIView is what an AI player knows about the battle, built from the messages it receives
+	AIView struct {
+		Pokemons        map[string]pokemons.Pokemon
+		Items           items.Inventory
+		SelectedPokemon string
+		EnemyPokemon    *pokemons.Pokemon
+	}
+
+	// AIStrategy decides the next move of an AI player. Returning false means waiting.
+	AIStrategy interface {
+		NextAction(view *AIView, rng RandomSource) (Action, bool)
+	}
+
+	Difficulty struct {
+		Name          string
+		ThinkTime     time.Duration
+		MistakeChance float64
+	}
+
+	RandomStrategy struct{}
+
+	GreedyStrategy struct{}
+
+	// HPThresholdDefenderStrategy attacks until the selected pokemon drops below Threshold of its
+	// max HP and then heals if it can or defends otherwise
+	HPThresholdDefenderStrategy struct {
+		Threshold float64
+	}
+
+	// ScriptedStrategy repeats the given sequence of actions
+	ScriptedStrategy struct {
+		Script []Action
+		next   int
+	}
+
+	// AIPlayer plays a battle or raid as a lobby participant, sending the same messages a human
+	// player would
+	AIPlayer struct {
+		Username   string
+		Strategy   AIStrategy
+		Difficulty Difficulty
+		view       AIView
+		rng        RandomSource
+	}
+)
+
+var difficulties = map[string]Difficulty{
+	EasyDifficulty:   {Name: EasyDifficulty, ThinkTime: 3 * time.Second, MistakeChance: .4},
+	MediumDifficulty: {Name: MediumDifficulty, ThinkTime: 2 * time.Second, MistakeChance: .2},
+	HardDifficulty:   {Name: HardDifficulty, ThinkTime: 1 * time.Second, MistakeChance: .05},
+}
+
+// DifficultyForLevel picks the difficulty of an AI player from the level of its strongest pokemon
+func DifficultyForLevel(level int) Difficulty {
+	switch {
+	case level >= hardDifficultyLevel:
+		return difficulties[HardDifficulty]
+	case level >= mediumDifficultyLevel:
+		return difficulties[MediumDifficulty]
+	default:
+		return difficulties[EasyDifficulty]
+	}
+}
+
+func NewAIPlayer(username string, trainerPokemons map[string]pokemons.Pokemon, trainerItems items.Inventory,
+	strategy AIStrategy, rng RandomSource) *AIPlayer {
+	rng = random.OrGlobal(rng)
+
+	maxLevel := 0
+	for _, pokemon := range trainerPokemons {
+		if pokemon.Level > maxLevel {
+			maxLevel = pokemon.Level
+		}
+	}
+
+	// the view changes as the battle goes, so it must not share the trainer's pokemons and items
+	viewPokemons := make(map[string]pokemons.Pokemon, len(trainerPokemons))
+	for id, pokemon := range trainerPokemons {
+		viewPokemons[id] = pokemon
+	}
+
+	return &AIPlayer{
+		Username:   username,
+		Strategy:   strategy,
+		Difficulty: DifficultyForLevel(maxLevel),
+		view: AIView{
+			Pokemons: viewPokemons,
+			Items:    trainerItems.Clone(),
+		},
+		rng: rng,
+	}
+}
+
+// NewRaidBossAIPlayer creates the AI that controls a raid boss, which only attacks and defends.
+// Raids drive it through Raid.BossAction.
+func NewRaidBossAIPlayer(boss pokemons.Pokemon, rng RandomSource) *AIPlayer {
+	ai := NewAIPlayer(boss.Species, map[string]pokemons.Pokemon{boss.Id: boss}, nil,
+		&HPThresholdDefenderStrategy{Threshold: defaultDefendThreshold}, rng)
+	ai.view.SelectedPokemon = boss.Id
+
+	return ai
+}
+
+func (ai *AIPlayer) Play(fromLobby <-chan *ws.WebsocketMsg, toLobby chan<- *ws.WebsocketMsg,
+	finished <-chan struct{}) {
+	ticker := time.NewTicker(ai.Difficulty.ThinkTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-finished:
+			return
+		case msg, ok := <-fromLobby:
+			if !ok {
+				return
+			}
+
+			if done := ai.handleMessage(msg); done {
+				return
+			}
+		case <-ticker.C:
+			action, ok := ai.nextAction()
+			if !ok {
+				continue
+			}
+
+			select {
+			case toLobby <- actionToMessage(action):
+			case <-finished:
+				return
+			}
+		}
+	}
+}
+
+func (ai *AIPlayer) handleMessage(msg *ws.WebsocketMsg) (done bool) {
+	if msg == nil || msg.Content == nil {
+		return false
+	}
+
+	switch msg.Content.AppMsgType {
+	case UpdatePokemon:
+		updateMsg := &UpdatePokemonMessage{}
+		if err := mapstructure.Decode(msg.Content.Data, updateMsg); err != nil {
+			log.Error(err)
+			return false
+		}
+
+		if updateMsg.Owner {
+			ai.view.Pokemons[updateMsg.Pokemon.Id] = updateMsg.Pokemon
+		} else {
+			enemyPokemon := updateMsg.Pokemon
+			ai.view.EnemyPokemon = &enemyPokemon
+		}
+	case RemoveItem:
+		removeMsg := &RemoveItemMessage{}
+		if err := mapstructure.Decode(msg.Content.Data, removeMsg); err != nil {
+			log.Error(err)
+			return false
+		}
+
+		_ = ai.view.Items.Remove(removeMsg.ItemId, 1)
+	case ws.Finish:
+		return true
+	}
+
+	return false
+}
+
+func (ai *AIPlayer) nextAction() (Action, bool) {
+	if selected, ok := ai.view.Pokemons[ai.view.SelectedPokemon]; !ok || selected.HP <= 0 {
+		pokemonId, ok := ai.view.strongestAlivePokemon()
+		if !ok {
+			return Action{}, false
+		}
+
+		ai.view.SelectedPokemon = pokemonId
+		return Action{Type: SelectPokemon, PokemonId: pokemonId}, true
+	}
+
+	if ai.rng.Float64() < ai.Difficulty.MistakeChance {
+		return RandomStrategy{}.NextAction(&ai.view, ai.rng)
+	}
+
+	return ai.Strategy.NextAction(&ai.view, ai.rng)
+}
+
+func (RandomStrategy) NextAction(view *AIView, rng RandomSource) (Action, bool) {
+	switch rng.Intn(3) {
+	case 0:
+		return Action{Type: Defend}, true
+	case 1:
+		if itemId, ok := view.healingItem(); ok {
+			return Action{Type: UseItem, ItemId: itemId}, true
+		}
+		fallthrough
+	default:
+		return Action{Type: Attack}, true
+	}
+}
+
+func (GreedyStrategy) NextAction(view *AIView, _ RandomSource) (Action, bool) {
+	if view.EnemyPokemon != nil && view.EnemyPokemon.HP <= 0 {
+		return Action{}, false
+	}
+
+	return Action{Type: Attack}, true
+}
+
+func (s *HPThresholdDefenderStrategy) NextAction(view *AIView, _ RandomSource) (Action, bool) {
+	selected := view.Pokemons[view.SelectedPokemon]
+	if selected.MaxHP > 0 && float64(selected.HP)/float64(selected.MaxHP) < s.Threshold {
+		if itemId, ok := view.healingItem(); ok {
+			return Action{Type: UseItem, ItemId: itemId}, true
+		}
+		return Action{Type: Defend}, true
+	}
+
+	return Action{Type: Attack}, true
+}
+
+func (s *ScriptedStrategy) NextAction(_ *AIView, _ RandomSource) (Action, bool) {
+	if len(s.Script) == 0 {
+		return Action{}, false
+	}
+
+	action := s.Script[s.next]
+	s.next = (s.next + 1) % len(s.Script)
+	return action, true
+}
+
+func (view *AIView) strongestAlivePokemon() (string, bool) {
+	ids := make([]string, 0, len(view.Pokemons))
+	for id, pokemon := range view.Pokemons {
+		if pokemon.HP > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return "", false
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		first, second := view.Pokemons[ids[i]], view.Pokemons[ids[j]]
+		if first.Damage != second.Damage {
+			return first.Damage > second.Damage
+		}
+		return ids[i] < ids[j]
+	})
+
+	return ids[0], true
+}
+
+// healingItem finds an item that restores HP, ignoring revives, which are wasted on pokemons that
+// haven't fainted, and items with other effects
+func (view *AIView) healingItem() (string, bool) {
+	for _, name := range view.Items.Names() {
+		effect := view.Items[name].Effect
+		if !effect.Appliable {
+			continue
+		}
+
+		for _, component := range effect.GetComponents() {
+			if component.Type == items.HealEffectType || component.Type == items.HealPercentEffectType {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func actionToMessage(action Action) *ws.WebsocketMsg {
+	switch action.Type {
+	case Defend:
+		return DefendMessage{}.ConvertToWSMessage()
+	case UseItem:
+		return UseItemMessage{ItemId: action.ItemId}.ConvertToWSMessage()
+	case SelectPokemon:
+		return SelectPokemonMessage{PokemonId: action.PokemonId}.ConvertToWSMessage()
+	default:
+		return AttackMessage{}.ConvertToWSMessage()
+	}
+}
@@ -0,0 +1,192 @@
+package battles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/stretchr/testify/assert"
+)
+
+const testThinkTime = 10 * time.Millisecond
+
+func newTestAIView(hp int, inventory items.Inventory) *AIView {
+	return &AIView{
+		Pokemons:        map[string]pokemons.Pokemon{"p0": {Id: "p0", HP: hp, MaxHP: 100, Damage: 10}},
+		Items:           inventory,
+		SelectedPokemon: "p0",
+	}
+}
+
+func testInventory(names ...string) items.Inventory {
+	inventory := items.Inventory{}
+	for _, name := range names {
+		item, _ := items.DefaultCatalogue.NewItem(name)
+		inventory[name] = items.ItemStack{Name: name, Effect: item.Effect, Quantity: 1}
+	}
+
+	return inventory
+}
+
+func TestGreedyStrategy(t *testing.T) {
+	view := newTestAIView(100, nil)
+
+	action, ok := GreedyStrategy{}.NextAction(view, nil)
+	assert.True(t, ok)
+	assert.Equal(t, Attack, action.Type)
+
+	view.EnemyPokemon = &pokemons.Pokemon{Id: "enemy", HP: 0}
+	_, ok = GreedyStrategy{}.NextAction(view, nil)
+	assert.False(t, ok)
+}
+
+func TestHPThresholdDefenderStrategy(t *testing.T) {
+	strategy := &HPThresholdDefenderStrategy{Threshold: .5}
+
+	action, _ := strategy.NextAction(newTestAIView(80, nil), nil)
+	assert.Equal(t, Attack, action.Type)
+
+	// revives, boosts and balls don't heal
+	view := newTestAIView(20, testInventory(items.ReviveName, items.XAttackName, items.GreatBallName))
+	action, _ = strategy.NextAction(view, nil)
+	assert.Equal(t, Defend, action.Type)
+
+	view.Items = testInventory(items.ReviveName, items.SuperPotionName)
+	action, _ = strategy.NextAction(view, nil)
+	assert.Equal(t, Action{Type: UseItem, ItemId: items.SuperPotionName}, action)
+}
+
+func TestScriptedStrategy(t *testing.T) {
+	strategy := &ScriptedStrategy{Script: []Action{{Type: Attack}, {Type: Defend}}}
+
+	for _, expected := range []string{Attack, Defend, Attack} {
+		action, ok := strategy.NextAction(nil, nil)
+		assert.True(t, ok)
+		assert.Equal(t, expected, action.Type)
+	}
+
+	_, ok := (&ScriptedStrategy{}).NextAction(nil, nil)
+	assert.False(t, ok)
+}
+
+func TestRandomStrategy(t *testing.T) {
+	view := newTestAIView(50, testInventory(items.HealName))
+
+	action, _ := RandomStrategy{}.NextAction(view, fixedSource(0))
+	assert.Equal(t, Defend, action.Type)
+
+	// without healing items it attacks instead
+	view.Items = testInventory(items.ReviveName)
+	action, _ = RandomStrategy{}.NextAction(view, intnSource(1))
+	assert.Equal(t, Attack, action.Type)
+}
+
+func TestAIPlayerPlay(t *testing.T) {
+	ai := NewAIPlayer("ai", map[string]pokemons.Pokemon{
+		"weak":   {Id: "weak", HP: 10, MaxHP: 10, Damage: 1},
+		"strong": {Id: "strong", HP: 10, MaxHP: 10, Damage: 20},
+	}, nil, &ScriptedStrategy{Script: []Action{{Type: Attack}}}, fixedSource(1))
+	ai.Difficulty.ThinkTime = testThinkTime
+
+	fromLobby := make(chan *ws.WebsocketMsg)
+	toLobby := make(chan *ws.WebsocketMsg)
+	finished := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ai.Play(fromLobby, toLobby, finished)
+	}()
+
+	msg := <-toLobby
+	assert.Equal(t, SelectPokemon, msg.Content.AppMsgType)
+	assert.Equal(t, SelectPokemonMessage{PokemonId: "strong"}, msg.Content.Data)
+
+	msg = <-toLobby
+	assert.Equal(t, Attack, msg.Content.AppMsgType)
+
+	// once its pokemon faints it selects the other one. The AI may be waiting to send its next
+	// move, so messages are sent to it concurrently.
+	go func() {
+		fromLobby <- UpdatePokemonMessage{Owner: true, Pokemon: pokemons.Pokemon{Id: "strong", MaxHP: 10}}.
+			ConvertToWSMessage()
+	}()
+
+	msg = <-toLobby
+	for msg.Content.AppMsgType != SelectPokemon {
+		msg = <-toLobby
+	}
+	assert.Equal(t, SelectPokemonMessage{PokemonId: "weak"}, msg.Content.Data)
+
+	go func() {
+		fromLobby <- ws.NewStandardMsg(ws.Finish, nil)
+	}()
+
+	for {
+		select {
+		case <-toLobby:
+		case <-done:
+			return
+		case <-time.After(time.Second):
+			t.Fatal("AI player did not finish")
+		}
+	}
+}
+
+func TestAIPlayerDoesNotChangeTheTrainer(t *testing.T) {
+	trainerPokemons := map[string]pokemons.Pokemon{"p0": {Id: "p0", HP: 10, MaxHP: 10, Damage: 1}}
+	trainerItems := testInventory(items.HealName)
+	ai := NewAIPlayer("ai", trainerPokemons, trainerItems, GreedyStrategy{}, fixedSource(1))
+
+	ai.handleMessage(UpdatePokemonMessage{Owner: true, Pokemon: pokemons.Pokemon{Id: "p0", MaxHP: 10}}.
+		ConvertToWSMessage())
+	ai.handleMessage(RemoveItemMessage{ItemId: items.HealName}.ConvertToWSMessage(ws.TrackedInfo{}))
+
+	assert.Equal(t, 0, ai.view.Pokemons["p0"].HP)
+	assert.Equal(t, 10, trainerPokemons["p0"].HP)
+	assert.Equal(t, 0, ai.view.Items.Quantity(items.HealName))
+	assert.Equal(t, 1, trainerItems.Quantity(items.HealName))
+}
+
+func TestRaidBossAction(t *testing.T) {
+	clock := &testClock{now: testStart}
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, clock)
+	raid.bossAI.rng = fixedSource(1)
+
+	_, ok := raid.BossAction()
+	assert.False(t, ok)
+
+	assert.NoError(t, raid.Join("trainer0"))
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow + DefaultRaidSettings.Countdown)
+
+	action, ok := raid.BossAction()
+	assert.True(t, ok)
+	assert.Equal(t, Attack, action.Type)
+
+	_, err := raid.DealDamage("trainer0", 80)
+	assert.NoError(t, err)
+
+	action, _ = raid.BossAction()
+	assert.Equal(t, Defend, action.Type)
+}
+
+// intnSource always picks the same option
+type intnSource int
+
+func (s intnSource) Float64() float64 {
+	return 0
+}
+
+func (s intnSource) Intn(int) int {
+	return int(s)
+}
+
+func (s intnSource) Int63() int64 {
+	return 0
+}
+
+func (s intnSource) NormFloat64() float64 {
+	return 0
+}
@@ -0,0 +1,58 @@
+package battles
+
+import (
+	"github.com/NOVAPokemon/utils/items"
+)
+
+// ActiveBoosts are the item boosts a pokemon has during a battle. Each attack it makes uses one
+// turn of the damage boost and each attack it takes uses one turn of the defense boost.
+type ActiveBoosts struct {
+	Damage  items.Boost
+	Defense items.Boost
+}
+
+func (boosts *ActiveBoosts) apply(result *items.EffectResult) {
+	if result.DamageBoost != nil {
+		boosts.Damage = *result.DamageBoost
+	}
+
+	if result.DefenseBoost != nil {
+		boosts.Defense = *result.DefenseBoost
+	}
+}
+
+// useDamage returns the factor of the damage boost and spends one of its turns
+func (boosts *ActiveBoosts) useDamage() float64 {
+	factor := boosts.Damage.Factor()
+	if boosts.Damage.Turns > 0 {
+		boosts.Damage.Turns--
+	}
+	return factor
+}
+
+// useDefense returns the factor the damage taken is divided by and spends one of its turns
+func (boosts *ActiveBoosts) useDefense() float64 {
+	factor := boosts.Defense.Factor()
+	if boosts.Defense.Turns > 0 {
+		boosts.Defense.Turns--
+	}
+	return factor
+}
+
+func (boosts ActiveBoosts) active() bool {
+	return boosts.Damage.Turns > 0 || boosts.Defense.Turns > 0
+}
+
+func (status *TrainerBattleStatus) boostsOf(pokemonId string) *ActiveBoosts {
+	if status.Boosts == nil {
+		status.Boosts = map[string]*ActiveBoosts{}
+	}
+
+	boosts, ok := status.Boosts[pokemonId]
+	if !ok {
+		boosts = &ActiveBoosts{}
+		status.Boosts[pokemonId] = boosts
+	}
+
+	return boosts
+}
@@ -0,0 +1,251 @@
+package battles
+
+import (
+	"time"
+
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+	"github.com/NOVAPokemon/utils/species"
+	ws "github.com/NOVAPokemon/utils/websockets"
+)
+
+// Status conditions
+const (
+	NoCondition = ""
+	Poison      = "POISON"
+	Burn        = "BURN"
+	Paralysis   = "PARALYSIS"
+	Sleep       = "SLEEP"
+)
+
+const (
+	ConditionTickInterval  = 2 * time.Second
+	ConditionInflictChance = 0.3
+)
+
+type (
+	// StatusCondition is an over-time effect on a pokemon that lasts for TicksLeft ticks
+	StatusCondition struct {
+		Type      string
+		TicksLeft int
+	}
+
+	conditionEffect struct {
+		duration      int
+		damageDivisor int
+		damageFactor  float64
+		skipChance    float64
+	}
+)
+
+// poison and burn hurt every tick, burn also weakens attacks, paralysis
+// sometimes prevents the pokemon from moving and sleep always does
+var conditionEffects = map[string]conditionEffect{
+	Poison:    {duration: 8, damageDivisor: 16, damageFactor: 1},
+	Burn:      {duration: 5, damageDivisor: 16, damageFactor: .5},
+	Paralysis: {duration: 6, damageFactor: 1, skipChance: .25},
+	Sleep:     {duration: 3, damageFactor: 1, skipChance: 1},
+}
+
+// conditions the attacks of each type may inflict, in the order they are checked for pokemons with
+// more than one of these types
+var (
+	conditionTypes = []string{species.Poison, species.Fire, species.Electric, species.Psychic}
+	typeConditions = map[string]string{
+		species.Poison:   Poison,
+		species.Fire:     Burn,
+		species.Electric: Paralysis,
+		species.Psychic:  Sleep,
+	}
+)
+
+// AttackCondition is the condition the attacks of the pokemon may inflict. It depends on the
+// pokemon's species, so clients can't choose it.
+func AttackCondition(pokemon *pokemons.Pokemon) string {
+	s, ok := species.DefaultCatalogue.Get(pokemon.Species)
+	if !ok {
+		return NoCondition
+	}
+
+	for _, conditionType := range conditionTypes {
+		if s.HasType(conditionType) {
+			return typeConditions[conditionType]
+		}
+	}
+
+	return NoCondition
+}
+
+func NewStatusCondition(conditionType string) (*StatusCondition, error) {
+	effect, ok := conditionEffects[conditionType]
+	if !ok {
+		return nil, ErrorInvalidCondition
+	}
+
+	return &StatusCondition{
+		Type:      conditionType,
+		TicksLeft: effect.duration,
+	}, nil
+}
+
+func (condition *StatusCondition) damageFactor() float64 {
+	if condition == nil {
+		return 1
+	}
+
+	return conditionEffects[condition.Type].damageFactor
+}
+
+// CanMove rolls whether a pokemon under this condition is able to act
+func (condition *StatusCondition) CanMove() bool {
+	return condition.canMoveWith(random.Global)
+}
+
+func (condition *StatusCondition) canMoveWith(rng RandomSource) bool {
+	if condition == nil {
+		return true
+	}
+
+	return rng.Float64() >= conditionEffects[condition.Type].skipChance
+}
+
+// TickCondition applies one tick of the condition to the pokemon. It is used both for trainers'
+// pokemons and for raid bosses, which have no battle status of their own.
+func TickCondition(pokemon *pokemons.Pokemon, condition *StatusCondition) (hpChanged, expired bool) {
+	effect := conditionEffects[condition.Type]
+
+	if effect.damageDivisor > 0 && pokemon.HP > 0 {
+		damage := pokemon.MaxHP / effect.damageDivisor
+		if damage < 1 {
+			damage = 1
+		}
+
+		pokemon.HP -= damage
+		if pokemon.HP < 0 {
+			pokemon.HP = 0
+		}
+		hpChanged = true
+	}
+
+	condition.TicksLeft--
+	expired = condition.TicksLeft <= 0 || pokemon.HP == 0
+
+	return hpChanged, expired
+}
+
+func (status *TrainerBattleStatus) ConditionOf(pokemonId string) *StatusCondition {
+	if status.Conditions == nil {
+		return nil
+	}
+
+	return status.Conditions[pokemonId]
+}
+
+// InflictCondition sets the condition on the given pokemon unless it is fainted or already affected
+func (status *TrainerBattleStatus) InflictCondition(pokemon *pokemons.Pokemon, conditionType string) (bool, error) {
+	condition, err := NewStatusCondition(conditionType)
+	if err != nil {
+		return false, err
+	}
+
+	if pokemon.HP <= 0 || status.ConditionOf(pokemon.Id) != nil {
+		return false, nil
+	}
+
+	if status.Conditions == nil {
+		status.Conditions = map[string]*StatusCondition{}
+	}
+
+	status.Conditions[pokemon.Id] = condition
+	return true, nil
+}
+
+func (status *TrainerBattleStatus) CureCondition(pokemonId string) {
+	delete(status.Conditions, pokemonId)
+}
+
+// TickConditions applies one tick to every condition of the trainer's pokemons and
+// returns the pokemons that were changed
+func (status *TrainerBattleStatus) TickConditions() []*pokemons.Pokemon {
+	var changed []*pokemons.Pokemon
+
+	for pokemonId, condition := range status.Conditions {
+		pokemon, ok := status.TrainerPokemons[pokemonId]
+		if !ok {
+			delete(status.Conditions, pokemonId)
+			continue
+		}
+
+		hpChanged, expired := TickCondition(pokemon, condition)
+		if expired {
+			delete(status.Conditions, pokemonId)
+		}
+
+		if hpChanged || expired {
+			changed = append(changed, pokemon)
+		}
+	}
+
+	return changed
+}
+
+// HandleConditionsTick ticks the trainer's conditions and sends the changed pokemons to its
+// owner and to the other participants
+func HandleConditionsTick(issuer *TrainerBattleStatus, issuerChan chan *ws.WebsocketMsg,
+	otherChans ...chan *ws.WebsocketMsg) {
+	for _, pokemon := range issuer.TickConditions() {
+		condition := issuer.ConditionOf(pokemon.Id)
+		UpdateTrainerPokemonWithCondition(nil, *pokemon, condition, issuerChan, true)
+		for _, otherChan := range otherChans {
+			UpdateTrainerPokemonWithCondition(nil, *pokemon, condition, otherChan, false)
+		}
+	}
+}
+
+// HandleAttackCondition tries to inflict the condition of the attacker's species on the target's
+// selected pokemon, notifying both trainers if it succeeds
+func HandleAttackCondition(info *ws.TrackedInfo, attacker *pokemons.Pokemon, issuerChan chan *ws.WebsocketMsg,
+	target *TrainerBattleStatus, targetChan chan *ws.WebsocketMsg) bool {
+	conditionType := AttackCondition(attacker)
+	if conditionType == NoCondition || random.Global.Float64() >= ConditionInflictChance {
+		return false
+	}
+
+	inflicted, err := target.InflictCondition(target.SelectedPokemon, conditionType)
+	if err != nil {
+		issuerChan <- ErrorBattleMessage{
+			Info:  err.Error(),
+			Fatal: false,
+		}.ConvertToWSMessage(*info)
+		return false
+	}
+
+	if !inflicted {
+		return false
+	}
+
+	condition := target.ConditionOf(target.SelectedPokemon.Id)
+	issuerChan <- StatusMessage{
+		Message: StatusEnemyConditionInflicted,
+	}.ConvertToWSMessage(*info)
+	UpdateTrainerPokemonWithCondition(info, *target.SelectedPokemon, condition, issuerChan, false)
+	UpdateTrainerPokemonWithCondition(nil, *target.SelectedPokemon, condition, targetChan, true)
+
+	return true
+}
+
+// ConditionsTickLoop calls onTick every ConditionTickInterval until finish is closed. Battles and
+// raids share it to drive the over-time effects of their participants.
+func ConditionsTickLoop(finish <-chan struct{}, onTick func()) {
+	ticker := time.NewTicker(ConditionTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			onTick()
+		case <-finish:
+			return
+		}
+	}
+}
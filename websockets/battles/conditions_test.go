@@ -0,0 +1,115 @@
+package battles
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedSource always rolls the same number
+type fixedSource float64
+
+func (s fixedSource) Float64() float64 {
+	return float64(s)
+}
+
+func (s fixedSource) Intn(int) int {
+	return 0
+}
+
+func (s fixedSource) Int63() int64 {
+	return 0
+}
+
+func (s fixedSource) NormFloat64() float64 {
+	return 0
+}
+
+func TestAttackConditionDependsOnSpecies(t *testing.T) {
+	expected := map[string]string{
+		"bulbasaur":  Poison,
+		"charmander": Burn,
+		"pikachu":    Paralysis,
+		"mewtwo":     Sleep,
+		"squirtle":   NoCondition,
+		"missingno":  NoCondition,
+	}
+
+	for speciesName, condition := range expected {
+		assert.Equal(t, condition, AttackCondition(&pokemons.Pokemon{Species: speciesName}), speciesName)
+	}
+}
+
+func TestAttackInflictsConditionByChance(t *testing.T) {
+	engine := NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown},
+		fixedSource(ConditionInflictChance-.01), nil)
+
+	state, events := engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+	assert.Equal(t, Poison, state.Players[1].Conditions["p1"].Type)
+	assert.Equal(t, StatusEnemyConditionInflicted, events[len(events)-1].Message)
+
+	engine = NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown},
+		fixedSource(ConditionInflictChance), nil)
+
+	state, _ = engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+	assert.Empty(t, state.Players[1].Conditions)
+}
+
+func TestConditionEffects(t *testing.T) {
+	for _, conditionType := range []string{Poison, Burn, Paralysis, Sleep} {
+		condition, err := NewStatusCondition(conditionType)
+		assert.NoError(t, err)
+
+		pokemon := &pokemons.Pokemon{HP: 160, MaxHP: 160}
+		hpChanged, expired := TickCondition(pokemon, condition)
+		assert.False(t, expired, conditionType)
+
+		switch conditionType {
+		case Poison, Burn:
+			assert.True(t, hpChanged, conditionType)
+			assert.Equal(t, 150, pokemon.HP, conditionType)
+		default:
+			assert.False(t, hpChanged, conditionType)
+			assert.Equal(t, 160, pokemon.HP, conditionType)
+		}
+	}
+
+	burn, _ := NewStatusCondition(Burn)
+	assert.Equal(t, .5, burn.damageFactor())
+
+	paralysis, _ := NewStatusCondition(Paralysis)
+	assert.False(t, paralysis.canMoveWith(fixedSource(.1)))
+	assert.True(t, paralysis.canMoveWith(fixedSource(.5)))
+
+	sleep, _ := NewStatusCondition(Sleep)
+	assert.False(t, sleep.canMoveWith(fixedSource(.99)))
+
+	var none *StatusCondition
+	assert.True(t, none.canMoveWith(fixedSource(0)))
+	assert.Equal(t, 1., none.damageFactor())
+
+	_, err := NewStatusCondition("CONFUSION")
+	assert.Equal(t, ErrorInvalidCondition, err)
+}
+
+func TestConditionsExpire(t *testing.T) {
+	pokemon := &pokemons.Pokemon{Id: "p", HP: 100, MaxHP: 100}
+	status := &TrainerBattleStatus{TrainerPokemons: map[string]*pokemons.Pokemon{pokemon.Id: pokemon}}
+
+	inflicted, err := status.InflictCondition(pokemon, Sleep)
+	assert.NoError(t, err)
+	assert.True(t, inflicted)
+
+	inflicted, err = status.InflictCondition(pokemon, Poison)
+	assert.NoError(t, err)
+	assert.False(t, inflicted)
+
+	for i := 1; i < conditionEffects[Sleep].duration; i++ {
+		assert.Empty(t, status.TickConditions())
+		assert.NotNil(t, status.ConditionOf(pokemon.Id))
+	}
+
+	assert.Equal(t, []*pokemons.Pokemon{pokemon}, status.TickConditions())
+	assert.Nil(t, status.ConditionOf(pokemon.Id))
+}
@@ -10,9 +10,11 @@ import (
 )
 
 var (
-	StatusDefended = "You defended an attack"
-	StatusDefending = "You are defending"
-	StatusEnemyDefended = "Enemy defended your attack"
+	StatusDefended                = "You defended an attack"
+	StatusDefending               = "You are defending"
+	StatusEnemyDefended           = "Enemy defended your attack"
+	StatusCannotMove              = "Your pokemon could not move"
+	StatusEnemyConditionInflicted = "Your attack inflicted a condition on the enemy"
 )
 
 type (
@@ -27,13 +29,15 @@ type (
 		Username        string
 		TrainerStats    *utils.TrainerStats
 		TrainerPokemons map[string]*pokemons.Pokemon
-		TrainerItems    map[string]items.Item
+		TrainerItems    items.Inventory
 		SelectedPokemon *pokemons.Pokemon
 		AllPokemonsDead bool
 		Defending       bool
 		Cooldown        bool
 		CdTimer         *time.Timer
-		UsedItems       map[string]items.Item
+		UsedItems       items.Inventory
+		Conditions      map[string]*StatusCondition
+		Boosts          map[string]*ActiveBoosts
 	}
 )
 
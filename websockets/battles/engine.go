@@ -0,0 +1,509 @@
+package battles
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+)
+
+// Actions that can be applied to a battle state. Player moves reuse the message types.
+const (
+	TickAction = "TICK"
+)
+
+// Events produced when applying actions
+const (
+	PokemonUpdatedEvent = "POKEMON_UPDATED"
+	ItemRemovedEvent    = "ITEM_REMOVED"
+	StatusEvent         = "STATUS"
+	ErrorEvent          = "ERROR"
+	FinishedEvent       = "FINISHED"
+)
+
+const NoWinner = -1
+
+type (
+	// RandomSource is the source of randomness of the battle engine
+	RandomSource = random.Source
+
+	Clock interface {
+		Now() time.Time
+	}
+
+	SystemClock struct{}
+
+	// PlayerState is the state of one of the trainers in a battle
+	PlayerState struct {
+		Username        string
+		Pokemons        map[string]pokemons.Pokemon
+		Items           items.Inventory
+		UsedItems       items.Inventory
+		Conditions      map[string]StatusCondition
+		Boosts          map[string]ActiveBoosts
+		SelectedPokemon string
+		DefendingUntil  time.Time
+		CooldownUntil   time.Time
+	}
+
+	// BattleState is a snapshot of a battle between two players. It is only changed
+	// through Engine.Apply, which returns a new state every time.
+	BattleState struct {
+		Players   [2]PlayerState
+		Turn      int
+		StartedAt time.Time
+		Finished  bool
+		Winner    int
+	}
+
+	Action struct {
+		Type      string
+		Player    int
+		PokemonId string `json:",omitempty"`
+		ItemId    string `json:",omitempty"`
+	}
+
+	// Event describes something that changed in the battle. Player is the trainer the event
+	// refers to, not necessarily the one that issued the action.
+	Event struct {
+		Type      string
+		Player    int
+		Pokemon   *pokemons.Pokemon `json:",omitempty"`
+		Condition *StatusCondition  `json:",omitempty"`
+		ItemId    string            `json:",omitempty"`
+		Damage    int               `json:",omitempty"`
+		Message   string            `json:",omitempty"`
+	}
+
+	// TimedAction is an action together with the instant it was applied, used to replay battles
+	TimedAction struct {
+		Action Action
+		At     time.Time
+	}
+
+	EngineConfig struct {
+		Cooldown time.Duration
+	}
+
+	// Engine applies actions to a battle state without touching channels, timers or global
+	// randomness, so the same sequence of actions always produces the same battle.
+	Engine struct {
+		state  BattleState
+		config EngineConfig
+		rng    RandomSource
+		clock  Clock
+	}
+)
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func NewPlayerState(username string, trainerPokemons map[string]pokemons.Pokemon,
+	trainerItems items.Inventory) PlayerState {
+	return PlayerState{
+		Username:   username,
+		Pokemons:   trainerPokemons,
+		Items:      trainerItems,
+		UsedItems:  items.Inventory{},
+		Conditions: map[string]StatusCondition{},
+		Boosts:     map[string]ActiveBoosts{},
+	}
+}
+
+func NewBattleState(player0, player1 PlayerState, startedAt time.Time) BattleState {
+	return BattleState{
+		Players:   [2]PlayerState{player0, player1},
+		StartedAt: startedAt,
+		Winner:    NoWinner,
+	}
+}
+
+func NewEngine(initial BattleState, config EngineConfig, rng RandomSource, clock Clock) *Engine {
+	rng = random.OrGlobal(rng)
+
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return &Engine{
+		state:  initial.clone(),
+		config: config,
+		rng:    rng,
+		clock:  clock,
+	}
+}
+
+func (e *Engine) State() BattleState {
+	return e.state.clone()
+}
+
+// ApplyNow applies the action at the time given by the engine's clock
+func (e *Engine) ApplyNow(action Action) (BattleState, []Event) {
+	return e.Apply(action, e.clock.Now())
+}
+
+func (e *Engine) Apply(action Action, now time.Time) (BattleState, []Event) {
+	if e.state.Finished {
+		return e.State(), []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorBattleFinished.Error()}}
+	}
+
+	if action.Type != TickAction && (action.Player < 0 || action.Player > 1) {
+		return e.State(), []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidPlayer.Error()}}
+	}
+
+	next := e.state.clone()
+
+	var events []Event
+	switch action.Type {
+	case Attack:
+		events = e.attack(&next, action, now)
+	case Defend:
+		events = e.defend(&next, action, now)
+	case UseItem:
+		events = e.useItem(&next, action, now)
+	case SelectPokemon:
+		events = e.selectPokemon(&next, action)
+	case TickAction:
+		events = e.tick(&next)
+	default:
+		events = []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidAction.Error()}}
+	}
+
+	// rejected actions leave the battle as it was
+	if rejected(events) {
+		return e.State(), events
+	}
+
+	next.Turn++
+	events = append(events, checkFinished(&next)...)
+	e.state = next
+
+	return e.State(), events
+}
+
+func (e *Engine) canAct(player *PlayerState, action Action, now time.Time) (*pokemons.Pokemon, []Event) {
+	pokemon, ok := player.Pokemons[player.SelectedPokemon]
+	if !ok {
+		return nil, []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidPokemonSelected.Error()}}
+	}
+
+	if pokemon.HP <= 0 {
+		return nil, []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorPokemonNoHP.Error()}}
+	}
+
+	if now.Before(player.CooldownUntil) {
+		return nil, []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorCooldown.Error()}}
+	}
+
+	return &pokemon, nil
+}
+
+func (e *Engine) attack(state *BattleState, action Action, now time.Time) []Event {
+	issuer := &state.Players[action.Player]
+	target := &state.Players[1-action.Player]
+
+	pokemon, events := e.canAct(issuer, action, now)
+	if pokemon == nil {
+		return events
+	}
+
+	targetPokemon, ok := target.Pokemons[target.SelectedPokemon]
+	if !ok {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidPokemonSelected.Error()}}
+	}
+
+	issuer.CooldownUntil = now.Add(pokemon.Cooldown(e.config.Cooldown))
+
+	condition := issuer.condition(pokemon.Id)
+	if !condition.canMoveWith(e.rng) {
+		return []Event{{Type: StatusEvent, Player: action.Player, Message: StatusCannotMove}}
+	}
+
+	if now.Before(target.DefendingUntil) {
+		return []Event{
+			{Type: StatusEvent, Player: action.Player, Message: StatusEnemyDefended},
+			{Type: StatusEvent, Player: 1 - action.Player, Message: StatusDefended},
+		}
+	}
+
+	damageFactor := condition.damageFactor() * issuer.useBoost(pokemon.Id, (*ActiveBoosts).useDamage) /
+		target.useBoost(targetPokemon.Id, (*ActiveBoosts).useDefense)
+
+	hpBefore := targetPokemon.HP
+	applyAttackMoveWithFactor(pokemon, &targetPokemon, false, damageFactor)
+	target.Pokemons[targetPokemon.Id] = targetPokemon
+
+	events = []Event{target.pokemonUpdated(1-action.Player, targetPokemon, hpBefore-targetPokemon.HP)}
+
+	if conditionType := AttackCondition(pokemon); conditionType != NoCondition &&
+		e.rng.Float64() < ConditionInflictChance {
+		inflicted, err := target.inflict(targetPokemon, conditionType)
+		if err != nil {
+			return append(events, Event{Type: ErrorEvent, Player: action.Player, Message: err.Error()})
+		}
+
+		if inflicted {
+			events = append(events,
+				target.pokemonUpdated(1-action.Player, targetPokemon, 0),
+				Event{Type: StatusEvent, Player: action.Player, Message: StatusEnemyConditionInflicted})
+		}
+	}
+
+	return events
+}
+
+func (e *Engine) defend(state *BattleState, action Action, now time.Time) []Event {
+	issuer := &state.Players[action.Player]
+
+	pokemon, events := e.canAct(issuer, action, now)
+	if pokemon == nil {
+		return events
+	}
+
+	issuer.CooldownUntil = now.Add(pokemon.Cooldown(e.config.Cooldown))
+
+	if !issuer.condition(pokemon.Id).canMoveWith(e.rng) {
+		return []Event{{Type: StatusEvent, Player: action.Player, Message: StatusCannotMove}}
+	}
+
+	issuer.DefendingUntil = issuer.CooldownUntil
+
+	return []Event{{Type: StatusEvent, Player: action.Player, Message: StatusDefending}}
+}
+
+func (e *Engine) useItem(state *BattleState, action Action, now time.Time) []Event {
+	issuer := &state.Players[action.Player]
+
+	if now.Before(issuer.CooldownUntil) {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorCooldown.Error()}}
+	}
+
+	item, ok := issuer.Items.Item(action.ItemId)
+	if !ok {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidItemSelected.Error()}}
+	}
+
+	if !item.Effect.Appliable {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorItemNotAppliable.Error()}}
+	}
+
+	pokemon, ok := issuer.Pokemons[issuer.SelectedPokemon]
+	if !ok {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidPokemonSelected.Error()}}
+	}
+
+	result, err := item.ApplyWithResult(&pokemon)
+	if err != nil {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: err.Error()}}
+	}
+
+	if result.CuredStatus {
+		delete(issuer.Conditions, pokemon.Id)
+	}
+
+	boosts := issuer.Boosts[pokemon.Id]
+	boosts.apply(result)
+	if boosts.active() {
+		issuer.Boosts[pokemon.Id] = boosts
+	}
+
+	issuer.CooldownUntil = now.Add(pokemon.Cooldown(e.config.Cooldown))
+	issuer.Pokemons[pokemon.Id] = pokemon
+	issuer.UsedItems.AddUncapped(item, 1)
+	_ = issuer.Items.Remove(item.Name, 1)
+
+	return []Event{
+		issuer.pokemonUpdated(action.Player, pokemon, 0),
+		{Type: ItemRemovedEvent, Player: action.Player, ItemId: item.Id},
+	}
+}
+
+func (e *Engine) selectPokemon(state *BattleState, action Action) []Event {
+	issuer := &state.Players[action.Player]
+
+	pokemon, ok := issuer.Pokemons[action.PokemonId]
+	if !ok {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorInvalidPokemonSelected.Error()}}
+	}
+
+	if pokemon.HP <= 0 {
+		return []Event{{Type: ErrorEvent, Player: action.Player, Message: ErrorPokemonNoHP.Error()}}
+	}
+
+	issuer.SelectedPokemon = pokemon.Id
+
+	return []Event{issuer.pokemonUpdated(action.Player, pokemon, 0)}
+}
+
+func (e *Engine) tick(state *BattleState) []Event {
+	var events []Event
+
+	for playerNum := range state.Players {
+		player := &state.Players[playerNum]
+
+		for _, pokemonId := range sortedKeys(player.Conditions) {
+			condition := player.Conditions[pokemonId]
+			pokemon, ok := player.Pokemons[pokemonId]
+			if !ok {
+				delete(player.Conditions, pokemonId)
+				continue
+			}
+
+			hpBefore := pokemon.HP
+			hpChanged, expired := TickCondition(&pokemon, &condition)
+			player.Pokemons[pokemonId] = pokemon
+
+			if expired {
+				delete(player.Conditions, pokemonId)
+			} else {
+				player.Conditions[pokemonId] = condition
+			}
+
+			if hpChanged || expired {
+				events = append(events, player.pokemonUpdated(playerNum, pokemon, hpBefore-pokemon.HP))
+			}
+		}
+	}
+
+	return events
+}
+
+func checkFinished(state *BattleState) []Event {
+	for playerNum := range state.Players {
+		if state.Players[playerNum].allPokemonsDead() {
+			state.Finished = true
+			state.Winner = 1 - playerNum
+			return []Event{{Type: FinishedEvent, Player: state.Winner}}
+		}
+	}
+
+	return nil
+}
+
+// rejected tells if the action only produced errors
+func rejected(events []Event) bool {
+	for _, event := range events {
+		if event.Type != ErrorEvent {
+			return false
+		}
+	}
+	return len(events) > 0
+}
+
+func (player *PlayerState) allPokemonsDead() bool {
+	for _, pokemon := range player.Pokemons {
+		if pokemon.HP > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (player *PlayerState) condition(pokemonId string) *StatusCondition {
+	condition, ok := player.Conditions[pokemonId]
+	if !ok {
+		return nil
+	}
+
+	return &condition
+}
+
+func (player *PlayerState) inflict(pokemon pokemons.Pokemon, conditionType string) (bool, error) {
+	condition, err := NewStatusCondition(conditionType)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := player.Conditions[pokemon.Id]; ok || pokemon.HP <= 0 {
+		return false, nil
+	}
+
+	player.Conditions[pokemon.Id] = *condition
+	return true, nil
+}
+
+func (player *PlayerState) pokemonUpdated(playerNum int, pokemon pokemons.Pokemon, damage int) Event {
+	return Event{
+		Type:      PokemonUpdatedEvent,
+		Player:    playerNum,
+		Pokemon:   &pokemon,
+		Condition: player.condition(pokemon.Id),
+		Damage:    damage,
+	}
+}
+
+// useBoost spends a turn of one of the pokemon's boosts and returns its factor
+func (player *PlayerState) useBoost(pokemonId string, use func(*ActiveBoosts) float64) float64 {
+	boosts, ok := player.Boosts[pokemonId]
+	if !ok {
+		return 1
+	}
+
+	factor := use(&boosts)
+	if boosts.active() {
+		player.Boosts[pokemonId] = boosts
+	} else {
+		delete(player.Boosts, pokemonId)
+	}
+
+	return factor
+}
+
+func (state BattleState) clone() BattleState {
+	cloned := state
+	for i := range state.Players {
+		cloned.Players[i] = state.Players[i].clone()
+	}
+	return cloned
+}
+
+func (player PlayerState) clone() PlayerState {
+	cloned := player
+
+	cloned.Pokemons = make(map[string]pokemons.Pokemon, len(player.Pokemons))
+	for id, pokemon := range player.Pokemons {
+		cloned.Pokemons[id] = pokemon
+	}
+
+	cloned.Items = player.Items.Clone()
+	cloned.UsedItems = player.UsedItems.Clone()
+
+	cloned.Conditions = make(map[string]StatusCondition, len(player.Conditions))
+	for id, condition := range player.Conditions {
+		cloned.Conditions[id] = condition
+	}
+
+	cloned.Boosts = make(map[string]ActiveBoosts, len(player.Boosts))
+	for id, boosts := range player.Boosts {
+		cloned.Boosts[id] = boosts
+	}
+
+	return cloned
+}
+
+// Replay applies the actions in order to the initial state and returns the final state together
+// with every event produced. Using a seeded random source makes the result reproducible.
+func Replay(initial BattleState, config EngineConfig, rng RandomSource, actions []TimedAction) (BattleState,
+	[]Event) {
+	engine := NewEngine(initial, config, rng, nil)
+
+	var allEvents []Event
+	for _, timedAction := range actions {
+		_, events := engine.Apply(timedAction.Action, timedAction.At)
+		allEvents = append(allEvents, events...)
+	}
+
+	return engine.State(), allEvents
+}
+
+func sortedKeys(conditions map[string]StatusCondition) []string {
+	keys := make([]string, 0, len(conditions))
+	for key := range conditions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
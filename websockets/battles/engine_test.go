@@ -0,0 +1,132 @@
+package battles
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCooldown = time.Second
+
+var testStart = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+var battleStateMockup = BattleState{
+	Players: [2]PlayerState{
+		{
+			Username: "trainer0",
+			Pokemons: map[string]pokemons.Pokemon{
+				"p0": {Id: "p0", Species: "bulbasaur", HP: 50, MaxHP: 50, Damage: 10},
+			},
+			Items: items.Inventory{
+				items.HealName: {Name: items.HealName, Effect: items.HealEffect, Quantity: 1},
+			},
+			SelectedPokemon: "p0",
+		},
+		{
+			Username:        "trainer1",
+			Pokemons:        map[string]pokemons.Pokemon{"p1": {Id: "p1", HP: 30, MaxHP: 30, Damage: 5}},
+			Items:           items.Inventory{},
+			SelectedPokemon: "p1",
+		},
+	},
+	StartedAt: testStart,
+	Winner:    NoWinner,
+}
+
+func testActions() []TimedAction {
+	return []TimedAction{
+		{Action: Action{Type: Attack, Player: 0}, At: testStart},
+		{Action: Action{Type: Defend, Player: 1}, At: testStart},
+		{Action: Action{Type: TickAction}, At: testStart.Add(ConditionTickInterval)},
+		{Action: Action{Type: Attack, Player: 0}, At: testStart.Add(testCooldown)},
+		{Action: Action{Type: Attack, Player: 1}, At: testStart.Add(2 * testCooldown)},
+		{Action: Action{Type: UseItem, Player: 0, ItemId: items.HealName}, At: testStart.Add(3 * testCooldown)},
+		{Action: Action{Type: Attack, Player: 0}, At: testStart.Add(4 * testCooldown)},
+		{Action: Action{Type: Attack, Player: 0}, At: testStart.Add(5 * testCooldown)},
+	}
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	config := EngineConfig{Cooldown: testCooldown}
+
+	firstState, firstEvents := Replay(battleStateMockup, config, rand.New(rand.NewSource(42)), testActions())
+	secondState, secondEvents := Replay(battleStateMockup, config, rand.New(rand.NewSource(42)), testActions())
+
+	assert.Equal(t, firstState, secondState)
+	assert.Equal(t, firstEvents, secondEvents)
+}
+
+func TestAttackRespectsCooldownAndDefense(t *testing.T) {
+	engine := NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+
+	state, _ := engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+	assert.Equal(t, 20, state.Players[1].Pokemons["p1"].HP)
+
+	_, events := engine.Apply(Action{Type: Attack, Player: 0}, testStart.Add(testCooldown/2))
+	assert.Equal(t, ErrorEvent, events[0].Type)
+	assert.Equal(t, ErrorCooldown.Error(), events[0].Message)
+	assert.Equal(t, 1, engine.State().Turn)
+
+	engine.Apply(Action{Type: Defend, Player: 1}, testStart.Add(testCooldown/2))
+	state, events = engine.Apply(Action{Type: Attack, Player: 0}, testStart.Add(testCooldown))
+	assert.Equal(t, 20, state.Players[1].Pokemons["p1"].HP)
+	assert.Equal(t, StatusEnemyDefended, events[0].Message)
+}
+
+func TestAttackWithoutTargetIsRejected(t *testing.T) {
+	initial := battleStateMockup.clone()
+	initial.Players[1].SelectedPokemon = ""
+	engine := NewEngine(initial, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+
+	state, events := engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+	assert.Equal(t, []Event{{Type: ErrorEvent, Player: 0, Message: ErrorInvalidPokemonSelected.Error()}}, events)
+	assert.Equal(t, 0, state.Turn)
+	assert.True(t, state.Players[0].CooldownUntil.IsZero())
+}
+
+func TestBattleFinishesWhenAllPokemonsDie(t *testing.T) {
+	engine := NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+
+	var (
+		state  BattleState
+		events []Event
+	)
+	for i := 0; i < 3; i++ {
+		state, events = engine.Apply(Action{Type: Attack, Player: 0}, testStart.Add(time.Duration(i)*testCooldown))
+	}
+
+	assert.True(t, state.Finished)
+	assert.Equal(t, 0, state.Winner)
+	assert.Equal(t, FinishedEvent, events[len(events)-1].Type)
+}
+
+func TestApplyDoesNotChangePreviousStates(t *testing.T) {
+	engine := NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+
+	before := engine.State()
+	engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+
+	assert.Equal(t, 30, before.Players[1].Pokemons["p1"].HP)
+}
+
+func TestSpeedAndDefenseChangeCooldownAndDamage(t *testing.T) {
+	initial := battleStateMockup.clone()
+	fast := initial.Players[0].Pokemons["p0"]
+	fast.Speed = 2 * pokemons.ReferenceSpeed
+	initial.Players[0].Pokemons["p0"] = fast
+	tough := initial.Players[1].Pokemons["p1"]
+	tough.Defense = pokemons.ReferenceDefense
+	initial.Players[1].Pokemons["p1"] = tough
+
+	engine := NewEngine(initial, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+
+	state, _ := engine.Apply(Action{Type: Attack, Player: 0}, testStart)
+	assert.Equal(t, 25, state.Players[1].Pokemons["p1"].HP)
+
+	state, _ = engine.Apply(Action{Type: Attack, Player: 0}, testStart.Add(testCooldown/2))
+	assert.Equal(t, 20, state.Players[1].Pokemons["p1"].HP)
+}
@@ -11,5 +11,13 @@ var (
 	ErrorCooldown               = errors.New("player still in cooldown")
 	ErrorInvalidItemSelected    = errors.New("invalid item selected")
 	ErrorItemNotAppliable       = errors.New("error item not appliable")
+	ErrorInvalidCondition       = errors.New("invalid status condition")
+	ErrorInvalidAction          = errors.New("invalid battle action")
+	ErrorInvalidPlayer          = errors.New("invalid battle player")
+	ErrorBattleFinished         = errors.New("battle already finished")
+	ErrorRaidNotForming         = errors.New("raid is not accepting trainers")
+	ErrorRaidFull               = errors.New("raid is full")
+	ErrorRaidNotFighting        = errors.New("raid is not in a fighting phase")
+	ErrorAlreadyInRaid          = errors.New("trainer already in raid")
+	ErrorNotInRaid              = errors.New("trainer not in raid")
 )
-
@@ -0,0 +1,151 @@
+package battles
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+)
+
+// Player indexes used in the log for actions that no trainer made
+const (
+	// BossPlayer is the raid boss
+	BossPlayer = -1
+	// SystemPlayer is the battle itself, such as for the ticks of the status conditions
+	SystemPlayer = -2
+)
+
+// BattleRecorder keeps the log of every action applied to a battle or raid and of the events
+// they produced, so it can be persisted and replayed once the lobby finishes
+type BattleRecorder struct {
+	lock      sync.Mutex
+	battle    utils.Battle
+	startedAt time.Time
+}
+
+func NewBattleRecorder(battleId string, participants []string, raid bool, startedAt time.Time) *BattleRecorder {
+	battle := utils.Battle{
+		Id:           battleId,
+		Raid:         raid,
+		Participants: participants,
+		StartedAt:    toMillis(startedAt),
+		Log:          []utils.BattleLogEntry{},
+	}
+
+	if !raid && len(participants) == 2 {
+		battle.Trainer1 = participants[0]
+		battle.Trainer2 = participants[1]
+	}
+
+	return &BattleRecorder{
+		battle:    battle,
+		startedAt: startedAt,
+	}
+}
+
+func (r *BattleRecorder) Record(action Action, events []Event, at time.Time) {
+	entry := utils.BattleLogEntry{
+		Offset: at.Sub(r.startedAt).Milliseconds(),
+		Player: action.Player,
+		Action: action.Type,
+		Arg:    actionArg(action),
+		Events: make([]utils.BattleLogEvent, 0, len(events)),
+	}
+
+	if action.Type == TickAction {
+		entry.Player = SystemPlayer
+	}
+
+	for _, event := range events {
+		logEvent := utils.BattleLogEvent{
+			Type:   event.Type,
+			Player: event.Player,
+			Damage: event.Damage,
+			Info:   event.Message,
+		}
+
+		if event.Pokemon != nil {
+			logEvent.PokemonId = event.Pokemon.Id
+			logEvent.HP = event.Pokemon.HP
+		} else if event.ItemId != "" {
+			logEvent.Info = event.ItemId
+		}
+
+		entry.Events = append(entry.Events, logEvent)
+	}
+
+	r.lock.Lock()
+	r.battle.Log = append(r.battle.Log, entry)
+	r.lock.Unlock()
+}
+
+// Finish closes the log and computes the summary of every participant
+func (r *BattleRecorder) Finish(winner string, at time.Time) utils.Battle {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.battle.Winner = winner
+	r.battle.FinishedAt = toMillis(at)
+	r.battle.Summaries = SummarizeBattle(r.battle)
+
+	return r.battle
+}
+
+// SummarizeBattle computes, for each participant, the damage dealt and taken, the items
+// used and the number of turns played
+func SummarizeBattle(battle utils.Battle) map[string]utils.BattleSummary {
+	summaries := make([]utils.BattleSummary, len(battle.Participants))
+
+	for _, entry := range battle.Log {
+		issuer := entry.Player
+		if issuer >= 0 && issuer < len(summaries) {
+			summaries[issuer].Turns++
+		}
+
+		for _, event := range entry.Events {
+			switch event.Type {
+			case PokemonUpdatedEvent:
+				if event.Damage <= 0 {
+					continue
+				}
+
+				if event.Player >= 0 && event.Player < len(summaries) {
+					summaries[event.Player].DamageTaken += event.Damage
+				}
+
+				if issuer >= 0 && issuer < len(summaries) && issuer != event.Player {
+					summaries[issuer].DamageDealt += event.Damage
+				}
+			case ItemRemovedEvent:
+				if event.Player >= 0 && event.Player < len(summaries) {
+					summaries[event.Player].ItemsUsed++
+				}
+			}
+		}
+	}
+
+	duration := battle.FinishedAt - battle.StartedAt
+
+	summariesByTrainer := make(map[string]utils.BattleSummary, len(battle.Participants))
+	for i, participant := range battle.Participants {
+		summaries[i].Duration = duration
+		summariesByTrainer[participant] = summaries[i]
+	}
+
+	return summariesByTrainer
+}
+
+func actionArg(action Action) string {
+	switch action.Type {
+	case UseItem:
+		return action.ItemId
+	case SelectPokemon:
+		return action.PokemonId
+	default:
+		return ""
+	}
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
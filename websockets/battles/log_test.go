@@ -0,0 +1,46 @@
+package battles
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderSummarizesBattle(t *testing.T) {
+	engine := NewEngine(battleStateMockup, EngineConfig{Cooldown: testCooldown}, rand.New(rand.NewSource(1)), nil)
+	recorder := NewBattleRecorder("battle", []string{"trainer0", "trainer1"}, false, testStart)
+
+	for _, timedAction := range testActions() {
+		_, events := engine.Apply(timedAction.Action, timedAction.At)
+		recorder.Record(timedAction.Action, events, timedAction.At)
+	}
+
+	battle := recorder.Finish("trainer0", testStart.Add(6*testCooldown))
+
+	assert.Len(t, battle.Log, len(testActions()))
+	assert.Equal(t, SystemPlayer, battle.Log[2].Player)
+	assert.Equal(t, "trainer1", battle.Trainer2)
+
+	summary := battle.Summaries["trainer0"]
+	assert.Equal(t, 1, summary.ItemsUsed)
+	assert.Equal(t, 5, summary.Turns)
+	assert.Equal(t, (6 * testCooldown).Milliseconds(), summary.Duration)
+	assert.Equal(t, summary.DamageDealt, battle.Summaries["trainer1"].DamageTaken-tickDamage(battle.Log, 1))
+}
+
+func tickDamage(log []utils.BattleLogEntry, player int) int {
+	damage := 0
+	for _, entry := range log {
+		if entry.Action != TickAction {
+			continue
+		}
+		for _, event := range entry.Events {
+			if event.Player == player {
+				damage += event.Damage
+			}
+		}
+	}
+	return damage
+}
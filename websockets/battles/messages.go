@@ -12,6 +12,7 @@ const (
 	Challenge = "CHALLENGE"
 
 	StartRaid     = "START_RAID"
+	RaidPhase     = "RAID_PHASE"
 	StartBattle   = "START_BATTLE"
 	RejectBattle  = "REJECT_BATTLE"
 	ErrorBattle   = "ERROR_BATTLE"
@@ -30,6 +31,15 @@ func (s StartRaidMessage) ConvertToWSMessage() *websockets.WebsocketMsg {
 	return websockets.NewStandardMsg(StartRaid, nil)
 }
 
+type RaidPhaseMessage struct {
+	Phase  string
+	BossHP int
+}
+
+func (rpMsg RaidPhaseMessage) ConvertToWSMessage() *websockets.WebsocketMsg {
+	return websockets.NewStandardMsg(RaidPhase, rpMsg)
+}
+
 type StartBattleMessage struct{}
 
 func (s StartBattleMessage) ConvertToWSMessage(info websockets.TrackedInfo) *websockets.WebsocketMsg {
@@ -64,8 +74,9 @@ func (aMsg AttackMessage) ConvertToWSMessage() *websockets.WebsocketMsg {
 }
 
 type UpdatePokemonMessage struct {
-	Owner   bool
-	Pokemon pokemons.Pokemon
+	Owner     bool
+	Pokemon   pokemons.Pokemon
+	Condition *StatusCondition `json:",omitempty"`
 }
 
 func (upMsg UpdatePokemonMessage) ConvertToWSMessage() *websockets.WebsocketMsg {
@@ -107,3 +118,50 @@ type SelectPokemonMessage struct {
 func (spMsg SelectPokemonMessage) ConvertToWSMessage() *websockets.WebsocketMsg {
 	return websockets.NewRequestMsg(SelectPokemon, spMsg)
 }
+
+// EventsToMessages converts the events produced by the battle engine into the messages
+// the given player should receive
+func EventsToMessages(events []Event, receiver int, info *websockets.TrackedInfo) []*websockets.WebsocketMsg {
+	var msgs []*websockets.WebsocketMsg
+
+	for _, event := range events {
+		switch event.Type {
+		case PokemonUpdatedEvent:
+			updateMsg := UpdatePokemonMessage{
+				Owner:     event.Player == receiver,
+				Pokemon:   *event.Pokemon,
+				Condition: event.Condition,
+			}
+			if info != nil {
+				msgs = append(msgs, updateMsg.ConvertToWSMessageWithInfo(*info))
+			} else {
+				msgs = append(msgs, updateMsg.ConvertToWSMessage())
+			}
+		case ItemRemovedEvent:
+			if event.Player == receiver {
+				msgs = append(msgs, convertWithOptionalInfo(RemoveItem, RemoveItemMessage{ItemId: event.ItemId}, info))
+			}
+		case StatusEvent:
+			if event.Player == receiver {
+				msgs = append(msgs, convertWithOptionalInfo(Status, StatusMessage{Message: event.Message}, info))
+			}
+		case ErrorEvent:
+			if event.Player == receiver {
+				msgs = append(msgs, convertWithOptionalInfo(ErrorBattle,
+					ErrorBattleMessage{Info: event.Message, Fatal: false}, info))
+			}
+		case FinishedEvent:
+			msgs = append(msgs, websockets.FinishMessage{Success: event.Player == receiver}.ConvertToWSMessage())
+		}
+	}
+
+	return msgs
+}
+
+func convertWithOptionalInfo(msgType string, content interface{}, info *websockets.TrackedInfo) *websockets.WebsocketMsg {
+	if info != nil {
+		return websockets.NewReplyMsg(msgType, content, *info)
+	}
+
+	return websockets.NewStandardMsg(msgType, content)
+}
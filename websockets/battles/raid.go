@@ -0,0 +1,391 @@
+package battles
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NOVAPokemon/utils/experience"
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/NOVAPokemon/utils/random"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Raid phases
+const (
+	RaidFormingPhase   = "FORMING"
+	RaidCountdownPhase = "COUNTDOWN"
+	RaidFightingPhase  = "FIGHTING"
+	RaidEnragedPhase   = "ENRAGED"
+	RaidVictoryPhase   = "VICTORY"
+	RaidDefeatPhase    = "DEFEAT"
+	RaidCancelledPhase = "CANCELLED"
+)
+
+type (
+	RaidSettings struct {
+		FormingWindow   time.Duration
+		Countdown       time.Duration
+		TimeLimit       time.Duration
+		EnrageDuration  time.Duration
+		MinParticipants int
+		MaxParticipants int
+		// HPScaling is the fraction of the boss base HP added for each participant after the first
+		HPScaling          float64
+		EnrageDamageFactor float64
+		// RewardItems is the number of items shared by the participants of a won raid
+		RewardItems int
+		ItemsPool   []items.Item
+	}
+
+	// RaidReward is what a participant gets when the raid ends. PokemonXP is the experience
+	// each of the participant's pokemons gains.
+	RaidReward struct {
+		Damage    int
+		PokemonXP float64
+		Items     []items.Item
+	}
+
+	// Raid manages the lifecycle of a raid against a boss: it is formed while trainers join,
+	// fought after a countdown, enraged once the time limit is reached and finished when the
+	// boss or every trainer faints
+	Raid struct {
+		lock         sync.Mutex
+		settings     RaidSettings
+		clock        Clock
+		boss         *pokemons.Pokemon
+		baseMaxHP    int
+		phase        string
+		phaseStarted time.Time
+		participants []string
+		damage       map[string]int
+		bossAI       *AIPlayer
+	}
+)
+
+var DefaultRaidSettings = RaidSettings{
+	FormingWindow:      30 * time.Second,
+	Countdown:          5 * time.Second,
+	TimeLimit:          3 * time.Minute,
+	EnrageDuration:     30 * time.Second,
+	MinParticipants:    1,
+	MaxParticipants:    10,
+	HPScaling:          .5,
+	EnrageDamageFactor: 2,
+	RewardItems:        5,
+	ItemsPool:          []items.Item{items.HealItem, items.ReviveItem, items.PokeBallItem},
+}
+
+// NewRaid creates a raid against a copy of the boss, so the given pokemon is left untouched when
+// the raid scales and damages it
+func NewRaid(boss *pokemons.Pokemon, settings RaidSettings, clock Clock) *Raid {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	raidBoss := *boss
+
+	return &Raid{
+		settings:     settings,
+		clock:        clock,
+		boss:         &raidBoss,
+		baseMaxHP:    raidBoss.MaxHP,
+		phase:        RaidFormingPhase,
+		phaseStarted: clock.Now(),
+		damage:       map[string]int{},
+		bossAI:       NewRaidBossAIPlayer(raidBoss, nil),
+	}
+}
+
+func (r *Raid) Phase() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.phase
+}
+
+func (r *Raid) Boss() pokemons.Pokemon {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return *r.boss
+}
+
+func (r *Raid) Participants() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	participants := make([]string, len(r.participants))
+	copy(participants, r.participants)
+	return participants
+}
+
+func (r *Raid) Join(username string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance(r.clock.Now())
+
+	if r.phase != RaidFormingPhase {
+		return ErrorRaidNotForming
+	}
+
+	if len(r.participants) >= r.settings.MaxParticipants {
+		return ErrorRaidFull
+	}
+
+	if _, ok := r.damage[username]; ok {
+		return ErrorAlreadyInRaid
+	}
+
+	r.participants = append(r.participants, username)
+	r.damage[username] = 0
+	return nil
+}
+
+// Leave takes the trainer out of a raid that is still forming
+func (r *Raid) Leave(username string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance(r.clock.Now())
+
+	if r.phase != RaidFormingPhase {
+		return ErrorRaidNotForming
+	}
+
+	if _, ok := r.damage[username]; !ok {
+		return ErrorNotInRaid
+	}
+
+	for i, participant := range r.participants {
+		if participant == username {
+			r.participants = append(r.participants[:i], r.participants[i+1:]...)
+			break
+		}
+	}
+
+	delete(r.damage, username)
+	return nil
+}
+
+// Update moves the raid to the phase it should be in at the current time and returns
+// it, along with whether it changed
+func (r *Raid) Update() (string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	previous := r.phase
+	r.advance(r.clock.Now())
+	return r.phase, previous != r.phase
+}
+
+// DealDamage applies damage done by a participant to the boss and returns the boss' remaining HP
+func (r *Raid) DealDamage(username string, damage int) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance(r.clock.Now())
+
+	if r.phase != RaidFightingPhase && r.phase != RaidEnragedPhase {
+		return r.boss.HP, ErrorRaidNotFighting
+	}
+
+	if _, ok := r.damage[username]; !ok {
+		return r.boss.HP, ErrorNotInRaid
+	}
+
+	if damage > r.boss.HP {
+		damage = r.boss.HP
+	}
+
+	r.boss.HP -= damage
+	r.damage[username] += damage
+
+	if r.boss.HP <= 0 {
+		r.setPhase(RaidVictoryPhase, r.clock.Now())
+	}
+
+	return r.boss.HP, nil
+}
+
+// BossDamage is the damage the boss deals with each attack, boosted while enraged
+func (r *Raid) BossDamage() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.phase == RaidEnragedPhase {
+		return int(math.Ceil(float64(r.boss.Damage) * r.settings.EnrageDamageFactor))
+	}
+
+	return r.boss.Damage
+}
+
+// BossAction is the next move of the boss, decided by its AI from its current HP. The boss only
+// acts while the raid is being fought.
+func (r *Raid) BossAction() (Action, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance(r.clock.Now())
+
+	if r.phase != RaidFightingPhase && r.phase != RaidEnragedPhase {
+		return Action{}, false
+	}
+
+	r.bossAI.view.Pokemons[r.boss.Id] = *r.boss
+	return r.bossAI.nextAction()
+}
+
+// TrainersDefeated ends the raid when every participant ran out of pokemons
+func (r *Raid) TrainersDefeated() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.phase == RaidFightingPhase || r.phase == RaidEnragedPhase {
+		r.setPhase(RaidDefeatPhase, r.clock.Now())
+	}
+}
+
+func (r *Raid) Finished() bool {
+	switch r.Phase() {
+	case RaidVictoryPhase, RaidDefeatPhase, RaidCancelledPhase:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rewards computes what each participant gets from a finished raid. Every participant's pokemons
+// gain XP, while the reward items of a won raid are split proportionally to the damage dealt.
+func (r *Raid) Rewards(rng RandomSource) map[string]RaidReward {
+	rng = random.OrGlobal(rng)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	rewards := make(map[string]RaidReward, len(r.participants))
+	if r.phase != RaidVictoryPhase && r.phase != RaidDefeatPhase {
+		return rewards
+	}
+
+	won := r.phase == RaidVictoryPhase
+
+	var itemCounts map[string]int
+	if won {
+		itemCounts = splitProportionally(r.settings.RewardItems, r.participants, r.damage)
+	}
+
+	for _, participant := range r.participants {
+		rewardItems := make([]items.Item, 0, itemCounts[participant])
+		for i := 0; i < itemCounts[participant] && len(r.settings.ItemsPool) > 0; i++ {
+			item := r.settings.ItemsPool[rng.Intn(len(r.settings.ItemsPool))]
+			item.Id = primitive.NewObjectID().Hex()
+			rewardItems = append(rewardItems, item)
+		}
+
+		rewards[participant] = RaidReward{
+			Damage:    r.damage[participant],
+			PokemonXP: experience.GetPokemonExperienceGainFromRaid(rng, won && r.damage[participant] > 0),
+			Items:     rewardItems,
+		}
+	}
+
+	return rewards
+}
+
+func (r *Raid) advance(now time.Time) {
+	for {
+		elapsed := now.Sub(r.phaseStarted)
+
+		switch r.phase {
+		case RaidFormingPhase:
+			if elapsed < r.settings.FormingWindow {
+				return
+			}
+
+			if len(r.participants) < r.settings.MinParticipants {
+				r.setPhase(RaidCancelledPhase, r.phaseStarted.Add(r.settings.FormingWindow))
+				return
+			}
+
+			r.setPhase(RaidCountdownPhase, r.phaseStarted.Add(r.settings.FormingWindow))
+		case RaidCountdownPhase:
+			if elapsed < r.settings.Countdown {
+				return
+			}
+
+			r.scaleBoss()
+			r.setPhase(RaidFightingPhase, r.phaseStarted.Add(r.settings.Countdown))
+		case RaidFightingPhase:
+			if elapsed < r.settings.TimeLimit {
+				return
+			}
+
+			r.setPhase(RaidEnragedPhase, r.phaseStarted.Add(r.settings.TimeLimit))
+		case RaidEnragedPhase:
+			if elapsed < r.settings.EnrageDuration {
+				return
+			}
+
+			r.setPhase(RaidDefeatPhase, r.phaseStarted.Add(r.settings.EnrageDuration))
+		default:
+			return
+		}
+	}
+}
+
+func (r *Raid) setPhase(phase string, at time.Time) {
+	r.phase = phase
+	r.phaseStarted = at
+}
+
+func (r *Raid) scaleBoss() {
+	extraParticipants := len(r.participants) - 1
+	if extraParticipants < 0 {
+		extraParticipants = 0
+	}
+
+	maxHP := int(float64(r.baseMaxHP) * (1 + r.settings.HPScaling*float64(extraParticipants)))
+	r.boss.MaxHP = maxHP
+	r.boss.HP = maxHP
+}
+
+// splitProportionally divides total among the participants according to their weights, handing
+// the remainders to the largest fractional parts
+func splitProportionally(total int, participants []string, weights map[string]int) map[string]int {
+	counts := make(map[string]int, len(participants))
+
+	weightsSum := 0
+	for _, participant := range participants {
+		weightsSum += weights[participant]
+	}
+
+	if weightsSum == 0 || total <= 0 {
+		return counts
+	}
+
+	remainders := make(map[string]float64, len(participants))
+	assigned := 0
+	for _, participant := range participants {
+		exact := float64(total) * float64(weights[participant]) / float64(weightsSum)
+		counts[participant] = int(exact)
+		remainders[participant] = exact - float64(counts[participant])
+		assigned += counts[participant]
+	}
+
+	byRemainder := make([]string, len(participants))
+	copy(byRemainder, participants)
+	sort.SliceStable(byRemainder, func(i, j int) bool {
+		return remainders[byRemainder[i]] > remainders[byRemainder[j]]
+	})
+
+	for i := 0; assigned < total; i++ {
+		counts[byRemainder[i%len(byRemainder)]]++
+		assigned++
+	}
+
+	return counts
+}
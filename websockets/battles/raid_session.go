@@ -0,0 +1,100 @@
+package battles
+
+import (
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// RaidSession is the gym server end of GymClient.EnterRaid. It joins the trainers that connect to
+// both the lobby and the raid and sends every participant a RaidPhaseMessage whenever the raid
+// changes phase.
+type RaidSession struct {
+	Lobby *ws.Lobby
+	Raid  *Raid
+}
+
+func NewRaidSession(lobby *ws.Lobby, raid *Raid) *RaidSession {
+	return &RaidSession{
+		Lobby: lobby,
+		Raid:  raid,
+	}
+}
+
+// AddTrainer joins the trainer to the raid and adds its connection to the lobby, sending it the
+// current phase. The trainer leaves the raid again if the lobby does not take it.
+func (s *RaidSession) AddTrainer(username string, trainerConn *websocket.Conn,
+	commsManager ws.CommunicationManager) (int, error) {
+	return s.add(username, func() (int, error) {
+		return ws.AddTrainer(s.Lobby, username, trainerConn, commsManager)
+	})
+}
+
+// AddLocalTrainer is like AddTrainer for participants running in the server, such as AI players
+func (s *RaidSession) AddLocalTrainer(username string, participant ws.LocalParticipant) (int, error) {
+	return s.add(username, func() (int, error) {
+		return ws.AddLocalTrainer(s.Lobby, username, participant)
+	})
+}
+
+func (s *RaidSession) add(username string, addToLobby func() (int, error)) (int, error) {
+	if err := s.Raid.Join(username); err != nil {
+		return -1, err
+	}
+
+	trainersJoined, err := addToLobby()
+	if err != nil {
+		if leaveErr := s.Raid.Leave(username); leaveErr != nil {
+			log.Error(leaveErr)
+		}
+		return -1, err
+	}
+
+	s.send(trainersJoined-1, s.phaseMessage(s.Raid.Phase()))
+	return trainersJoined, nil
+}
+
+// Update moves the raid to its current phase and tells the participants if it changed
+func (s *RaidSession) Update() string {
+	phase, changed := s.Raid.Update()
+	if changed {
+		s.broadcast(s.phaseMessage(phase))
+	}
+
+	return phase
+}
+
+// DealDamage applies the participant's damage to the boss and tells the participants if it
+// ended the raid
+func (s *RaidSession) DealDamage(username string, damage int) (int, error) {
+	previous := s.Raid.Phase()
+
+	hp, err := s.Raid.DealDamage(username, damage)
+	if err != nil {
+		return hp, err
+	}
+
+	if phase := s.Raid.Phase(); phase != previous {
+		s.broadcast(s.phaseMessage(phase))
+	}
+
+	return hp, nil
+}
+
+func (s *RaidSession) phaseMessage(phase string) *ws.WebsocketMsg {
+	return RaidPhaseMessage{Phase: phase, BossHP: s.Raid.Boss().HP}.ConvertToWSMessage()
+}
+
+func (s *RaidSession) broadcast(msg *ws.WebsocketMsg) {
+	for trainerNum := 0; trainerNum < ws.GetTrainersJoined(s.Lobby); trainerNum++ {
+		s.send(trainerNum, msg)
+	}
+}
+
+func (s *RaidSession) send(trainerNum int, msg *ws.WebsocketMsg) {
+	select {
+	case s.Lobby.TrainerOutChannels[trainerNum] <- msg:
+	case <-s.Lobby.Finished:
+		log.Warnf("could not send %s to trainer %d of raid %s", msg.Content.AppMsgType, trainerNum, s.Lobby.Id)
+	}
+}
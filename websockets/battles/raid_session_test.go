@@ -0,0 +1,54 @@
+package battles
+
+import (
+	"testing"
+
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaidSessionSendsPhases(t *testing.T) {
+	clock := &testClock{now: testStart}
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, clock)
+	session := NewRaidSession(ws.NewLobby("raid", DefaultRaidSettings.MaxParticipants, nil), raid)
+	defer ws.FinishLobby(session.Lobby)
+
+	participant := make(collectingParticipant, 10)
+	_, err := session.AddLocalTrainer("trainer0", participant)
+	assert.NoError(t, err)
+	assert.Equal(t, RaidPhaseMessage{Phase: RaidFormingPhase, BossHP: 100}, (<-participant).Content.Data)
+
+	_, err = session.AddLocalTrainer("trainer0", make(collectingParticipant, 10))
+	assert.Equal(t, ErrorAlreadyInRaid, err)
+
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow)
+	assert.Equal(t, RaidCountdownPhase, session.Update())
+	assert.Equal(t, RaidPhaseMessage{Phase: RaidCountdownPhase, BossHP: 100}, (<-participant).Content.Data)
+
+	// nothing is sent while the phase stays the same
+	session.Update()
+	assert.Len(t, participant, 0)
+
+	clock.now = clock.now.Add(DefaultRaidSettings.Countdown)
+	session.Update()
+	assert.Equal(t, RaidFightingPhase, (<-participant).Content.Data.(RaidPhaseMessage).Phase)
+
+	_, err = session.DealDamage("trainer0", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, RaidPhaseMessage{Phase: RaidVictoryPhase, BossHP: 0}, (<-participant).Content.Data)
+}
+
+func TestRaidSessionLeavesTheRaidWhenTheLobbyRejects(t *testing.T) {
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, &testClock{now: testStart})
+	session := NewRaidSession(ws.NewLobby("raid", 1, nil), raid)
+	defer ws.FinishLobby(session.Lobby)
+
+	_, err := session.AddLocalTrainer("trainer0", make(collectingParticipant, 10))
+	assert.NoError(t, err)
+
+	_, err = session.AddLocalTrainer("trainer1", make(collectingParticipant, 10))
+	assert.Equal(t, ws.ErrorLobbyIsFull, errors.Cause(err))
+	assert.Equal(t, []string{"trainer0"}, raid.Participants())
+	assert.Equal(t, ErrorNotInRaid, raid.Leave("trainer1"))
+}
@@ -0,0 +1,102 @@
+package battles
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils/experience"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+var bossMockup = pokemons.Pokemon{Id: "boss", HP: 100, MaxHP: 100, Damage: 10}
+
+func TestRaidPhasesAndHPScaling(t *testing.T) {
+	clock := &testClock{now: testStart}
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, clock)
+
+	assert.NoError(t, raid.Join("trainer0"))
+	assert.NoError(t, raid.Join("trainer1"))
+	assert.Equal(t, ErrorAlreadyInRaid, raid.Join("trainer1"))
+
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow)
+	phase, changed := raid.Update()
+	assert.True(t, changed)
+	assert.Equal(t, RaidCountdownPhase, phase)
+	assert.Equal(t, ErrorRaidNotForming, raid.Join("trainer2"))
+	assert.Equal(t, ErrorRaidNotForming, raid.Leave("trainer1"))
+
+	clock.now = clock.now.Add(DefaultRaidSettings.Countdown)
+	phase, _ = raid.Update()
+	assert.Equal(t, RaidFightingPhase, phase)
+	assert.Equal(t, 150, raid.Boss().MaxHP)
+	assert.Equal(t, 10, raid.BossDamage())
+
+	clock.now = clock.now.Add(DefaultRaidSettings.TimeLimit)
+	phase, _ = raid.Update()
+	assert.Equal(t, RaidEnragedPhase, phase)
+	assert.Equal(t, 20, raid.BossDamage())
+
+	clock.now = clock.now.Add(DefaultRaidSettings.EnrageDuration)
+	phase, _ = raid.Update()
+	assert.Equal(t, RaidDefeatPhase, phase)
+}
+
+func TestRaidDoesNotChangeTheGivenBoss(t *testing.T) {
+	clock := &testClock{now: testStart}
+	boss := bossMockup
+	raid := NewRaid(&boss, DefaultRaidSettings, clock)
+
+	assert.NoError(t, raid.Join("trainer0"))
+	assert.NoError(t, raid.Join("trainer1"))
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow + DefaultRaidSettings.Countdown)
+	_, err := raid.DealDamage("trainer0", 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 140, raid.Boss().HP)
+	assert.Equal(t, bossMockup, boss)
+}
+
+func TestRaidIsCancelledWithoutParticipants(t *testing.T) {
+	clock := &testClock{now: testStart}
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, clock)
+
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow + DefaultRaidSettings.Countdown)
+	phase, _ := raid.Update()
+	assert.Equal(t, RaidCancelledPhase, phase)
+	assert.Empty(t, raid.Rewards(nil))
+}
+
+func TestRaidRewardsAreProportionalToDamage(t *testing.T) {
+	clock := &testClock{now: testStart}
+	raid := NewRaid(&bossMockup, DefaultRaidSettings, clock)
+
+	assert.NoError(t, raid.Join("trainer0"))
+	assert.NoError(t, raid.Join("trainer1"))
+	assert.NoError(t, raid.Join("trainer2"))
+
+	clock.now = clock.now.Add(DefaultRaidSettings.FormingWindow + DefaultRaidSettings.Countdown)
+
+	_, err := raid.DealDamage("trainer0", 160)
+	assert.NoError(t, err)
+	hp, err := raid.DealDamage("trainer1", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, hp)
+	assert.Equal(t, RaidVictoryPhase, raid.Phase())
+
+	rewards := raid.Rewards(rand.New(rand.NewSource(1)))
+	assert.Len(t, rewards["trainer0"].Items, 4)
+	assert.Len(t, rewards["trainer1"].Items, 1)
+	assert.Empty(t, rewards["trainer2"].Items)
+	assert.Equal(t, 40, rewards["trainer1"].Damage)
+	assert.GreaterOrEqual(t, rewards["trainer0"].PokemonXP, float64(experience.MinExperiencePerRaid))
+}
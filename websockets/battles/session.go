@@ -0,0 +1,132 @@
+package battles
+
+import (
+	"sync"
+
+	"github.com/NOVAPokemon/utils"
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// BattleSession plays a battle between the two trainers of a lobby through the Engine. It turns
+// the moves they send into actions and sends each trainer the messages for the resulting events.
+// Accepted actions are recorded, so the battle log is ready to be stored once the session finishes.
+// It is safe for concurrent use.
+type BattleSession struct {
+	Lobby *ws.Lobby
+
+	lock     sync.Mutex
+	engine   *Engine
+	recorder *BattleRecorder
+}
+
+func NewBattleSession(lobby *ws.Lobby, initial BattleState, config EngineConfig, rng RandomSource,
+	clock Clock) *BattleSession {
+	participants := []string{initial.Players[0].Username, initial.Players[1].Username}
+
+	return &BattleSession{
+		Lobby:    lobby,
+		engine:   NewEngine(initial, config, rng, clock),
+		recorder: NewBattleRecorder(lobby.Id, participants, false, initial.StartedAt),
+	}
+}
+
+func (s *BattleSession) State() BattleState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.engine.State()
+}
+
+// HandleMessage applies the move the player sent and tells if the battle finished
+func (s *BattleSession) HandleMessage(player int, msg *ws.WebsocketMsg) (bool, error) {
+	action, err := MessageToAction(player, msg)
+	if err != nil {
+		return false, err
+	}
+
+	return s.apply(action, msg.Content.RequestTrack), nil
+}
+
+// Tick applies a tick of the status conditions and tells if the battle finished
+func (s *BattleSession) Tick() bool {
+	return s.apply(Action{Type: TickAction}, nil)
+}
+
+// Finish finishes the lobby and returns the battle log with the summary of each trainer, to be
+// stored with AddBattle
+func (s *BattleSession) Finish() utils.Battle {
+	s.lock.Lock()
+	state := s.engine.State()
+
+	winner := ""
+	if state.Winner != NoWinner {
+		winner = state.Players[state.Winner].Username
+	}
+
+	battle := s.recorder.Finish(winner, s.engine.clock.Now())
+	s.lock.Unlock()
+
+	ws.FinishLobby(s.Lobby)
+	return battle
+}
+
+// apply holds the lock while sending the messages, so both trainers receive them in the order the
+// actions were applied
+func (s *BattleSession) apply(action Action, info *ws.TrackedInfo) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.engine.clock.Now()
+	state, events := s.engine.Apply(action, now)
+	if !rejected(events) {
+		s.recorder.Record(action, events, now)
+	}
+
+	for receiver := range state.Players {
+		receiverInfo := info
+		if receiver != action.Player {
+			receiverInfo = nil
+		}
+
+		for _, msg := range EventsToMessages(events, receiver, receiverInfo) {
+			select {
+			case s.Lobby.TrainerOutChannels[receiver] <- msg:
+			case <-s.Lobby.Finished:
+				return state.Finished
+			}
+		}
+	}
+
+	return state.Finished
+}
+
+// MessageToAction converts a move sent by the player into the action applied by the engine
+func MessageToAction(player int, msg *ws.WebsocketMsg) (Action, error) {
+	if msg == nil || msg.Content == nil {
+		return Action{}, ErrorInvalidAction
+	}
+
+	action := Action{Type: msg.Content.AppMsgType, Player: player}
+
+	switch action.Type {
+	case Attack, Defend:
+	case UseItem:
+		useItemMsg := &UseItemMessage{}
+		if err := mapstructure.Decode(msg.Content.Data, useItemMsg); err != nil {
+			return Action{}, errors.Wrap(err, ErrorInvalidAction.Error())
+		}
+		action.ItemId = useItemMsg.ItemId
+	case SelectPokemon:
+		selectMsg := &SelectPokemonMessage{}
+		if err := mapstructure.Decode(msg.Content.Data, selectMsg); err != nil {
+			return Action{}, errors.Wrap(err, ErrorInvalidAction.Error())
+		}
+		action.PokemonId = selectMsg.PokemonId
+	default:
+		return Action{}, ErrorInvalidAction
+	}
+
+	return action, nil
+}
@@ -0,0 +1,111 @@
+package battles
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/items"
+	ws "github.com/NOVAPokemon/utils/websockets"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectingParticipant keeps every message the lobby sends to it
+type collectingParticipant chan *ws.WebsocketMsg
+
+func (c collectingParticipant) Play(fromLobby <-chan *ws.WebsocketMsg, _ chan<- *ws.WebsocketMsg,
+	finished <-chan struct{}) {
+	for {
+		select {
+		case msg := <-fromLobby:
+			c <- msg
+		case <-finished:
+			return
+		}
+	}
+}
+
+func newTestSession(t *testing.T) (*BattleSession, *testClock, [2]collectingParticipant) {
+	lobby := ws.NewLobby("battle", 2, nil)
+	participants := [2]collectingParticipant{make(collectingParticipant, 10), make(collectingParticipant, 10)}
+
+	for i, participant := range participants {
+		_, err := ws.AddLocalTrainer(lobby, battleStateMockup.Players[i].Username, participant)
+		assert.NoError(t, err)
+	}
+
+	clock := &testClock{now: testStart}
+	session := NewBattleSession(lobby, battleStateMockup, EngineConfig{Cooldown: testCooldown}, fixedSource(1),
+		clock)
+
+	return session, clock, participants
+}
+
+func TestBattleSessionSendsEvents(t *testing.T) {
+	session, clock, participants := newTestSession(t)
+	defer ws.FinishLobby(session.Lobby)
+
+	finished, err := session.HandleMessage(0, AttackMessage{}.ConvertToWSMessage())
+	assert.NoError(t, err)
+	assert.False(t, finished)
+
+	for i, participant := range participants {
+		msg := <-participant
+		assert.Equal(t, UpdatePokemon, msg.Content.AppMsgType)
+		assert.Equal(t, i == 1, msg.Content.Data.(UpdatePokemonMessage).Owner)
+		assert.Equal(t, 20, msg.Content.Data.(UpdatePokemonMessage).Pokemon.HP)
+	}
+
+	// only the issuer hears about rejected moves
+	_, err = session.HandleMessage(0, AttackMessage{}.ConvertToWSMessage())
+	assert.NoError(t, err)
+	assert.Equal(t, ErrorBattle, (<-participants[0]).Content.AppMsgType)
+	assert.Len(t, participants[1], 0)
+	assert.Equal(t, 1, session.State().Turn)
+
+	clock.now = clock.now.Add(testCooldown)
+	_, err = session.HandleMessage(0, UseItemMessage{ItemId: items.HealName}.ConvertToWSMessage())
+	assert.NoError(t, err)
+	assert.Equal(t, UpdatePokemon, (<-participants[0]).Content.AppMsgType)
+	assert.Equal(t, RemoveItem, (<-participants[0]).Content.AppMsgType)
+	assert.Equal(t, UpdatePokemon, (<-participants[1]).Content.AppMsgType)
+}
+
+func TestBattleSessionFinishes(t *testing.T) {
+	session, clock, _ := newTestSession(t)
+	defer ws.FinishLobby(session.Lobby)
+
+	var finished bool
+	for i := 0; i < 3; i++ {
+		var err error
+		finished, err = session.HandleMessage(0, AttackMessage{}.ConvertToWSMessage())
+		assert.NoError(t, err)
+		clock.now = clock.now.Add(testCooldown)
+	}
+
+	assert.True(t, finished)
+	assert.Equal(t, 0, session.State().Winner)
+
+	// the move after the battle finished is rejected and not logged
+	_, _ = session.HandleMessage(1, AttackMessage{}.ConvertToWSMessage())
+
+	battle := session.Finish()
+	assert.Equal(t, "battle", battle.Id)
+	assert.Equal(t, "trainer0", battle.Winner)
+	assert.Len(t, battle.Log, 3)
+	assert.Equal(t, 3, battle.Summaries["trainer0"].Turns)
+	assert.Equal(t, 30, battle.Summaries["trainer1"].DamageTaken)
+
+	select {
+	case <-session.Lobby.Finished:
+	default:
+		t.Fatal("lobby did not finish")
+	}
+}
+
+func TestMessageToAction(t *testing.T) {
+	action, err := MessageToAction(1, SelectPokemonMessage{PokemonId: "p1"}.ConvertToWSMessage())
+	assert.NoError(t, err)
+	assert.Equal(t, Action{Type: SelectPokemon, Player: 1, PokemonId: "p1"}, action)
+
+	_, err = MessageToAction(0, ws.NewStandardMsg(UpdatePokemon, nil))
+	assert.Equal(t, ErrorInvalidAction, err)
+}
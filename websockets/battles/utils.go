@@ -9,6 +9,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// HandleUseItem applies the move to the trainer's status.
+//
+// Deprecated: use BattleSession, which applies moves through the Engine.
 func HandleUseItem(info *ws.TrackedInfo, useItemMsg *UseItemMessage, issuer *TrainerBattleStatus,
 	issuerChan chan *ws.WebsocketMsg, cooldownDuration time.Duration) bool {
 
@@ -22,7 +25,7 @@ func HandleUseItem(info *ws.TrackedInfo, useItemMsg *UseItemMessage, issuer *Tra
 	}
 
 	itemId := useItemMsg.ItemId
-	item, ok := issuer.TrainerItems[itemId]
+	item, ok := issuer.TrainerItems.Item(itemId)
 	if !ok {
 		issuerChan <- ErrorBattleMessage{
 			Info:  fmt.Sprintf(ErrorInvalidItemSelected.Error()),
@@ -39,7 +42,7 @@ func HandleUseItem(info *ws.TrackedInfo, useItemMsg *UseItemMessage, issuer *Tra
 		}.ConvertToWSMessage(*info)
 	}
 
-	err := item.Apply(issuer.SelectedPokemon)
+	result, err := item.ApplyWithResult(issuer.SelectedPokemon)
 	if err != nil {
 		issuerChan <- ErrorBattleMessage{
 			Info:  fmt.Sprintf(err.Error()),
@@ -47,18 +50,29 @@ func HandleUseItem(info *ws.TrackedInfo, useItemMsg *UseItemMessage, issuer *Tra
 		}.ConvertToWSMessage(*info)
 	}
 
-	issuer.CdTimer.Reset(cooldownDuration)
+	issuer.CdTimer.Reset(issuer.SelectedPokemon.Cooldown(cooldownDuration))
 	issuer.Cooldown = true
 
-	issuer.UsedItems[item.Id] = item
-	delete(issuer.TrainerItems, item.Id)
-	UpdateTrainerPokemon(info, *issuer.SelectedPokemon, issuerChan, true)
+	if result != nil {
+		if result.CuredStatus {
+			issuer.CureCondition(issuer.SelectedPokemon.Id)
+		}
+		issuer.boostsOf(issuer.SelectedPokemon.Id).apply(result)
+	}
+
+	issuer.UsedItems.AddUncapped(item, 1)
+	_ = issuer.TrainerItems.Remove(item.Name, 1)
+	UpdateTrainerPokemonWithCondition(info, *issuer.SelectedPokemon, issuer.ConditionOf(issuer.SelectedPokemon.Id),
+		issuerChan, true)
 	issuerChan <- RemoveItemMessage{
 		ItemId: itemId,
 	}.ConvertToWSMessage(*info)
 	return true
 }
 
+// HandleSelectPokemon applies the move to the trainer's status.
+//
+// Deprecated: use BattleSession, which applies moves through the Engine.
 func HandleSelectPokemon(info *ws.TrackedInfo, selectedPokemonMsg *SelectPokemonMessage, issuer *TrainerBattleStatus,
 	issuerChan chan *ws.WebsocketMsg) bool {
 
@@ -86,10 +100,13 @@ func HandleSelectPokemon(info *ws.TrackedInfo, selectedPokemonMsg *SelectPokemon
 
 	issuer.SelectedPokemon = pokemon
 	log.Info("Changed selected pokemon")
-	UpdateTrainerPokemon(info, *issuer.SelectedPokemon, issuerChan, true)
+	UpdateTrainerPokemonWithCondition(info, *issuer.SelectedPokemon, issuer.ConditionOf(pokemon.Id), issuerChan, true)
 	return true
 }
 
+// HandleDefendMove applies the move to the trainer's status.
+//
+// Deprecated: use BattleSession, which applies moves through the Engine.
 func HandleDefendMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerChan chan *ws.WebsocketMsg,
 	cooldownDuration time.Duration) {
 	// if the pokemon is dead, player must select a new pokemon
@@ -109,9 +126,16 @@ func HandleDefendMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerC
 		}.ConvertToWSMessage(*info)
 		return
 	}
-	issuer.CdTimer.Reset(cooldownDuration)
+	issuer.CdTimer.Reset(issuer.SelectedPokemon.Cooldown(cooldownDuration))
 	issuer.Cooldown = true
 
+	if !issuer.ConditionOf(issuer.SelectedPokemon.Id).CanMove() {
+		issuerChan <- StatusMessage{
+			Message: StatusCannotMove,
+		}.ConvertToWSMessage(*info)
+		return
+	}
+
 	// process Defending move: update both players and setup a Cooldown
 	issuer.Defending = true
 	issuerChan <- StatusMessage{
@@ -119,8 +143,26 @@ func HandleDefendMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerC
 	}.ConvertToWSMessage(*info)
 }
 
+// HandleAttackMove applies the move to the trainer's status.
+//
+// Deprecated: use BattleSession, which applies moves through the Engine.
 func HandleAttackMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerChan chan *ws.WebsocketMsg,
 	defending bool, otherPokemon *pokemons.Pokemon, cooldownDuration time.Duration) bool {
+	return handleAttackMove(info, issuer, issuerChan, defending, otherPokemon, nil, cooldownDuration)
+}
+
+// HandleAttackMoveOnTrainer attacks the selected pokemon of another trainer, taking its
+// defense and defense boosts into account.
+//
+// Deprecated: use BattleSession, which applies moves through the Engine.
+func HandleAttackMoveOnTrainer(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerChan chan *ws.WebsocketMsg,
+	target *TrainerBattleStatus, cooldownDuration time.Duration) bool {
+	return handleAttackMove(info, issuer, issuerChan, target.Defending, target.SelectedPokemon, target,
+		cooldownDuration)
+}
+
+func handleAttackMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerChan chan *ws.WebsocketMsg,
+	defending bool, otherPokemon *pokemons.Pokemon, target *TrainerBattleStatus, cooldownDuration time.Duration) bool {
 	if issuer.SelectedPokemon.HP == 0 {
 		issuerChan <- ErrorBattleMessage{
 			Info:  fmt.Sprintf(ErrorPokemonNoHP.Error()),
@@ -138,18 +180,42 @@ func HandleAttackMove(info *ws.TrackedInfo, issuer *TrainerBattleStatus, issuerC
 		return false
 	}
 
-	issuer.CdTimer.Reset(cooldownDuration)
+	issuer.CdTimer.Reset(issuer.SelectedPokemon.Cooldown(cooldownDuration))
 	issuer.Cooldown = true
-	hpChanged := ApplyAttackMove(issuer.SelectedPokemon, otherPokemon, defending)
+
+	condition := issuer.ConditionOf(issuer.SelectedPokemon.Id)
+	if !condition.CanMove() {
+		issuerChan <- StatusMessage{
+			Message: StatusCannotMove,
+		}.ConvertToWSMessage(*info)
+		return false
+	}
+
+	damageFactor := condition.damageFactor()
+	if !defending {
+		damageFactor *= issuer.boostsOf(issuer.SelectedPokemon.Id).useDamage()
+		if target != nil {
+			damageFactor /= target.boostsOf(otherPokemon.Id).useDefense()
+		}
+	}
+
+	hpChanged := applyAttackMoveWithFactor(issuer.SelectedPokemon, otherPokemon, defending, damageFactor)
 
 	return hpChanged
 }
 
 func ApplyAttackMove(issuerPokemon *pokemons.Pokemon, otherPokemon *pokemons.Pokemon, defending bool) bool {
+	return applyAttackMoveWithFactor(issuerPokemon, otherPokemon, defending, 1)
+}
+
+func applyAttackMoveWithFactor(issuerPokemon *pokemons.Pokemon, otherPokemon *pokemons.Pokemon, defending bool,
+	damageFactor float64) bool {
 	if defending {
 		return false
 	} else {
-		otherPokemon.HP -= issuerPokemon.Damage
+		damage := otherPokemon.MitigateDamage(int(float64(issuerPokemon.Damage) * damageFactor))
+
+		otherPokemon.HP -= damage
 		if otherPokemon.HP < 0 {
 			otherPokemon.HP = 0
 		}
@@ -159,16 +225,23 @@ func ApplyAttackMove(issuerPokemon *pokemons.Pokemon, otherPokemon *pokemons.Pok
 
 func UpdateTrainerPokemon(trackedInfo *ws.TrackedInfo, pokemon pokemons.Pokemon, channel chan *ws.WebsocketMsg,
 	owner bool) {
+	UpdateTrainerPokemonWithCondition(trackedInfo, pokemon, nil, channel, owner)
+}
+
+func UpdateTrainerPokemonWithCondition(trackedInfo *ws.TrackedInfo, pokemon pokemons.Pokemon,
+	condition *StatusCondition, channel chan *ws.WebsocketMsg, owner bool) {
 	var wsMsg *ws.WebsocketMsg
 	if trackedInfo != nil {
 		wsMsg = UpdatePokemonMessage{
-			Owner:   owner,
-			Pokemon: pokemon,
+			Owner:     owner,
+			Pokemon:   pokemon,
+			Condition: condition,
 		}.ConvertToWSMessageWithInfo(*trackedInfo)
 	} else {
 		wsMsg = UpdatePokemonMessage{
-			Owner:   owner,
-			Pokemon: pokemon,
+			Owner:     owner,
+			Pokemon:   pokemon,
+			Condition: condition,
 		}.ConvertToWSMessage()
 	}
 
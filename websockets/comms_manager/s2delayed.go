@@ -14,8 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/NOVAPokemon/utils/gps"
 	"github.com/NOVAPokemon/utils/websockets"
-	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 	"github.com/gorilla/websocket"
 	"github.com/mitchellh/mapstructure"
@@ -466,7 +466,7 @@ func getClosestNode(cellID s2.CellID) int {
 
 	for nodeNumString, latLng := range locations {
 		nodeCell := s2.CellFromLatLng(s2.LatLngFromDegrees(latLng.Lat, latLng.Lng))
-		dist := chordAngleToKM(myCell.DistanceToCell(nodeCell))
+		dist := gps.AngleToMeters(myCell.DistanceToCell(nodeCell).Angle())
 
 		if minDist == -1 || dist < minDist {
 			minDist = dist
@@ -479,11 +479,3 @@ func getClosestNode(cellID s2.CellID) int {
 
 	return closestNode
 }
-
-const (
-	earthRadius = 6_378
-)
-
-func chordAngleToKM(angle s1.ChordAngle) float64 {
-	return angle.Angle().Radians() * earthRadius
-}
@@ -94,6 +94,48 @@ func AddTrainer(lobby *Lobby, username string, trainerConn *websocket.Conn,
 	}
 }
 
+// LocalParticipant is a lobby participant that runs in the server process instead of behind a
+// websocket connection, such as an AI opponent. Play receives the messages the lobby sends to the
+// participant and writes the participant's moves, and must return once finished is closed.
+type LocalParticipant interface {
+	Play(fromLobby <-chan *WebsocketMsg, toLobby chan<- *WebsocketMsg, finished <-chan struct{})
+}
+
+func AddLocalTrainer(lobby *Lobby, username string, participant LocalParticipant) (int, error) {
+	lobby.changeLobbyLock.Lock()
+	defer lobby.changeLobbyLock.Unlock()
+
+	if lobby.TrainersJoined >= lobby.Capacity {
+		return -1, NewLobbyIsFullError(lobby.Id)
+	}
+
+	select {
+	case <-lobby.Started:
+		return -1, NewLobbyStartedError(lobby.Id)
+	case <-lobby.Finished:
+		return -1, NewLobbyFinishedError(lobby.Id)
+	default:
+		trainerNum := lobby.TrainersJoined
+		trainerChanIn := make(chan *WebsocketMsg)
+		trainerChanOut := make(chan *WebsocketMsg)
+
+		done := make(chan interface{})
+		go func() {
+			defer close(done)
+			participant.Play(trainerChanOut, trainerChanIn, lobby.Finished)
+			log.Infof("(%s, %s) local participant finished", lobby.Id, username)
+		}()
+
+		lobby.TrainerUsernames[trainerNum] = username
+		lobby.TrainerInChannels[trainerNum] = trainerChanIn
+		lobby.TrainerOutChannels[trainerNum] = trainerChanOut
+		lobby.DoneListeningFromConn[trainerNum] = done
+		lobby.DoneWritingToConn[trainerNum] = done
+		lobby.TrainersJoined++
+		return lobby.TrainersJoined, nil
+	}
+}
+
 func sendFromChanToConn(lobby *Lobby, trainerNum int, writer CommunicationManager) (done chan interface{}) {
 	done = make(chan interface{})
 	go func() {
@@ -216,8 +258,10 @@ func FinishLobby(lobby *Lobby) {
 		defer lobby.changeLobbyLock.Unlock()
 		close(lobby.Finished)
 		for i := 0; i < lobby.TrainersJoined; i++ {
-			if err := lobby.trainerConnections[i].Close(); err != nil {
-				log.Error(err)
+			if lobby.trainerConnections[i] != nil {
+				if err := lobby.trainerConnections[i].Close(); err != nil {
+					log.Error(err)
+				}
 			}
 			<-lobby.DoneWritingToConn[i]
 			<-lobby.DoneListeningFromConn[i]
@@ -0,0 +1,54 @@
+package websockets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// echoParticipant sends back every message it receives
+type echoParticipant struct{}
+
+func (echoParticipant) Play(fromLobby <-chan *WebsocketMsg, toLobby chan<- *WebsocketMsg, finished <-chan struct{}) {
+	for {
+		select {
+		case msg := <-fromLobby:
+			select {
+			case toLobby <- msg:
+			case <-finished:
+				return
+			}
+		case <-finished:
+			return
+		}
+	}
+}
+
+func TestAddLocalTrainer(t *testing.T) {
+	lobby := NewLobby("lobby", 1, nil)
+
+	joined, err := AddLocalTrainer(lobby, "ai", echoParticipant{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, joined)
+	assert.Equal(t, "ai", lobby.TrainerUsernames[0])
+
+	_, err = AddLocalTrainer(lobby, "other", echoParticipant{})
+	assert.Error(t, err)
+
+	msg := NewStandardMsg(Finish, nil)
+	lobby.TrainerOutChannels[0] <- msg
+	assert.Equal(t, msg, <-lobby.TrainerInChannels[0])
+
+	finished := make(chan struct{})
+	go func() {
+		FinishLobby(lobby)
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("lobby did not finish")
+	}
+}
@@ -77,8 +77,13 @@ func (cMsg CatchWildPokemonMessage) ConvertToWSMessage() *ws.WebsocketMsg {
 	return ws.NewRequestMsg(CatchPokemon, cMsg)
 }
 
+// CatchWildPokemonMessageResponse carries the outcome of the catch attempt, as given by the catch
+// package, and the reason clients display
 type CatchWildPokemonMessageResponse struct {
 	Caught        bool
+	Outcome       string
+	Shakes        int
+	Reason        string
 	PokemonTokens []string
 	Error         string
 }
@@ -0,0 +1,15 @@
+package trades
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorInvalidPlayer  = errors.New("invalid trade player")
+	ErrorTradeFinished  = errors.New("trade already finished")
+	ErrorAlreadyOffered = errors.New("asset already offered")
+	ErrorNotOffered     = errors.New("asset not offered")
+	ErrorInvalidCoins   = errors.New("invalid coins amount")
+	ErrorInvalidAsset   = errors.New("invalid asset kind")
+	ErrorNotOwned       = errors.New("asset not owned by player")
+)
@@ -14,6 +14,8 @@ const (
 	RejectTrade = "REJECT_TRADE"
 	ErrorTrade  = "ERROR_TRADE"
 	Trade       = "TRADE"
+	AddOffer    = "ADD_OFFER"
+	RemoveOffer = "REMOVE_OFFER"
 	Accept      = "ACCEPT"
 	Update      = "UPDATE_TRADE"
 )
@@ -31,8 +33,9 @@ func (s RejectTradeMessage) ConvertToWSMessage(info ws.TrackedInfo) *ws.Websocke
 }
 
 type ErrorTradeMessage struct {
-	Info  string
-	Fatal bool
+	Info   string
+	Fatal  bool
+	Reason string `json:",omitempty"`
 }
 
 func (e ErrorTradeMessage) ConvertToWSMessage(info ws.TrackedInfo) *ws.WebsocketMsg {
@@ -48,6 +51,21 @@ func (tMsg TradeMessage) ConvertToWSMessage() *ws.WebsocketMsg {
 	return ws.NewRequestMsg(Trade, tMsg)
 }
 
+// Offers: Id is the item or pokemon id and Coins the amount offered when Asset is CoinsAsset
+type OfferMessage struct {
+	Asset string
+	Id    string `json:",omitempty"`
+	Coins int    `json:",omitempty"`
+}
+
+func (oMsg OfferMessage) ConvertToWSMessage() *ws.WebsocketMsg {
+	return ws.NewRequestMsg(AddOffer, oMsg)
+}
+
+func (oMsg OfferMessage) ConvertToRemoveWSMessage() *ws.WebsocketMsg {
+	return ws.NewRequestMsg(RemoveOffer, oMsg)
+}
+
 // Accept
 type AcceptMessage struct{}
 
@@ -0,0 +1,292 @@
+package trades
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+)
+
+// Reasons for rejecting a trade
+const (
+	TooManyItemsReason    = "TOO_MANY_ITEMS"
+	TooManyPokemonsReason = "TOO_MANY_POKEMONS"
+	TrainerLevelReason    = "TRAINER_LEVEL"
+	FriendshipReason      = "FRIENDSHIP"
+	DailyLimitReason      = "DAILY_LIMIT"
+	AssetCooldownReason   = "ASSET_COOLDOWN"
+	ValueImbalanceReason  = "VALUE_IMBALANCE"
+)
+
+const (
+	dailyWindow = 24 * time.Hour
+
+	pokemonLevelValue = 10
+	defaultItemValue  = 10
+)
+
+type (
+	// TradeRules are enforced by the trades server. Zero values disable the corresponding rule.
+	TradeRules struct {
+		MaxItemsPerSide    int
+		MaxPokemonsPerSide int
+		MinTrainerLevel    int
+		// MinFriendship is the number of completed trades two trainers need before trading pokemons
+		MinFriendship int
+		DailyLimit    int
+		// AssetCooldown is how long a pokemon has to wait before being traded again. Items are
+		// offered by the name of their stack, which every trainer shares, so they have no cooldown.
+		AssetCooldown time.Duration
+		// MaxValueImbalance is the maximum ratio between the values of both sides of a trade
+		MaxValueImbalance float64
+	}
+
+	// RuleViolation is the error returned when a trade breaks one of the rules. Player is the
+	// index of the trainer that caused it.
+	RuleViolation struct {
+		Reason string
+		Player int
+		Info   string
+	}
+
+	// TradeTracker remembers recent trades to enforce the rules that depend on history
+	TradeTracker struct {
+		lock        sync.Mutex
+		trainers    map[string][]time.Time
+		pokemons    map[string]time.Time
+		friendships map[string]int
+	}
+)
+
+var DefaultTradeRules = TradeRules{
+	MaxItemsPerSide:    10,
+	MaxPokemonsPerSide: 3,
+	MinTrainerLevel:    2,
+	MinFriendship:      1,
+	DailyLimit:         20,
+	AssetCooldown:      time.Hour,
+	MaxValueImbalance:  5,
+}
+
+func (v *RuleViolation) Error() string {
+	return fmt.Sprintf("trade rule violated (%s): %s", v.Reason, v.Info)
+}
+
+func (v *RuleViolation) ToErrorMessage() ErrorTradeMessage {
+	return ErrorTradeMessage{
+		Info:   v.Info,
+		Fatal:  false,
+		Reason: v.Reason,
+	}
+}
+
+// CheckOffer validates the offer of a player every time it changes
+func (rules TradeRules) CheckOffer(trade *TradeStatus, playerNum int) error {
+	player := trade.Players[playerNum]
+
+	if rules.MaxItemsPerSide > 0 && len(player.Items) > rules.MaxItemsPerSide {
+		return &RuleViolation{
+			Reason: TooManyItemsReason,
+			Player: playerNum,
+			Info:   fmt.Sprintf("at most %d items can be traded", rules.MaxItemsPerSide),
+		}
+	}
+
+	if rules.MaxPokemonsPerSide > 0 && len(player.Pokemons) > rules.MaxPokemonsPerSide {
+		return &RuleViolation{
+			Reason: TooManyPokemonsReason,
+			Player: playerNum,
+			Info:   fmt.Sprintf("at most %d pokemons can be traded", rules.MaxPokemonsPerSide),
+		}
+	}
+
+	return nil
+}
+
+// CheckJoin validates whether two trainers can start a trade
+func (rules TradeRules) CheckJoin(usernames [2]string, stats [2]utils.TrainerStats, tracker *TradeTracker,
+	now time.Time) error {
+	for i := range usernames {
+		if stats[i].Level < rules.MinTrainerLevel {
+			return &RuleViolation{
+				Reason: TrainerLevelReason,
+				Player: i,
+				Info:   fmt.Sprintf("trainers must be level %d to trade", rules.MinTrainerLevel),
+			}
+		}
+
+		if rules.DailyLimit > 0 && tracker.TradesToday(usernames[i], now) >= rules.DailyLimit {
+			return &RuleViolation{
+				Reason: DailyLimitReason,
+				Player: i,
+				Info:   fmt.Sprintf("%s reached the limit of %d trades per day", usernames[i], rules.DailyLimit),
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckTrade validates the final offers once both players accepted them
+func (rules TradeRules) CheckTrade(trade *TradeStatus, stats [2]utils.TrainerStats, tracker *TradeTracker,
+	now time.Time) error {
+	usernames := [2]string{trade.Players[0].Username, trade.Players[1].Username}
+	if err := rules.CheckJoin(usernames, stats, tracker, now); err != nil {
+		return err
+	}
+
+	for i := range trade.Players {
+		if err := rules.CheckOffer(trade, i); err != nil {
+			return err
+		}
+	}
+
+	tradesPokemons := len(trade.Players[0].Pokemons) > 0 || len(trade.Players[1].Pokemons) > 0
+	if tradesPokemons && tracker.Friendship(usernames[0], usernames[1]) < rules.MinFriendship {
+		return &RuleViolation{
+			Reason: FriendshipReason,
+			Player: 0,
+			Info:   fmt.Sprintf("pokemons can only be traded after %d trades together", rules.MinFriendship),
+		}
+	}
+
+	if rules.AssetCooldown > 0 {
+		for i, offer := range trade.Offers() {
+			for _, pokemonId := range offer.PokemonIds {
+				if tracker.tradedWithin(pokemonId, rules.AssetCooldown, now) {
+					return &RuleViolation{
+						Reason: AssetCooldownReason,
+						Player: i,
+						Info:   fmt.Sprintf("%s was traded recently", pokemonId),
+					}
+				}
+			}
+		}
+	}
+
+	if rules.MaxValueImbalance > 0 {
+		values := [2]int{PlayerOfferValue(trade.Players[0]), PlayerOfferValue(trade.Players[1])}
+		low, high := 0, 1
+		if values[low] > values[high] {
+			low, high = high, low
+		}
+
+		if float64(values[high]) > rules.MaxValueImbalance*float64(values[low]+1) {
+			return &RuleViolation{
+				Reason: ValueImbalanceReason,
+				Player: high,
+				Info:   fmt.Sprintf("offers are worth %d and %d", values[0], values[1]),
+			}
+		}
+	}
+
+	return nil
+}
+
+// PlayerOfferValue estimates what a player is giving, in coins
+func PlayerOfferValue(player Player) int {
+	value := player.Coins
+
+	for _, item := range player.Items {
+		value += ItemValue(item)
+	}
+
+	for _, pokemon := range player.Pokemons {
+		value += PokemonValue(pokemon)
+	}
+
+	return value
+}
+
+func ItemValue(item items.Item) int {
+	if item.Effect.Value > 0 {
+		return item.Effect.Value
+	}
+
+	return defaultItemValue
+}
+
+func PokemonValue(pokemon pokemons.Pokemon) int {
+	return pokemon.Level*pokemonLevelValue + pokemon.MaxHP/10 + pokemon.Damage
+}
+
+// NewTradeTracker creates a tracker from the completed trades in the history
+func NewTradeTracker(history []utils.TradeRecord) *TradeTracker {
+	tracker := &TradeTracker{
+		trainers:    map[string][]time.Time{},
+		pokemons:    map[string]time.Time{},
+		friendships: map[string]int{},
+	}
+
+	for _, record := range history {
+		if record.Outcome != utils.TradeCompleted {
+			continue
+		}
+
+		tracker.RecordTrade(record.Offers, time.Unix(0, record.FinishedAt*int64(time.Millisecond)))
+	}
+
+	return tracker
+}
+
+// RecordTrade registers a completed trade
+func (t *TradeTracker) RecordTrade(offers [2]utils.TradeOffer, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, offer := range offers {
+		t.trainers[offer.Username] = append(t.trainers[offer.Username], at)
+
+		for _, pokemonId := range offer.PokemonIds {
+			if at.After(t.pokemons[pokemonId]) {
+				t.pokemons[pokemonId] = at
+			}
+		}
+	}
+
+	t.friendships[friendshipKey(offers[0].Username, offers[1].Username)]++
+}
+
+// TradesToday returns how many trades the trainer completed in the last 24 hours
+func (t *TradeTracker) TradesToday(username string, now time.Time) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// drop the trades outside the window while counting
+	recent := t.trainers[username][:0]
+	for _, at := range t.trainers[username] {
+		if now.Sub(at) < dailyWindow {
+			recent = append(recent, at)
+		}
+	}
+	t.trainers[username] = recent
+
+	return len(recent)
+}
+
+// Friendship is the number of trades two trainers completed together
+func (t *TradeTracker) Friendship(trainer1, trainer2 string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.friendships[friendshipKey(trainer1, trainer2)]
+}
+
+func (t *TradeTracker) tradedWithin(pokemonId string, cooldown time.Duration, now time.Time) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	at, ok := t.pokemons[pokemonId]
+	return ok && now.Sub(at) < cooldown
+}
+
+func friendshipKey(trainer1, trainer2 string) string {
+	if trainer1 > trainer2 {
+		trainer1, trainer2 = trainer2, trainer1
+	}
+
+	return trainer1 + "|" + trainer2
+}
@@ -0,0 +1,87 @@
+package trades
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NOVAPokemon/utils"
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testNow     = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	testStats   = [2]utils.TrainerStats{{Level: 5}, {Level: 5}}
+	tradeMockup = TradeStatus{Players: [2]Player{{Username: "trainer0"}, {Username: "trainer1"}}}
+)
+
+func assertViolation(t *testing.T, reason string, err error) {
+	violation, ok := err.(*RuleViolation)
+	if assert.True(t, ok, "expected a rule violation, got %v", err) {
+		assert.Equal(t, reason, violation.Reason)
+		assert.Equal(t, reason, violation.ToErrorMessage().Reason)
+	}
+}
+
+func TestCheckOfferLimitsItems(t *testing.T) {
+	rules := TradeRules{MaxItemsPerSide: 1}
+	trade := tradeMockup
+
+	_ = trade.AddItem(0, items.Item{Id: "item1"})
+	assert.NoError(t, rules.CheckOffer(&trade, 0))
+
+	_ = trade.AddItem(0, items.Item{Id: "item2"})
+	assertViolation(t, TooManyItemsReason, rules.CheckOffer(&trade, 0))
+}
+
+func TestCheckJoinRequirements(t *testing.T) {
+	rules := TradeRules{MinTrainerLevel: 3, DailyLimit: 1}
+	tracker := NewTradeTracker(nil)
+	usernames := [2]string{"trainer0", "trainer1"}
+
+	assertViolation(t, TrainerLevelReason,
+		rules.CheckJoin(usernames, [2]utils.TrainerStats{{Level: 5}, {Level: 1}}, tracker, testNow))
+
+	tracker.RecordTrade(tradeMockup.Offers(), testNow.Add(-time.Hour))
+	assertViolation(t, DailyLimitReason, rules.CheckJoin(usernames, testStats, tracker, testNow))
+	assert.NoError(t, rules.CheckJoin(usernames, testStats, tracker, testNow.Add(dailyWindow)))
+}
+
+func TestCheckTradeHistoryAndValue(t *testing.T) {
+	rules := TradeRules{MinFriendship: 1, AssetCooldown: time.Hour, MaxValueImbalance: 2}
+	pokemon := pokemons.Pokemon{Id: "pokemon", Level: 10}
+
+	trade := tradeMockup
+	_ = trade.AddPokemon(0, pokemon)
+	_ = trade.SetCoins(1, 100)
+
+	tracker := NewTradeTracker(nil)
+	assertViolation(t, FriendshipReason, rules.CheckTrade(&trade, testStats, tracker, testNow))
+
+	tracker = NewTradeTracker([]utils.TradeRecord{{
+		Offers:     [2]utils.TradeOffer{{Username: "trainer1", PokemonIds: []string{"pokemon"}}, {Username: "trainer0"}},
+		Outcome:    utils.TradeCompleted,
+		FinishedAt: testNow.Add(-time.Minute).UnixNano() / int64(time.Millisecond),
+	}})
+	assertViolation(t, AssetCooldownReason, rules.CheckTrade(&trade, testStats, tracker, testNow))
+	assert.NoError(t, rules.CheckTrade(&trade, testStats, tracker, testNow.Add(time.Hour)))
+
+	_ = trade.SetCoins(1, 1000)
+	assertViolation(t, ValueImbalanceReason, rules.CheckTrade(&trade, testStats, tracker, testNow.Add(time.Hour)))
+}
+
+func TestAssetCooldownIgnoresItemStacks(t *testing.T) {
+	rules := TradeRules{AssetCooldown: time.Hour}
+	tracker := NewTradeTracker(nil)
+
+	tracker.RecordTrade([2]utils.TradeOffer{
+		{Username: "trainer2", ItemIds: []string{"potion"}},
+		{Username: "trainer3"},
+	}, testNow.Add(-time.Minute))
+
+	trade := tradeMockup
+	_ = trade.AddItem(0, items.Item{Id: "potion", Name: "potion"})
+	_ = trade.AddItem(1, items.Item{Id: "potion", Name: "potion"})
+	assert.NoError(t, rules.CheckTrade(&trade, testStats, tracker, testNow))
+}
@@ -1,7 +1,18 @@
 package trades
 
 import (
+	"time"
+
+	"github.com/NOVAPokemon/utils"
 	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+)
+
+// Kinds of assets that can be offered in a trade
+const (
+	ItemAsset    = "ITEM"
+	PokemonAsset = "POKEMON"
+	CoinsAsset   = "COINS"
 )
 
 type TradeStatus struct {
@@ -10,12 +21,17 @@ type TradeStatus struct {
 }
 
 type Player struct {
+	Username string
 	Items    []items.Item
+	Pokemons []pokemons.Pokemon
+	Coins    int
 	Accepted bool
 }
 
 type PlayerInfo struct {
 	Items    []string
+	Pokemons []string
+	Coins    int
 	Accepted bool
 }
 
@@ -26,10 +42,214 @@ func PlayerToPlayerInfo(player *Player) *PlayerInfo {
 		playerItems[i] = item.Id
 	}
 
+	playerPokemons := make([]string, len(player.Pokemons))
+
+	for i, pokemon := range player.Pokemons {
+		playerPokemons[i] = pokemon.Id
+	}
+
 	return &PlayerInfo{
 		Items:    playerItems,
+		Pokemons: playerPokemons,
+		Coins:    player.Coins,
 		Accepted: player.Accepted,
 	}
 }
 
+// AddItem adds an item to the player's offer. Items of the same stack can be added more than
+// once. Any change to the offers resets both acceptances.
+func (trade *TradeStatus) AddItem(playerNum int, item items.Item) error {
+	player, err := trade.changeOffer(playerNum)
+	if err != nil {
+		return err
+	}
+
+	player.Items = append(player.Items, item)
+	return nil
+}
+
+func (trade *TradeStatus) RemoveItem(playerNum int, itemId string) error {
+	player, err := trade.changeOffer(playerNum)
+	if err != nil {
+		return err
+	}
+
+	for i, offered := range player.Items {
+		if offered.Id == itemId || offered.Name == itemId {
+			player.Items = append(player.Items[:i], player.Items[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrorNotOffered
+}
+
+func (trade *TradeStatus) AddPokemon(playerNum int, pokemon pokemons.Pokemon) error {
+	player, err := trade.changeOffer(playerNum)
+	if err != nil {
+		return err
+	}
+
+	for _, offered := range player.Pokemons {
+		if offered.Id == pokemon.Id {
+			return ErrorAlreadyOffered
+		}
+	}
+
+	player.Pokemons = append(player.Pokemons, pokemon)
+	return nil
+}
+
+func (trade *TradeStatus) RemovePokemon(playerNum int, pokemonId string) error {
+	player, err := trade.changeOffer(playerNum)
+	if err != nil {
+		return err
+	}
+
+	for i, offered := range player.Pokemons {
+		if offered.Id == pokemonId {
+			player.Pokemons = append(player.Pokemons[:i], player.Pokemons[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrorNotOffered
+}
+
+func (trade *TradeStatus) SetCoins(playerNum int, coins int) error {
+	if coins < 0 {
+		return ErrorInvalidCoins
+	}
+
+	player, err := trade.changeOffer(playerNum)
+	if err != nil {
+		return err
+	}
+
+	player.Coins = coins
+	return nil
+}
+
+// ApplyOffer adds or removes the asset in the offer message, checking it against what the
+// player owns
+func (trade *TradeStatus) ApplyOffer(playerNum int, offer OfferMessage, remove bool, playerItems items.Inventory,
+	playerPokemons map[string]pokemons.Pokemon, playerCoins int) error {
+	switch offer.Asset {
+	case ItemAsset:
+		if remove {
+			return trade.RemoveItem(playerNum, offer.Id)
+		}
+
+		item, ok := playerItems.Item(offer.Id)
+		if !ok || trade.offeredItems(playerNum, offer.Id) >= playerItems.Quantity(offer.Id) {
+			return ErrorNotOwned
+		}
+
+		return trade.AddItem(playerNum, item)
+	case PokemonAsset:
+		if remove {
+			return trade.RemovePokemon(playerNum, offer.Id)
+		}
+
+		pokemon, ok := playerPokemons[offer.Id]
+		if !ok {
+			return ErrorNotOwned
+		}
+
+		return trade.AddPokemon(playerNum, pokemon)
+	case CoinsAsset:
+		if remove {
+			return trade.SetCoins(playerNum, 0)
+		}
+
+		if offer.Coins > playerCoins {
+			return ErrorNotOwned
+		}
+
+		return trade.SetCoins(playerNum, offer.Coins)
+	default:
+		return ErrorInvalidAsset
+	}
+}
+
+// Accept marks the player's acceptance and returns whether both players accepted the current offers
+func (trade *TradeStatus) Accept(playerNum int) (bool, error) {
+	if playerNum < 0 || playerNum >= len(trade.Players) {
+		return false, ErrorInvalidPlayer
+	}
+
+	if trade.TradeFinished {
+		return false, ErrorTradeFinished
+	}
+
+	trade.Players[playerNum].Accepted = true
+	return trade.Players[0].Accepted && trade.Players[1].Accepted, nil
+}
+
+// Offers converts the trade into what each trainer gives, to be executed by the trainers database
+func (trade *TradeStatus) Offers() [2]utils.TradeOffer {
+	offers := [2]utils.TradeOffer{}
+
+	for i, player := range trade.Players {
+		offers[i] = utils.TradeOffer{
+			Username:   player.Username,
+			ItemIds:    make([]string, len(player.Items)),
+			PokemonIds: make([]string, len(player.Pokemons)),
+			Coins:      player.Coins,
+		}
+
+		for j, item := range player.Items {
+			offers[i].ItemIds[j] = item.Id
+		}
+
+		for j, pokemon := range player.Pokemons {
+			offers[i].PokemonIds[j] = pokemon.Id
+		}
+	}
+
+	return offers
+}
+
+// Record builds the trade history entry for a closed lobby. Times are converted to milliseconds.
+func (trade *TradeStatus) Record(tradeId, outcome string, startedAt, finishedAt time.Time) utils.TradeRecord {
+	return utils.TradeRecord{
+		Id:           tradeId,
+		Participants: []string{trade.Players[0].Username, trade.Players[1].Username},
+		Offers:       trade.Offers(),
+		Outcome:      outcome,
+		StartedAt:    startedAt.UnixNano() / int64(time.Millisecond),
+		FinishedAt:   finishedAt.UnixNano() / int64(time.Millisecond),
+	}
+}
+
+func (trade *TradeStatus) offeredItems(playerNum int, name string) int {
+	if playerNum < 0 || playerNum >= len(trade.Players) {
+		return 0
+	}
+
+	offered := 0
+	for _, item := range trade.Players[playerNum].Items {
+		if item.Name == name {
+			offered++
+		}
+	}
+
+	return offered
+}
+
+func (trade *TradeStatus) changeOffer(playerNum int) (*Player, error) {
+	if playerNum < 0 || playerNum >= len(trade.Players) {
+		return nil, ErrorInvalidPlayer
+	}
+
+	if trade.TradeFinished {
+		return nil, ErrorTradeFinished
+	}
+
+	trade.Players[0].Accepted = false
+	trade.Players[1].Accepted = false
+
+	return &trade.Players[playerNum], nil
+}
+
 type ItemsMap = map[string]items.Item
@@ -0,0 +1,58 @@
+package trades
+
+import (
+	"testing"
+
+	"github.com/NOVAPokemon/utils/items"
+	"github.com/NOVAPokemon/utils/pokemons"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangingOffersResetsAcceptance(t *testing.T) {
+	trade := &TradeStatus{}
+	trade.Players[0].Username = "trainer0"
+	trade.Players[1].Username = "trainer1"
+
+	assert.NoError(t, trade.AddItem(0, items.Item{Id: "item"}))
+	assert.NoError(t, trade.AddPokemon(1, pokemons.Pokemon{Id: "pokemon"}))
+	assert.NoError(t, trade.SetCoins(1, 10))
+
+	both, err := trade.Accept(0)
+	assert.NoError(t, err)
+	assert.False(t, both)
+
+	assert.NoError(t, trade.RemovePokemon(1, "pokemon"))
+	assert.False(t, trade.Players[0].Accepted)
+
+	_, _ = trade.Accept(0)
+	both, _ = trade.Accept(1)
+	assert.True(t, both)
+
+	offers := trade.Offers()
+	assert.Equal(t, []string{"item"}, offers[0].ItemIds)
+	assert.Empty(t, offers[1].PokemonIds)
+	assert.Equal(t, 10, offers[1].Coins)
+}
+
+func TestApplyOfferChecksOwnership(t *testing.T) {
+	trade := &TradeStatus{}
+	owned := items.Inventory{"item": {Name: "item", Quantity: 2}}
+	offerItem := OfferMessage{Asset: ItemAsset, Id: "item"}
+
+	err := trade.ApplyOffer(0, OfferMessage{Asset: ItemAsset, Id: "other"}, false, owned, nil, 0)
+	assert.Equal(t, ErrorNotOwned, err)
+
+	assert.NoError(t, trade.ApplyOffer(0, offerItem, false, owned, nil, 0))
+	assert.NoError(t, trade.ApplyOffer(0, offerItem, false, owned, nil, 0))
+	assert.Equal(t, ErrorNotOwned, trade.ApplyOffer(0, offerItem, false, owned, nil, 0))
+	assert.Equal(t, []string{"item", "item"}, trade.Offers()[0].ItemIds)
+	assert.Equal(t, ErrorInvalidAsset, trade.ApplyOffer(0, OfferMessage{Asset: "BADGE"}, false, owned, nil, 0))
+}
+
+func TestApplyOfferChecksCoins(t *testing.T) {
+	trade := &TradeStatus{}
+
+	assert.Equal(t, ErrorNotOwned, trade.ApplyOffer(0, OfferMessage{Asset: CoinsAsset, Coins: 11}, false, nil, nil, 10))
+	assert.NoError(t, trade.ApplyOffer(0, OfferMessage{Asset: CoinsAsset, Coins: 10}, false, nil, nil, 10))
+	assert.Equal(t, 10, trade.Offers()[0].Coins)
+}